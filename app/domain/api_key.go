@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+type ApiKeyScope string
+
+const (
+	ApiKeyScopeReserve       ApiKeyScope = "reserve"        // reserve stock and read availability
+	ApiKeyScopeInitStock     ApiKeyScope = "init-stock"     // initialise stock rows and read availability
+	ApiKeyScopeTransferAdmin ApiKeyScope = "transfer-admin" // move transfers between statuses
+	ApiKeyScopeSnapshot      ApiKeyScope = "snapshot"       // publish availability snapshots
+	ApiKeyScopeConfig        ApiKeyScope = "config"         // read the effective runtime config
+)
+
+var ApiKeyScopes = []ApiKeyScope{ApiKeyScopeReserve, ApiKeyScopeInitStock, ApiKeyScopeTransferAdmin, ApiKeyScopeSnapshot, ApiKeyScopeConfig}
+
+// ApiKey authenticates internal and admin callers. Only the SHA-256 hash of
+// the key is stored; Prefix is the public part used to look the key up.
+type ApiKey struct {
+	ID         int64         `json:"id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	KeyHash    string        `json:"-"`
+	Scopes     []ApiKeyScope `json:"scopes"`
+	ExpiresAt  *time.Time    `json:"expires_at"`
+	LastUsedAt *time.Time    `json:"last_used_at"`
+	RevokedAt  *time.Time    `json:"revoked_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// HasAnyScope reports whether granted holds at least one of required. It is
+// the one scope check for both the HTTP and the gRPC api key auth.
+func HasAnyScope(granted []ApiKeyScope, required ...ApiKeyScope) bool {
+	for _, scope := range required {
+		if slices.Contains(granted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+type ApiKeyCreateRequest struct {
+	Name      string        `json:"name" validate:"required,max=100"`
+	Scopes    []ApiKeyScope `json:"scopes" validate:"required,min=1,dive,oneof=reserve init-stock transfer-admin snapshot config"`
+	ExpiresAt *time.Time    `json:"expires_at"`
+}
+
+type ApiKeyRepository interface {
+	Create(ctx context.Context, apiKey *ApiKey) error
+	GetByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetList(ctx context.Context) ([]ApiKey, error)
+	Revoke(ctx context.Context, id int64) error
+	// TouchLastUsed records a use, at most once a minute per key.
+	TouchLastUsed(ctx context.Context, id int64) error
+}
+
+type ApiKeyUsecase interface {
+	// Issue stores a new key and returns it with the plaintext key, which is
+	// not recoverable afterwards.
+	Issue(ctx context.Context, req ApiKeyCreateRequest) (*ApiKey, string, error)
+	// Authenticate returns the active key matching the plaintext key.
+	Authenticate(ctx context.Context, key string) (ApiKey, error)
+	GetList(ctx context.Context) ([]ApiKey, error)
+	Revoke(ctx context.Context, id int64) error
+}
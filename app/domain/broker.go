@@ -1,12 +1,76 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"time"
+)
 
+type StockEventCause string
+
+const (
+	StockEventCauseInit            StockEventCause = "init"
+	StockEventCauseReservation     StockEventCause = "reservation"
+	StockEventCauseTransfer        StockEventCause = "transfer"
+	StockEventCauseAdjustment      StockEventCause = "adjustment"
+	StockEventCauseWarehouseStatus StockEventCause = "warehouse_status"
+	StockEventCauseReplay          StockEventCause = "replay"
+	StockEventCauseStocktake       StockEventCause = "stocktake"
+)
+
+const (
+	StockEventTypeAvailabilityChanged = "stock.availability.changed"
+	StockTransferEventTypeUpdated     = "stock.transfer.updated"
+	StockEventSchemaVersion           = 1
+)
+
+// StockMessage is what usecases hand to the publisher. Only ProductID and
+// Available are part of the legacy `stock.available` payload; the rest feed
+// the versioned StockEvent envelope.
 type StockMessage struct {
+	ProductID   int64           `json:"product_id"`
+	Available   int64           `json:"available"`
+	ShopID      int64           `json:"-"`
+	WarehouseID int64           `json:"-"`
+	Cause       StockEventCause `json:"-"`
+	// Tx is the transaction of the stock change, if any. The event sequence
+	// is taken in it, so rolled back changes don't use up numbers and
+	// sequences follow commit order.
+	Tx *sql.Tx `json:"-"`
+}
+
+type StockEvent struct {
+	EventID       string          `json:"event_id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	ShopID        int64           `json:"shop_id"`
+	WarehouseID   int64           `json:"warehouse_id,omitempty"`
+	Cause         StockEventCause `json:"cause"`
+	Sequence      int64           `json:"sequence"` // monotonic per product
+	Data          StockEventData  `json:"data"`
+}
+
+type StockEventData struct {
 	ProductID int64 `json:"product_id"`
 	Available int64 `json:"available"`
 }
 
+type StockTransferMessage struct {
+	ShopID         int64          `json:"shop_id"`
+	PreviousStatus TransferStatus `json:"previous_status,omitempty"`
+	Transfer       StockTransfer  `json:"transfer"`
+}
+
 type BrokerPublisher interface {
 	PublishStockAvailable(ctx context.Context, data StockMessage) error
+	PublishStockAlert(ctx context.Context, data StockAlertMessage) error
+	PublishStockSnapshot(ctx context.Context, data StockSnapshotMessage) error
+	PublishStockTransfer(ctx context.Context, data StockTransferMessage) error
+}
+
+type EventSequenceRepository interface {
+	// NextSequence increments the product's sequence with tx when it is not
+	// nil, holding the sequence row lock until tx ends.
+	NextSequence(ctx context.Context, productID int64, tx *sql.Tx) (int64, error)
 }
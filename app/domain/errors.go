@@ -8,6 +8,44 @@ var (
 	ErrInvalidRequest  = errors.New("invalid request")
 	ErrValidation      = errors.New("validation error")
 	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
 	ErrVersionMismatch = errors.New("version mismatch")
+	ErrConflict        = errors.New("conflict")
+	ErrTooManyRequests = errors.New("too many requests")
 	ErrInternal        = errors.New("internal server error")
 )
+
+// Error gives one of the sentinel errors above a stable, machine-readable
+// code. The sentinel (Kind) still decides how the error is reported, so
+// errors.Is(err, ErrConflict) keeps working for coded errors.
+type Error struct {
+	Code   string
+	Kind   error
+	Detail string
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+var (
+	ErrInsufficientStock     = &Error{Code: "INSUFFICIENT_STOCK", Kind: ErrConflict, Detail: "insufficient stock available"}
+	ErrQuantityBelowReserved = &Error{Code: "QUANTITY_BELOW_RESERVED", Kind: ErrConflict, Detail: "quantity is lower than the reserved stock at the warehouse"}
+	ErrTransferInvalidState  = &Error{Code: "TRANSFER_INVALID_STATE", Kind: ErrConflict, Detail: "transfer cannot move to the requested status from its current status"}
+	ErrWarehouseInactive     = &Error{Code: "WAREHOUSE_INACTIVE", Kind: ErrConflict, Detail: "warehouse is inactive"}
+	ErrWarehouseHasReserved  = &Error{Code: "WAREHOUSE_HAS_RESERVED_STOCK", Kind: ErrConflict, Detail: "warehouse still has reserved stock"}
+	ErrWarehouseShopMismatch = &Error{Code: "WAREHOUSE_SHOP_MISMATCH", Kind: ErrInvalidRequest, Detail: "warehouses must belong to the same shop as the caller"}
+	ErrPermissionDenied      = &Error{Code: "PERMISSION_DENIED", Kind: ErrForbidden, Detail: "your role does not allow this action"}
+	ErrRoleSelfChange        = &Error{Code: "ROLE_SELF_CHANGE", Kind: ErrConflict, Detail: "you cannot change or remove your own role"}
+	ErrApiKeyScopeMissing    = &Error{Code: "API_KEY_SCOPE_MISSING", Kind: ErrForbidden, Detail: "the api key does not have the scope required for this action"}
+	ErrConcurrencyLimited    = &Error{Code: "CONCURRENCY_LIMITED", Kind: ErrTooManyRequests, Detail: "too many requests in flight for this client"}
+	ErrStocktakeInvalidState = &Error{Code: "STOCKTAKE_INVALID_STATE", Kind: ErrConflict, Detail: "stocktake cannot do this in its current status"}
+	ErrStocktakeAlreadyOpen  = &Error{Code: "STOCKTAKE_ALREADY_OPEN", Kind: ErrConflict, Detail: "warehouse already has an open or submitted stocktake"}
+	ErrStocktakeUnknownItem  = &Error{Code: "STOCKTAKE_UNKNOWN_PRODUCT", Kind: ErrInvalidRequest, Detail: "product has no stock row in the stocktake warehouse"}
+	ErrStocktakeNotCounted   = &Error{Code: "STOCKTAKE_NOT_COUNTED", Kind: ErrConflict, Detail: "stocktake has no counted products"}
+	ErrWebhookURLNotAllowed  = &Error{Code: "WEBHOOK_URL_NOT_ALLOWED", Kind: ErrInvalidRequest, Detail: "webhook url must use https and must not point to a loopback, private or link-local address"}
+)
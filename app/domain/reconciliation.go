@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ReconciliationInvariant string
+
+const (
+	// Active reservations of a stock row must not exceed its quantity
+	InvariantReservedWithinQuantity ReconciliationInvariant = "reserved_within_quantity"
+	InvariantNonNegativeQuantity    ReconciliationInvariant = "non_negative_quantity"
+	// An in-progress transfer has a stock row at its source and destination.
+	// It does not show the source was deducted, see
+	// InvariantTransferSourceDeducted
+	InvariantTransferStockRows ReconciliationInvariant = "in_progress_transfer_stock_rows"
+	// Every product of a shop has a stock row in each active warehouse
+	InvariantStockRowPerWarehouse ReconciliationInvariant = "stock_row_per_warehouse"
+	// An in-progress transfer's quantity was deducted from its source row.
+	// Not checked, see UncheckedReconciliationInvariants
+	InvariantTransferSourceDeducted ReconciliationInvariant = "in_progress_transfer_source_deducted"
+)
+
+var ReconciliationInvariants = []ReconciliationInvariant{
+	InvariantReservedWithinQuantity,
+	InvariantNonNegativeQuantity,
+	InvariantTransferStockRows,
+	InvariantStockRowPerWarehouse,
+}
+
+// UncheckedReconciliationInvariants are reported with the reason they are not
+// checked, so a clean report is not read as covering them.
+var UncheckedReconciliationInvariants = map[ReconciliationInvariant]string{
+	InvariantTransferSourceDeducted: "transfers keep no ledger of their stock movements to match a source deduction against",
+}
+
+type ReconciliationViolation struct {
+	Invariant   ReconciliationInvariant `json:"invariant"`
+	ShopID      int64                   `json:"shop_id"`
+	ProductID   int64                   `json:"product_id"`
+	WarehouseID int64                   `json:"warehouse_id"`
+	StockID     int64                   `json:"stock_id,omitempty"`
+	TransferID  int64                   `json:"transfer_id,omitempty"`
+	Quantity    int64                   `json:"quantity"`
+	Reserved    int64                   `json:"reserved"`
+	Detail      string                  `json:"detail,omitempty"`
+	// Repaired is set when the run fixed the violation
+	Repaired bool `json:"repaired"`
+}
+
+type ReconciliationReport struct {
+	CheckedAt  time.Time                         `json:"checked_at"`
+	Counts     map[ReconciliationInvariant]int64 `json:"counts"`
+	Repaired   int64                             `json:"repaired"`
+	Violations []ReconciliationViolation         `json:"violations"`
+	// Unchecked lists invariants this run did not verify, with the reason
+	Unchecked map[ReconciliationInvariant]string `json:"unchecked"`
+}
+
+type ReconciliationRepository interface {
+	GetOverReservedStocks(ctx context.Context) ([]ReconciliationViolation, error)
+	GetNegativeStocks(ctx context.Context) ([]ReconciliationViolation, error)
+	GetInProgressTransfersWithoutStock(ctx context.Context) ([]ReconciliationViolation, error)
+	GetMissingStocks(ctx context.Context) ([]ReconciliationViolation, error)
+	// CreateMissingStock adds an empty stock row unless the warehouse already
+	// has one for the product, and returns the new row's ID or 0.
+	CreateMissingStock(ctx context.Context, productID, warehouseID int64) (int64, error)
+}
+
+type ReconciliationUsecase interface {
+	// Check scans every invariant and reports the rows breaking them. With
+	// repair, violations that are safe to fix without a person deciding
+	// (missing empty stock rows) are fixed; nothing else is changed.
+	Check(ctx context.Context, repair bool) (ReconciliationReport, error)
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ReplenishmentRule struct {
+	ID                int64     `json:"id"`
+	ShopID            int64     `json:"shop_id"`
+	ProductID         int64     `json:"product_id"`
+	WarehouseID       int64     `json:"warehouse_id"`
+	SourceWarehouseID int64     `json:"source_warehouse_id"`
+	MinLevel          int64     `json:"min_level"`
+	MaxLevel          int64     `json:"max_level"`
+	Active            bool      `json:"active"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type ReplenishmentRuleUpsertRequest struct {
+	ProductID         int64 `json:"product_id" validate:"required"`
+	WarehouseID       int64 `json:"warehouse_id" validate:"required"`
+	SourceWarehouseID int64 `json:"source_warehouse_id" validate:"required,nefield=WarehouseID"`
+	MinLevel          int64 `json:"min_level" validate:"gte=0"`
+	MaxLevel          int64 `json:"max_level" validate:"required,gtfield=MinLevel"`
+	Active            *bool `json:"active"`
+}
+
+type GetListReplenishmentRuleRequest struct {
+	ProductID   int64 `query:"product_id"`
+	WarehouseID int64 `query:"warehouse_id"`
+	Page        int64 `query:"page"`
+	Limit       int64 `query:"limit"`
+}
+
+type ReplenishmentRuleRepository interface {
+	Upsert(ctx context.Context, rule *ReplenishmentRule) error
+	GetByID(ctx context.Context, id int64) (ReplenishmentRule, error)
+	GetByProductIDAndWarehouseID(ctx context.Context, productID, warehouseID int64) (ReplenishmentRule, error)
+	GetListRule(ctx context.Context, shopID int64, param GetListReplenishmentRuleRequest) ([]ReplenishmentRule, error)
+	GetListRuleCount(ctx context.Context, shopID int64, param GetListReplenishmentRuleRequest) (int64, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type ReplenishmentUsecase interface {
+	UpsertRule(ctx context.Context, shopID int64, req ReplenishmentRuleUpsertRequest) (*ReplenishmentRule, error)
+	GetListRule(ctx context.Context, shopID int64, param GetListReplenishmentRuleRequest) ([]ReplenishmentRule, Metadata, error)
+	DeleteRule(ctx context.Context, id, shopID int64) error
+	// Replenish creates a transfer into the stock's warehouse when its
+	// availability is below the rule minimum and none is already open.
+	Replenish(ctx context.Context, stockID int64) (*StockTransfer, error)
+}
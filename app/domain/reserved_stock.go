@@ -41,9 +41,13 @@ type ReservedStockRepository interface {
 	GetTotalReservedStockByStockIDAndStatus(ctx context.Context, stockID int64, status ReservedStockStatus) (int64, error)
 	UpdateReservedStockStatus(ctx context.Context, id int64, status ReservedStockStatus) error
 	GetTotalReservedStockByStockIDsAndStatus(ctx context.Context, stockIDs []int64, status ReservedStockStatus) (map[int64]int64, error)
+	GetActiveReservedStocksCreatedBefore(ctx context.Context, before time.Time, limit int64) ([]ReservedStock, error)
 }
 
 type ReservedStockUsecase interface {
 	CreateReservedStock(ctx context.Context, req ReservedStockCreateRequest) error
 	UpdateReservedStockStatusByOrderID(ctx context.Context, orderID int64, req ReservedStockUpdateRequest) error
+	// ExpireReservedStocks cancels up to limit active reservations created
+	// before the cutoff, releasing their stock. With dryRun it only lists them.
+	ExpireReservedStocks(ctx context.Context, before time.Time, limit int64, dryRun bool) ([]ReservedStock, error)
 }
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleOwner            Role = "owner"
+	RoleInventoryManager Role = "inventory_manager"
+	RolePicker           Role = "picker"
+	RoleViewer           Role = "viewer"
+)
+
+type Permission string
+
+const (
+	PermissionWarehouseRead    Permission = "warehouse:read"
+	PermissionWarehouseManage  Permission = "warehouse:manage" // create, activate and deactivate warehouses
+	PermissionStockRead        Permission = "stock:read"
+	PermissionStockAdjust      Permission = "stock:adjust"
+	PermissionStocktakeCount   Permission = "stocktake:count" // open, count and submit stocktakes, approval needs stock:adjust
+	PermissionTransferRead     Permission = "transfer:read"
+	PermissionTransferCreate   Permission = "transfer:create"
+	PermissionAutomationRead   Permission = "automation:read"   // schedules, replenishment rules, alert thresholds
+	PermissionAutomationManage Permission = "automation:manage" // schedules, replenishment rules, alert thresholds
+	PermissionWebhookManage    Permission = "webhook:manage"
+	PermissionRoleManage       Permission = "role:manage"
+)
+
+// RolePermissions is the permission matrix. Roles not listed have no
+// permissions.
+var RolePermissions = map[Role][]Permission{
+	RoleOwner: {
+		PermissionWarehouseRead, PermissionWarehouseManage,
+		PermissionStockRead, PermissionStockAdjust, PermissionStocktakeCount,
+		PermissionTransferRead, PermissionTransferCreate,
+		PermissionAutomationRead, PermissionAutomationManage,
+		PermissionWebhookManage, PermissionRoleManage,
+	},
+	RoleInventoryManager: {
+		PermissionWarehouseRead,
+		PermissionStockRead, PermissionStockAdjust, PermissionStocktakeCount,
+		PermissionTransferRead, PermissionTransferCreate,
+		PermissionAutomationRead, PermissionAutomationManage,
+	},
+	RolePicker: {
+		PermissionWarehouseRead,
+		PermissionStockRead, PermissionStocktakeCount,
+		PermissionTransferRead, PermissionTransferCreate,
+	},
+	RoleViewer: {
+		PermissionWarehouseRead,
+		PermissionStockRead,
+		PermissionTransferRead,
+		PermissionAutomationRead,
+	},
+}
+
+func (r Role) Can(permission Permission) bool {
+	for _, p := range RolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type UserRole struct {
+	ShopID    int64     `json:"shop_id"`
+	UserID    int64     `json:"user_id"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UserRoleUpsertRequest struct {
+	UserID int64 `json:"user_id" validate:"required"`
+	Role   Role  `json:"role" validate:"required,oneof=owner inventory_manager picker viewer"`
+}
+
+type UserRoleRepository interface {
+	Upsert(ctx context.Context, userRole *UserRole) error
+	GetByShopIDAndUserID(ctx context.Context, shopID, userID int64) (UserRole, error)
+	GetByShopID(ctx context.Context, shopID int64) ([]UserRole, error)
+	ExistsByShopID(ctx context.Context, shopID int64) (bool, error)
+	Delete(ctx context.Context, shopID, userID int64) error
+}
+
+type UserRoleUsecase interface {
+	// ResolveRole returns the role stored for the user, or the configured
+	// default role when there is none. Users of shops without any stored
+	// role are owners, so shops predating roles keep their access.
+	ResolveRole(ctx context.Context, shopID, userID int64) (Role, error)
+	// Upsert stores the caller as owner too when it is the shop's first
+	// role, so assigning it doesn't drop the caller to the default role.
+	Upsert(ctx context.Context, shopID, callerID int64, req UserRoleUpsertRequest) (*UserRole, error)
+	GetByShopID(ctx context.Context, shopID int64) ([]UserRole, error)
+	Delete(ctx context.Context, shopID, callerID, userID int64) error
+}
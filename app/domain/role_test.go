@@ -0,0 +1,54 @@
+package domain
+
+import "testing"
+
+func TestRolePermissions(t *testing.T) {
+	all := []Permission{
+		PermissionWarehouseRead, PermissionWarehouseManage,
+		PermissionStockRead, PermissionStockAdjust, PermissionStocktakeCount,
+		PermissionTransferRead, PermissionTransferCreate,
+		PermissionAutomationRead, PermissionAutomationManage,
+		PermissionWebhookManage, PermissionRoleManage,
+	}
+
+	tests := []struct {
+		role    Role
+		allowed []Permission
+	}{
+		{RoleOwner, all},
+		{RoleInventoryManager, []Permission{
+			PermissionWarehouseRead,
+			PermissionStockRead, PermissionStockAdjust, PermissionStocktakeCount,
+			PermissionTransferRead, PermissionTransferCreate,
+			PermissionAutomationRead, PermissionAutomationManage,
+		}},
+		{RolePicker, []Permission{
+			PermissionWarehouseRead,
+			PermissionStockRead, PermissionStocktakeCount,
+			PermissionTransferRead, PermissionTransferCreate,
+		}},
+		{RoleViewer, []Permission{
+			PermissionWarehouseRead,
+			PermissionStockRead,
+			PermissionTransferRead,
+			PermissionAutomationRead,
+		}},
+		{Role("unknown"), nil},
+		{Role(""), nil},
+	}
+
+	for _, tt := range tests {
+		allowed := make(map[Permission]bool, len(tt.allowed))
+		for _, permission := range tt.allowed {
+			allowed[permission] = true
+		}
+
+		for _, permission := range all {
+			t.Run(string(tt.role)+"/"+string(permission), func(t *testing.T) {
+				if got := tt.role.Can(permission); got != allowed[permission] {
+					t.Fatalf("%q.Can(%q) = %v, want %v", tt.role, permission, got, allowed[permission])
+				}
+			})
+		}
+	}
+}
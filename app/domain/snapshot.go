@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ProductAvailability struct {
+	ProductID int64 `json:"product_id"`
+	ShopID    int64 `json:"shop_id"`
+	Available int64 `json:"available"`
+}
+
+type StockSnapshotMessage struct {
+	SnapshotID string    `json:"snapshot_id"`
+	ProductID  int64     `json:"product_id"`
+	ShopID     int64     `json:"shop_id"`
+	Available  int64     `json:"available"`
+	SnapshotAt time.Time `json:"snapshot_at"`
+}
+
+type AvailabilitySnapshotRequest struct {
+	SnapshotID    string `json:"snapshot_id"` // reuse to keep pages of one run together
+	ShopID        int64  `json:"shop_id"`
+	Cursor        int64  `json:"cursor"` // last product ID already published
+	Limit         int64  `json:"limit" validate:"omitempty,gt=0,lte=10000"`
+	RatePerSecond int64  `json:"rate_per_second" validate:"omitempty,gt=0,lte=5000"`
+}
+
+type AvailabilitySnapshotResult struct {
+	SnapshotID string `json:"snapshot_id"`
+	Published  int64  `json:"published"`
+	NextCursor int64  `json:"next_cursor"`
+	Done       bool   `json:"done"`
+}
+
+// AvailabilityReplayRequest selects the products whose current availability
+// is published again as stock.availability.changed events. Without product
+// IDs every product of ShopID is replayed, or of every shop when ShopID is 0.
+type AvailabilityReplayRequest struct {
+	ShopID        int64
+	ProductIDs    []int64
+	RatePerSecond int64
+}
+
+type SnapshotUsecase interface {
+	// PublishAvailabilitySnapshot publishes up to req.Limit products after
+	// req.Cursor and returns the cursor to resume from. It stops early once
+	// a time budget is spent, so a low rate publishes fewer products.
+	PublishAvailabilitySnapshot(ctx context.Context, req AvailabilitySnapshotRequest) (AvailabilitySnapshotResult, error)
+	// ReplayAvailability republishes availability events so consumers that
+	// missed some can converge. It returns how many events were published.
+	ReplayAvailability(ctx context.Context, req AvailabilityReplayRequest) (int64, error)
+}
@@ -71,6 +71,8 @@ type StockRepository interface {
 	GetListStockCount(ctx context.Context, shopID int64, param GetListStockRequest) (int64, error)
 	GetByWarehouseID(ctx context.Context, warehouseID int64) ([]Stock, error)
 	GetAvailableStockByProductIDs(ctx context.Context, productIDs []int64) (map[int64]int64, error)
+	GetShopIDByProductID(ctx context.Context, productID int64) (int64, error)
+	GetProductAvailabilities(ctx context.Context, shopID, afterProductID, limit int64) ([]ProductAvailability, error)
 	// UpdateReservedStocks(ctx context.Context, id, reservedQuantity, version int64, tx *sql.Tx) error
 
 	LockForUpdate(ctx context.Context, id int64, tx *sql.Tx) (Stock, error)
@@ -80,6 +82,14 @@ type StockRepository interface {
 type StockService interface {
 	InitStock(ctx context.Context, req StockCreateRequest) ([]Stock, error)
 	GetAvailableStockByProductID(ctx context.Context, productID int64) (AvailableStock, error)
-	UpdateQuantity(ctx context.Context, id, shopID int64, req UpdateQuantityRequest) error
+	GetAvailableStockByProductIDs(ctx context.Context, productIDs []int64) ([]AvailableStock, error)
+	// UpdateQuantity sets an absolute quantity, recorded in the adjustment
+	// ledger as a correction. userID is 0 from the command line.
+	UpdateQuantity(ctx context.Context, id, shopID, userID int64, req UpdateQuantityRequest) error
+	// AdjustQuantity adds req.Delta to the current quantity under the stock
+	// row lock, so concurrent adjustments don't overwrite each other.
+	AdjustQuantity(ctx context.Context, id, shopID, userID int64, req StockAdjustRequest) (StockAdjustment, error)
 	GetListStock(ctx context.Context, shopID int64, param GetListStockRequest) ([]Stock, Metadata, error)
+	GetListStockAdjustment(ctx context.Context, shopID int64, param GetListStockAdjustmentRequest) ([]StockAdjustment, Metadata, error)
+	GetAdjustmentReport(ctx context.Context, shopID int64, param StockAdjustmentReportRequest) ([]StockAdjustmentReasonTotal, error)
 }
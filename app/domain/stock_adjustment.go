@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type AdjustmentReason string
+
+const (
+	AdjustmentReasonReceived   AdjustmentReason = "received"
+	AdjustmentReasonDamaged    AdjustmentReason = "damaged"
+	AdjustmentReasonLost       AdjustmentReason = "lost"
+	AdjustmentReasonFound      AdjustmentReason = "found"
+	AdjustmentReasonCorrection AdjustmentReason = "correction" // also absolute updates and stocktake approvals
+	AdjustmentReasonReturned   AdjustmentReason = "returned"
+)
+
+// StockAdjustment is a ledger entry for a manual change of a stock quantity.
+// Before and after are read under the stock row lock, so entries of a stock
+// chain up even when adjustments race.
+type StockAdjustment struct {
+	ID             int64            `json:"id"`
+	ShopID         int64            `json:"shop_id"`
+	StockID        int64            `json:"stock_id"`
+	ProductID      int64            `json:"product_id"`
+	WarehouseID    int64            `json:"warehouse_id"`
+	Delta          int64            `json:"delta"`
+	QuantityBefore int64            `json:"quantity_before"`
+	QuantityAfter  int64            `json:"quantity_after"`
+	Reason         AdjustmentReason `json:"reason"`
+	Note           string           `json:"note"`
+	UserID         *int64           `json:"user_id"` // nil when made from the command line
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+type StockAdjustRequest struct {
+	Delta  int64            `json:"delta" validate:"required"`
+	Reason AdjustmentReason `json:"reason" validate:"required,oneof=received damaged lost found correction returned"`
+	Note   string           `json:"note" validate:"max=500"`
+}
+
+type GetListStockAdjustmentRequest struct {
+	StockID     int64            `query:"stock_id"`
+	ProductID   int64            `query:"product_id"`
+	WarehouseID int64            `query:"warehouse_id"`
+	Reason      AdjustmentReason `query:"reason"`
+	From        string           `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To          string           `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page        int64            `query:"page"`
+	Limit       int64            `query:"limit"`
+}
+
+type StockAdjustmentReportRequest struct {
+	ProductID   int64  `query:"product_id"`
+	WarehouseID int64  `query:"warehouse_id"`
+	From        string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To          string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// StockAdjustmentReasonTotal sums the adjustments of one reason. Increase
+// and Decrease are both positive, Net is their difference.
+type StockAdjustmentReasonTotal struct {
+	Reason      AdjustmentReason `json:"reason"`
+	Adjustments int64            `json:"adjustments"`
+	Increase    int64            `json:"increase"`
+	Decrease    int64            `json:"decrease"`
+	Net         int64            `json:"net"`
+}
+
+type StockAdjustmentRepository interface {
+	Create(ctx context.Context, adjustment *StockAdjustment, tx *sql.Tx) error
+	GetListStockAdjustment(ctx context.Context, shopID int64, param GetListStockAdjustmentRequest) ([]StockAdjustment, error)
+	GetListStockAdjustmentCount(ctx context.Context, shopID int64, param GetListStockAdjustmentRequest) (int64, error)
+	GetTotalsByReason(ctx context.Context, shopID int64, param StockAdjustmentReportRequest) ([]StockAdjustmentReasonTotal, error)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type StockAlertState string
+
+const (
+	StockAlertStateOK  StockAlertState = "ok"
+	StockAlertStateLow StockAlertState = "low"
+	StockAlertStateOut StockAlertState = "out"
+)
+
+type StockAlertType string
+
+const (
+	StockAlertTypeLow       StockAlertType = "low"
+	StockAlertTypeOut       StockAlertType = "out"
+	StockAlertTypeRestocked StockAlertType = "restocked"
+)
+
+type StockAlertThreshold struct {
+	ID           int64     `json:"id"`
+	ShopID       int64     `json:"shop_id"`
+	ProductID    int64     `json:"product_id"` // 0 is the shop-wide default
+	LowThreshold int64     `json:"low_threshold"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type StockAlertThresholdUpsertRequest struct {
+	ProductID    int64 `json:"product_id"`
+	LowThreshold int64 `json:"low_threshold" validate:"gte=0"`
+}
+
+type StockAlertMessage struct {
+	Type         StockAlertType `json:"type"`
+	ShopID       int64          `json:"shop_id"`
+	ProductID    int64          `json:"product_id"`
+	Available    int64          `json:"available"`
+	LowThreshold int64          `json:"low_threshold"`
+}
+
+type StockAlertRepository interface {
+	UpsertThreshold(ctx context.Context, threshold *StockAlertThreshold) error
+	GetThresholdByID(ctx context.Context, id int64) (StockAlertThreshold, error)
+	GetThresholdsByShopID(ctx context.Context, shopID int64) ([]StockAlertThreshold, error)
+	// GetEffectiveThreshold returns the product threshold, falling back to the shop default.
+	GetEffectiveThreshold(ctx context.Context, shopID, productID int64) (StockAlertThreshold, error)
+	DeleteThreshold(ctx context.Context, id int64) error
+	// SwapState stores the new state and returns the previous one. changed is
+	// false when the stored state already equals state.
+	SwapState(ctx context.Context, shopID, productID int64, state StockAlertState) (previous StockAlertState, changed bool, err error)
+}
+
+type StockAlertUsecase interface {
+	UpsertThreshold(ctx context.Context, shopID int64, req StockAlertThresholdUpsertRequest) (*StockAlertThreshold, error)
+	GetThresholds(ctx context.Context, shopID int64) ([]StockAlertThreshold, error)
+	DeleteThreshold(ctx context.Context, id, shopID int64) error
+	Evaluate(ctx context.Context, productID, available int64) error
+}
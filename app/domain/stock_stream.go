@@ -0,0 +1,26 @@
+package domain
+
+const StockStreamEventTypeReset = "stream.reset"
+
+// StockStreamEvent is a change pushed to dashboards over SSE. IDs increase
+// monotonically within the process and double as the SSE `id:` field.
+type StockStreamEvent struct {
+	ID     int64  `json:"id"`
+	Type   string `json:"type"`
+	ShopID int64  `json:"-"`
+	Data   any    `json:"data"`
+}
+
+type StockStreamSubscription struct {
+	// Backlog holds buffered events after the requested Last-Event-ID. It
+	// starts with a reset event when that ID is no longer in the buffer.
+	Backlog []StockStreamEvent
+	Events  <-chan StockStreamEvent
+	Cancel  func()
+}
+
+type StockStreamUsecase interface {
+	Publish(shopID int64, eventType string, data any)
+	Subscribe(shopID int64, lastEventID int64) StockStreamSubscription
+	Close()
+}
@@ -50,18 +50,27 @@ type GetListStockTransferRequest struct {
 }
 
 type StockTransferRepository interface {
-	Create(ctx context.Context, transfer *StockTransfer) error
+	Create(ctx context.Context, transfer *StockTransfer, tx *sql.Tx) error
 	GetByID(ctx context.Context, id int64) (StockTransfer, error)
+	LockForUpdate(ctx context.Context, id int64, tx *sql.Tx) (StockTransfer, error)
 	UpdateStatus(ctx context.Context, st StockTransfer, tx *sql.Tx) error
 	GetListStockTransfer(ctx context.Context, shopID int64, param GetListStockTransferRequest) ([]StockTransfer, error)
 	GetListStockTransferCount(ctx context.Context, shopID int64, param GetListStockTransferRequest) (int64, error)
+	GetOpenTransferCount(ctx context.Context, productID, toWarehouse int64) (int64, error)
 
 	WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error
 }
 
 type StockTransferUsecase interface {
 	CreateTransfer(ctx context.Context, shopID int64, transfer StockTransferCreateRequest) (*StockTransfer, error)
+	// CreateTransferTx creates the transfer in the caller's transaction, so it
+	// is rolled back with the rest of the caller's work.
+	CreateTransferTx(ctx context.Context, shopID int64, transfer StockTransferCreateRequest, tx *sql.Tx) (*StockTransfer, error)
 	GetTransferByID(ctx context.Context, id int64, shopID *int64) (StockTransfer, error)
 	UpdateTransferStatus(ctx context.Context, id int64, req StockTransferUpdateRequest) error
+	// UpdateTransferStatusTx moves st in the caller's transaction, also for a
+	// transfer created in it, and updates st. st must be created or locked in
+	// tx so its status is current.
+	UpdateTransferStatusTx(ctx context.Context, st *StockTransfer, req StockTransferUpdateRequest, tx *sql.Tx) error
 	GetListStockTransfer(ctx context.Context, shopID int64, param GetListStockTransferRequest) ([]StockTransfer, Metadata, error)
 }
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type TransferRecurrence string
+
+const (
+	TransferRecurrenceNone    TransferRecurrence = ""
+	TransferRecurrenceWeekly  TransferRecurrence = "weekly"
+	TransferRecurrenceMonthly TransferRecurrence = "monthly"
+)
+
+type ScheduleRunStatus string
+
+const (
+	ScheduleRunStatusExecuted ScheduleRunStatus = "executed"
+	ScheduleRunStatusSkipped  ScheduleRunStatus = "skipped"
+	ScheduleRunStatusFailed   ScheduleRunStatus = "failed"
+)
+
+type StockTransferSchedule struct {
+	ID            int64              `json:"id"`
+	ShopID        int64              `json:"shop_id"`
+	ProductID     int64              `json:"product_id"`
+	FromWarehouse int64              `json:"from_warehouse"`
+	ToWarehouse   int64              `json:"to_warehouse"`
+	Quantity      int64              `json:"quantity"`  // fixed quantity per run, ignored when TopUpTo is set
+	TopUpTo       int64              `json:"top_up_to"` // top up destination availability to this level
+	Recurrence    TransferRecurrence `json:"recurrence"`
+	Description   string             `json:"description"`
+	ScheduledAt   time.Time          `json:"scheduled_at"` // next due time
+	DayOfMonth    int                `json:"-"`            // day monthly runs fall on, the last day in shorter months
+	Active        bool               `json:"active"`
+	LastRunAt     *time.Time         `json:"last_run_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+type StockTransferScheduleRun struct {
+	ID         int64             `json:"id"`
+	ScheduleID int64             `json:"schedule_id"`
+	TransferID *int64            `json:"transfer_id"`
+	Quantity   int64             `json:"quantity"`
+	Status     ScheduleRunStatus `json:"status"` // "executed", "skipped", "failed"
+	Message    string            `json:"message"`
+	RunAt      time.Time         `json:"run_at"`
+}
+
+type StockTransferScheduleCreateRequest struct {
+	ProductID     int64              `json:"product_id" validate:"required"`
+	FromWarehouse int64              `json:"from_warehouse" validate:"required"`
+	ToWarehouse   int64              `json:"to_warehouse" validate:"required,nefield=FromWarehouse"`
+	Quantity      int64              `json:"quantity" validate:"required_without=TopUpTo,gte=0"`
+	TopUpTo       int64              `json:"top_up_to" validate:"required_without=Quantity,gte=0"`
+	Recurrence    TransferRecurrence `json:"recurrence" validate:"omitempty,oneof=weekly monthly"`
+	ScheduledAt   time.Time          `json:"scheduled_at" validate:"required"`
+	Description   string             `json:"description"`
+}
+
+type GetListStockTransferScheduleRequest struct {
+	Page      int64  `query:"page"`
+	Limit     int64  `query:"limit"`
+	SortBy    string `query:"sort_by"`
+	SortOrder string `query:"sort_order"`
+	Active    *bool  `query:"active"` // nil lists active and inactive schedules
+}
+
+type StockTransferScheduleRepository interface {
+	Create(ctx context.Context, schedule *StockTransferSchedule) error
+	GetByID(ctx context.Context, id int64) (StockTransferSchedule, error)
+	// ClaimDue locks the earliest due schedule not in skip, skipping rows
+	// locked by other instances. ErrNotFound when none is left.
+	ClaimDue(ctx context.Context, now time.Time, skip []int64, tx *sql.Tx) (StockTransferSchedule, error)
+	GetListSchedule(ctx context.Context, shopID int64, param GetListStockTransferScheduleRequest) ([]StockTransferSchedule, error)
+	GetListScheduleCount(ctx context.Context, shopID int64, param GetListStockTransferScheduleRequest) (int64, error)
+	UpdateNextRun(ctx context.Context, schedule StockTransferSchedule, tx *sql.Tx) error
+	UpdateActive(ctx context.Context, id int64, active bool) error
+	CreateRun(ctx context.Context, run *StockTransferScheduleRun, tx *sql.Tx) error
+	GetRunsByScheduleID(ctx context.Context, scheduleID int64, limit int64) ([]StockTransferScheduleRun, error)
+
+	WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error
+}
+
+type StockTransferScheduleUsecase interface {
+	CreateSchedule(ctx context.Context, shopID int64, req StockTransferScheduleCreateRequest) (*StockTransferSchedule, error)
+	GetListSchedule(ctx context.Context, shopID int64, param GetListStockTransferScheduleRequest) ([]StockTransferSchedule, Metadata, error)
+	GetRuns(ctx context.Context, id, shopID int64) ([]StockTransferScheduleRun, error)
+	CancelSchedule(ctx context.Context, id, shopID int64) error
+	RunDueSchedules(ctx context.Context, now time.Time) error
+}
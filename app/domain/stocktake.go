@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type StocktakeStatus string
+
+const (
+	StocktakeStatusOpen      StocktakeStatus = "open"
+	StocktakeStatusSubmitted StocktakeStatus = "submitted"
+	StocktakeStatusApproved  StocktakeStatus = "approved"
+	StocktakeStatusCancelled StocktakeStatus = "cancelled"
+)
+
+// Stocktake is a count of one warehouse. System quantities are frozen per
+// line when it is opened, so variances are against what the system believed
+// at that time, not against sales made while counting.
+type Stocktake struct {
+	ID          int64           `json:"id"`
+	ShopID      int64           `json:"shop_id"`
+	WarehouseID int64           `json:"warehouse_id"`
+	Status      StocktakeStatus `json:"status"` // "open", "submitted", "approved", "cancelled"
+	Note        string          `json:"note"`
+	OpenedBy    int64           `json:"opened_by"`
+	SubmittedBy *int64          `json:"submitted_by"`
+	SubmittedAt *time.Time      `json:"submitted_at"`
+	ClosedBy    *int64          `json:"closed_by"` // approver or canceller
+	ClosedAt    *time.Time      `json:"closed_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Lines       []StocktakeLine `json:"lines,omitempty"`
+}
+
+type StocktakeLine struct {
+	ID              int64      `json:"id"`
+	StocktakeID     int64      `json:"stocktake_id"`
+	StockID         int64      `json:"stock_id"`
+	ProductID       int64      `json:"product_id"`
+	SystemQuantity  int64      `json:"system_quantity"`  // frozen when the stocktake was opened
+	CountedQuantity *int64     `json:"counted_quantity"` // nil until counted
+	Variance        *int64     `json:"variance"`         // counted minus system, nil until counted
+	CountedBy       *int64     `json:"counted_by"`
+	CountedAt       *time.Time `json:"counted_at"`
+	// AppliedQuantity is the stock quantity set on approval
+	AppliedQuantity *int64 `json:"applied_quantity"`
+}
+
+type StocktakeCreateRequest struct {
+	WarehouseID int64  `json:"warehouse_id" validate:"required"`
+	Note        string `json:"note" validate:"max=500"`
+}
+
+type StocktakeCount struct {
+	ProductID       int64 `json:"product_id" validate:"required"`
+	CountedQuantity int64 `json:"counted_quantity" validate:"gte=0"`
+}
+
+type StocktakeCountRequest struct {
+	Counts []StocktakeCount `json:"counts" validate:"required,min=1,max=500,dive"`
+}
+
+type GetListStocktakeRequest struct {
+	WarehouseID int64           `query:"warehouse_id"`
+	Status      StocktakeStatus `query:"status"`
+	Page        int64           `query:"page"`
+	Limit       int64           `query:"limit"`
+}
+
+type StocktakeRepository interface {
+	// Create inserts the stocktake and a line per stock row of its
+	// warehouse with the current quantity.
+	Create(ctx context.Context, stocktake *Stocktake, tx *sql.Tx) error
+	GetByID(ctx context.Context, id int64) (Stocktake, error)
+	GetLines(ctx context.Context, stocktakeID int64) ([]StocktakeLine, error)
+	GetListStocktake(ctx context.Context, shopID int64, param GetListStocktakeRequest) ([]Stocktake, error)
+	GetListStocktakeCount(ctx context.Context, shopID int64, param GetListStocktakeRequest) (int64, error)
+	// LockForUpdate serialises status changes and counting of a stocktake
+	LockForUpdate(ctx context.Context, id int64, tx *sql.Tx) (Stocktake, error)
+	// UpdateCount records a count and reports false when the product has no
+	// line in the stocktake.
+	UpdateCount(ctx context.Context, stocktakeID int64, count StocktakeCount, userID int64, tx *sql.Tx) (bool, error)
+	UpdateStatus(ctx context.Context, stocktake Stocktake, tx *sql.Tx) error
+	UpdateAppliedQuantity(ctx context.Context, lineID, quantity int64, tx *sql.Tx) error
+
+	WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error
+}
+
+type StocktakeUsecase interface {
+	Open(ctx context.Context, shopID, userID int64, req StocktakeCreateRequest) (*Stocktake, error)
+	GetByID(ctx context.Context, id, shopID int64) (Stocktake, error)
+	GetListStocktake(ctx context.Context, shopID int64, param GetListStocktakeRequest) ([]Stocktake, Metadata, error)
+	// RecordCounts sets counted quantities of an open stocktake; counting a
+	// product again replaces the earlier count.
+	RecordCounts(ctx context.Context, id, shopID, userID int64, req StocktakeCountRequest) (Stocktake, error)
+	Submit(ctx context.Context, id, shopID, userID int64) (Stocktake, error)
+	// Approve applies the variance of every counted line to the current
+	// stock quantity, all lines or none.
+	Approve(ctx context.Context, id, shopID, userID int64) (Stocktake, error)
+	Cancel(ctx context.Context, id, shopID, userID int64) (Stocktake, error)
+}
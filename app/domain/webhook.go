@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookEventStockAvailabilityChanged = StockEventTypeAvailabilityChanged
+	WebhookEventStockLow                 = "stock.low"
+	WebhookEventStockOut                 = "stock.out"
+	WebhookEventStockRestocked           = "stock.restocked"
+	WebhookEventStockTransferUpdated     = StockTransferEventTypeUpdated
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+type Webhook struct {
+	ID         int64     `json:"id"`
+	ShopID     int64     `json:"shop_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"` // only returned on create
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      int64                 `json:"webhook_id"`
+	EventID        string                `json:"event_id"`
+	EventType      string                `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"` // "pending", "succeeded", "failed"
+	Attempts       int64                 `json:"attempts"`
+	LastStatusCode int64                 `json:"last_status_code"`
+	LastError      string                `json:"last_error"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// WebhookPayload is the JSON body POSTed to the shop's URL.
+type WebhookPayload struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	ShopID     int64     `json:"shop_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+type WebhookCreateRequest struct {
+	URL        string   `json:"url" validate:"required,url,startswith=https://"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=stock.availability.changed stock.low stock.out stock.restocked stock.transfer.updated"`
+}
+
+type GetListWebhookDeliveryRequest struct {
+	Page   int64  `query:"page"`
+	Limit  int64  `query:"limit"`
+	Status string `query:"status"`
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	GetByID(ctx context.Context, id int64) (Webhook, error)
+	GetByShopID(ctx context.Context, shopID int64) ([]Webhook, error)
+	GetActiveByShopIDAndEventType(ctx context.Context, shopID int64, eventType string) ([]Webhook, error)
+	Delete(ctx context.Context, id int64) error
+
+	CreateDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	GetDeliveryByID(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetListDelivery(ctx context.Context, webhookID int64, param GetListWebhookDeliveryRequest) ([]WebhookDelivery, error)
+	GetListDeliveryCount(ctx context.Context, webhookID int64, param GetListWebhookDeliveryRequest) (int64, error)
+	// ClaimDueDeliveries pushes next_attempt_at of due pending deliveries to
+	// leaseUntil and returns them, so concurrent dispatchers don't overlap.
+	ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int64) ([]WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery WebhookDelivery) error
+	ResetDelivery(ctx context.Context, id int64) error
+}
+
+type WebhookSender interface {
+	// Send POSTs a signed payload and returns the response status code.
+	Send(ctx context.Context, webhook Webhook, delivery WebhookDelivery) (int, error)
+}
+
+type WebhookUsecase interface {
+	Create(ctx context.Context, shopID int64, req WebhookCreateRequest) (*Webhook, error)
+	GetByShopID(ctx context.Context, shopID int64) ([]Webhook, error)
+	Delete(ctx context.Context, id, shopID int64) error
+	GetListDelivery(ctx context.Context, id, shopID int64, param GetListWebhookDeliveryRequest) ([]WebhookDelivery, Metadata, error)
+	Redeliver(ctx context.Context, id, deliveryID, shopID int64) error
+
+	Enqueue(ctx context.Context, shopID int64, eventType string, data any) error
+	DeliverDue(ctx context.Context, now time.Time) error
+}
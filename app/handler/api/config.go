@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"time"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConfigHandler struct {
+	live *config.Live
+}
+
+func NewConfigHandler(live *config.Live) *ConfigHandler {
+	return &ConfigHandler{live: live}
+}
+
+type runtimeConfigResponse struct {
+	Config     map[string]any `json:"config"`
+	Reloadable []string       `json:"reloadable"`
+	ReloadedAt *time.Time     `json:"reloaded_at"`
+}
+
+// Get shows the config the running instance uses, with secrets masked.
+func (h *ConfigHandler) Get(c *fiber.Ctx) error {
+	resp := runtimeConfigResponse{
+		Config:     h.live.Get().Redacted(),
+		Reloadable: config.ReloadableKeys(),
+	}
+	if reloadedAt := h.live.ReloadedAt(); !reloadedAt.IsZero() {
+		resp.ReloadedAt = &reloadedAt
+	}
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp))
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"warehouse-service/pkg/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthReport serves the per-dependency report behind /ready, with 503 when
+// any dependency is down.
+func HealthReport(checker *health.Checker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := checker.Report(c.UserContext())
+		if report.Status != health.StatusUp {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+		}
+		return c.Status(fiber.StatusOK).JSON(report)
+	}
+}
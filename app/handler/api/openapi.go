@@ -0,0 +1,70 @@
+package handler
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>warehouse-service API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>`
+
+func OpenAPISpec(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(openAPISpec)
+}
+
+func SwaggerUI(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(swaggerUIPage)
+}
+
+// UndocumentedRoutes returns the registered routes that have no operation in
+// openapi.json, formatted as "METHOD /path". Spec-only, operational and HEAD
+// routes are ignored.
+func UndocumentedRoutes(routes []fiber.Route) []string {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		slog.Error("[openapi] UndocumentedRoutes", "unmarshal", err)
+		return nil
+	}
+
+	var missing []string
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead || route.Path == "/openapi.json" || route.Path == "/docs" || route.Path == "/metrics" || route.Path == "/health" {
+			continue
+		}
+
+		// Fiber uses :param, OpenAPI uses {param}
+		segments := strings.Split(route.Path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+			}
+		}
+
+		if _, ok := spec.Paths[strings.Join(segments, "/")][strings.ToLower(route.Method)]; !ok {
+			missing = append(missing, fmt.Sprintf("%s %s", route.Method, route.Path))
+		}
+	}
+	return missing
+}
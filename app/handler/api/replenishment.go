@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"log/slog"
+	"strconv"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReplenishmentHandler struct {
+	replenishmentUsecase domain.ReplenishmentUsecase
+	validator            *validator.Validate
+}
+
+func NewReplenishmentHandler(replenishmentUsecase domain.ReplenishmentUsecase, validator *validator.Validate) *ReplenishmentHandler {
+	return &ReplenishmentHandler{replenishmentUsecase, validator}
+}
+
+func (h *ReplenishmentHandler) UpsertRule(c *fiber.Ctx) error {
+	var req domain.ReplenishmentRuleUpsertRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] UpsertRule", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] UpsertRule", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] UpsertRule", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	rule, err := h.replenishmentUsecase.UpsertRule(c.UserContext(), shopID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] UpsertRule", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(rule))
+}
+
+func (h *ReplenishmentHandler) GetListRule(c *fiber.Ctx) error {
+	var param domain.GetListReplenishmentRuleRequest
+	if err := c.QueryParser(&param); err != nil {
+		slog.WarnContext(c.UserContext(), "[replenishmentHandler] GetListRule", "queryParser", err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] GetListRule", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.Limit <= 0 {
+		param.Limit = 10
+	}
+	if param.Limit > 20 {
+		param.Limit = 20
+	}
+
+	rules, metadata, err := h.replenishmentUsecase.GetListRule(c.UserContext(), shopID, param)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] GetListRule", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithMetadata(rules, metadata))
+}
+
+func (h *ReplenishmentHandler) DeleteRule(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] DeleteRule", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] DeleteRule", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	err = h.replenishmentUsecase.DeleteRule(c.UserContext(), id, shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[replenishmentHandler] DeleteRule", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
+}
@@ -25,20 +25,19 @@ func NewReservedStockHandler(usecase domain.ReservedStockUsecase, validator *val
 func (h *ReservedStockHandler) CreateReservedStock(c *fiber.Ctx) error {
 	var req domain.ReservedStockCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] CreateReservedStock", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] CreateReservedStock", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] CreateReservedStock", "validator", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrValidation))
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] CreateReservedStock", "validator", err)
+		return response.ValidationError(c, err)
 	}
 
-	err := h.usecase.CreateReservedStock(c.Context(), req)
+	err := h.usecase.CreateReservedStock(c.UserContext(), req)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] CreateReservedStock", "usecase", err)
-		status, resp := response.FromError(err)
-		return c.Status(status).JSON(resp)
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] CreateReservedStock", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(response.Success(nil))
@@ -47,31 +46,30 @@ func (h *ReservedStockHandler) CreateReservedStock(c *fiber.Ctx) error {
 func (h *ReservedStockHandler) UpdateReservedStockStatus(c *fiber.Ctx) error {
 	orderIDStr := c.Params("order_id")
 	if orderIDStr == "" {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] UpdateReservedStockStatus", "orderID", "missing")
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] UpdateReservedStockStatus", "orderID", "missing")
+		return response.Error(c, domain.ErrBadRequest)
 	}
 	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
 	if err != nil || orderID <= 0 {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] UpdateReservedStockStatus", "parseInt:"+orderIDStr, err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] UpdateReservedStockStatus", "parseInt:"+orderIDStr, err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	var req domain.ReservedStockUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] UpdateReservedStockStatus", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] UpdateReservedStockStatus", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] UpdateReservedStockStatus", "validator", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrValidation))
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] UpdateReservedStockStatus", "validator", err)
+		return response.ValidationError(c, err)
 	}
 
-	err = h.usecase.UpdateReservedStockStatusByOrderID(c.Context(), orderID, req)
+	err = h.usecase.UpdateReservedStockStatusByOrderID(c.UserContext(), orderID, req)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[reservedStockHandler] UpdateReservedStockStatus", "usecase", err)
-		status, resp := response.FromError(err)
-		return c.Status(status).JSON(resp)
+		slog.ErrorContext(c.UserContext(), "[reservedStockHandler] UpdateReservedStockStatus", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
@@ -0,0 +1,149 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is stable and meant for
+// programmatic handling; Title and Detail are for humans.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// sentinelCodes gives every domain sentinel error its status and default code.
+var sentinelCodes = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrValidation, fiber.StatusBadRequest, "VALIDATION_FAILED"},
+	{domain.ErrInvalidRequest, fiber.StatusBadRequest, "INVALID_REQUEST"},
+	{domain.ErrBadRequest, fiber.StatusBadRequest, "BAD_REQUEST"},
+	{domain.ErrUnauthorized, fiber.StatusUnauthorized, "UNAUTHORIZED"},
+	{domain.ErrForbidden, fiber.StatusForbidden, "FORBIDDEN"},
+	{domain.ErrNotFound, fiber.StatusNotFound, "NOT_FOUND"},
+	{domain.ErrConflict, fiber.StatusConflict, "CONFLICT"},
+	{domain.ErrVersionMismatch, fiber.StatusConflict, "VERSION_MISMATCH"},
+	{domain.ErrTooManyRequests, fiber.StatusTooManyRequests, "RATE_LIMITED"},
+}
+
+func NewProblem(err error) Problem {
+	for _, sentinel := range sentinelCodes {
+		if !errors.Is(err, sentinel.err) {
+			continue
+		}
+
+		problem := Problem{
+			Title:  sentinel.err.Error(),
+			Status: sentinel.status,
+			Code:   sentinel.code,
+		}
+		var domainErr *domain.Error
+		if errors.As(err, &domainErr) {
+			problem.Code = domainErr.Code
+			problem.Detail = domainErr.Detail
+		}
+		problem.Type = problemType(problem.Code)
+		return problem
+	}
+
+	// Unknown errors may carry internals, so only the generic title is exposed.
+	return Problem{
+		Type:   problemType("INTERNAL"),
+		Title:  domain.ErrInternal.Error(),
+		Status: fiber.StatusInternalServerError,
+		Code:   "INTERNAL",
+	}
+}
+
+// Error writes err as application/problem+json with the status it maps to.
+func Error(c *fiber.Ctx, err error) error {
+	return write(c, NewProblem(err))
+}
+
+// ValidationError reports the failed fields of a validator.Struct error.
+func ValidationError(c *fiber.Ctx, err error) error {
+	problem := NewProblem(domain.ErrValidation)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		problem.Detail = "one or more fields are invalid"
+		for _, fe := range validationErrors {
+			problem.Errors = append(problem.Errors, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fieldMessage(fe),
+			})
+		}
+	}
+	return write(c, problem)
+}
+
+func write(c *fiber.Ctx, problem Problem) error {
+	problem.Instance = c.Path()
+	if reqID, ok := c.Locals(ctxutil.RequestIDKey).(string); ok {
+		problem.RequestID = reqID
+	}
+	return c.Status(problem.Status).JSON(problem, MIMEApplicationProblemJSON)
+}
+
+// problemType turns INSUFFICIENT_STOCK into /problems/insufficient-stock.
+func problemType(code string) string {
+	return "/problems/" + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+// fieldPath drops the top-level struct name from the validator namespace,
+// e.g. WebhookCreateRequest.event_types[0] becomes event_types[0].
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "gt", "gtfield":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "min":
+		return fmt.Sprintf("must have at least %s characters or items", fe.Param())
+	case "nefield":
+		return fmt.Sprintf("must differ from %s", fe.Param())
+	case "url":
+		return "must be a valid URL"
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"warehouse-service/app/domain"
 	"warehouse-service/app/middleware"
 	"warehouse-service/config"
+	"warehouse-service/pkg"
+	"warehouse-service/pkg/metrics"
+	"warehouse-service/pkg/ratelimit"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 )
 
 func SetupRouter(app *fiber.App,
@@ -12,33 +17,133 @@ func SetupRouter(app *fiber.App,
 	stockHandler *StockHandler,
 	stockTransferHandler *StockTransferHandler,
 	reservedStockHandler *ReservedStockHandler,
-	cfg *config.Config) {
+	stockTransferScheduleHandler *StockTransferScheduleHandler,
+	replenishmentHandler *ReplenishmentHandler,
+	stockAlertHandler *StockAlertHandler,
+	snapshotHandler *SnapshotHandler,
+	webhookHandler *WebhookHandler,
+	stockStreamHandler *StockStreamHandler,
+	userRoleHandler *UserRoleHandler,
+	configHandler *ConfigHandler,
+	stocktakeHandler *StocktakeHandler,
+	userRoleUsecase domain.UserRoleUsecase,
+	jwtVerifier *pkg.JwtVerifier,
+	apiKeyUsecase domain.ApiKeyUsecase,
+	rateLimitStore ratelimit.Store,
+	concurrency *ratelimit.Concurrency,
+	live *config.Live) {
 
-	api := app.Group("/warehouse-service", middleware.Auth(cfg.Jwt.SecretKey))
-	internal := app.Group("/internal/warehouse-service", middleware.AuthInternal(cfg))
-	warehouseAdmin := app.Group("/admin/warehouse-service", middleware.AuthWarehouseAdmin(cfg))
+	// Settings read here need a restart, the rate limits below are read per
+	// request
+	cfg := live.Get()
+
+	// API contract
+	app.Get("/openapi.json", OpenAPISpec)
+	if cfg.OpenAPI.SwaggerUI {
+		app.Get("/docs", SwaggerUI)
+	}
+
+	if cfg.Metrics.Enabled {
+		app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+	}
+
+	apiHandlers := []fiber.Handler{middleware.Auth(jwtVerifier, userRoleUsecase)}
+	internalHandlers := []fiber.Handler{middleware.AuthInternal(cfg, apiKeyUsecase)}
+	warehouseAdminHandlers := []fiber.Handler{middleware.AuthWarehouseAdmin(cfg, apiKeyUsecase)}
+	if cfg.RateLimit.Enabled {
+		// Internal and admin routes share one budget per API key
+		internalRateLimit := middleware.RateLimit(rateLimitStore, concurrency, middleware.RateLimitConfig{
+			Group: "internal",
+			KeyBy: middleware.RateLimitKeyApiKey,
+			Limits: func() (ratelimit.Limit, int) {
+				rl := live.Get().RateLimit
+				return ratelimit.Limit{Rate: rl.InternalRate, Burst: rl.InternalBurst}, rl.InternalConcurrency
+			},
+		})
+		apiHandlers = append(apiHandlers, middleware.RateLimit(rateLimitStore, concurrency, middleware.RateLimitConfig{
+			Group: "public",
+			KeyBy: middleware.RateLimitKey(cfg.RateLimit.PublicKey),
+			Limits: func() (ratelimit.Limit, int) {
+				rl := live.Get().RateLimit
+				return ratelimit.Limit{Rate: rl.PublicRate, Burst: rl.PublicBurst}, rl.PublicConcurrency
+			},
+		}))
+		internalHandlers = append(internalHandlers, internalRateLimit)
+		warehouseAdminHandlers = append(warehouseAdminHandlers, internalRateLimit)
+	}
+
+	api := app.Group("/warehouse-service", apiHandlers...)
+	internal := app.Group("/internal/warehouse-service", internalHandlers...)
+	warehouseAdmin := app.Group("/admin/warehouse-service", warehouseAdminHandlers...)
 
 	// warehouses
-	api.Post("/warehouses", warehousHandler.Create)
-	api.Get("/shops/:shop_id/warehouses", warehousHandler.GetByShopID)
-	api.Patch("/warehouses/:id/status", warehousHandler.UpdateStatus)
+	api.Post("/warehouses", middleware.RequirePermission(domain.PermissionWarehouseManage), warehousHandler.Create)
+	api.Get("/shops/:shop_id/warehouses", middleware.RequirePermission(domain.PermissionWarehouseRead), warehousHandler.GetByShopID)
+	api.Patch("/warehouses/:id/status", middleware.RequirePermission(domain.PermissionWarehouseManage), warehousHandler.UpdateStatus)
 
 	// stocks
-	api.Get("/stocks", stockHandler.GetListStock)
-	api.Patch("/stocks/:id", stockHandler.UpdateQuantity)
+	api.Get("/stocks", middleware.RequirePermission(domain.PermissionStockRead), stockHandler.GetListStock)
+	api.Get("/stocks/stream", middleware.RequirePermission(domain.PermissionStockRead), stockStreamHandler.Stream)
+	api.Patch("/stocks/:id", middleware.RequirePermission(domain.PermissionStockAdjust), stockHandler.UpdateQuantity)
+	api.Post("/stocks/:id/adjustments", middleware.RequirePermission(domain.PermissionStockAdjust), stockHandler.AdjustQuantity)
+	api.Get("/stock-adjustments", middleware.RequirePermission(domain.PermissionStockRead), stockHandler.GetListStockAdjustment)
+	api.Get("/stock-adjustments/report", middleware.RequirePermission(domain.PermissionStockRead), stockHandler.GetAdjustmentReport)
+
+	// stocktakes
+	api.Post("/stocktakes", middleware.RequirePermission(domain.PermissionStocktakeCount), stocktakeHandler.Open)
+	api.Get("/stocktakes", middleware.RequirePermission(domain.PermissionStockRead), stocktakeHandler.GetListStocktake)
+	api.Get("/stocktakes/:id", middleware.RequirePermission(domain.PermissionStockRead), stocktakeHandler.GetByID)
+	api.Put("/stocktakes/:id/counts", middleware.RequirePermission(domain.PermissionStocktakeCount), stocktakeHandler.RecordCounts)
+	api.Post("/stocktakes/:id/submit", middleware.RequirePermission(domain.PermissionStocktakeCount), stocktakeHandler.Submit)
+	api.Post("/stocktakes/:id/approve", middleware.RequirePermission(domain.PermissionStockAdjust), stocktakeHandler.Approve)
+	api.Post("/stocktakes/:id/cancel", middleware.RequirePermission(domain.PermissionStockAdjust), stocktakeHandler.Cancel)
 
 	// internal stocks
-	internal.Post("/stocks", stockHandler.Create)
-	internal.Get("/products/:product_id/stocks", stockHandler.GetByProductID)
+	internal.Post("/stocks", middleware.RequireScope(domain.ApiKeyScopeInitStock), stockHandler.Create)
+	internal.Get("/products/:product_id/stocks", middleware.RequireScope(domain.ApiKeyScopeReserve, domain.ApiKeyScopeInitStock), stockHandler.GetByProductID)
 
 	// stock transfers
-	api.Post("/stock-transfers", stockTransferHandler.Create)
-	api.Get("/stock-transfers/:id", stockTransferHandler.GetByID)
-	api.Get("/stock-transfers", stockTransferHandler.GetListStockTransfer)
-	warehouseAdmin.Patch("/stock-transfers/:id", stockTransferHandler.UpdateStatus)
+	api.Post("/stock-transfers", middleware.RequirePermission(domain.PermissionTransferCreate), stockTransferHandler.Create)
+	api.Get("/stock-transfers/:id", middleware.RequirePermission(domain.PermissionTransferRead), stockTransferHandler.GetByID)
+	api.Get("/stock-transfers", middleware.RequirePermission(domain.PermissionTransferRead), stockTransferHandler.GetListStockTransfer)
+	warehouseAdmin.Patch("/stock-transfers/:id", middleware.RequireScope(domain.ApiKeyScopeTransferAdmin), stockTransferHandler.UpdateStatus)
+
+	// stock transfer schedules
+	api.Post("/stock-transfer-schedules", middleware.RequirePermission(domain.PermissionAutomationManage), stockTransferScheduleHandler.Create)
+	api.Get("/stock-transfer-schedules", middleware.RequirePermission(domain.PermissionAutomationRead), stockTransferScheduleHandler.GetListSchedule)
+	api.Get("/stock-transfer-schedules/:id/runs", middleware.RequirePermission(domain.PermissionAutomationRead), stockTransferScheduleHandler.GetRuns)
+	api.Delete("/stock-transfer-schedules/:id", middleware.RequirePermission(domain.PermissionAutomationManage), stockTransferScheduleHandler.Cancel)
+
+	// replenishment rules
+	api.Put("/replenishment-rules", middleware.RequirePermission(domain.PermissionAutomationManage), replenishmentHandler.UpsertRule)
+	api.Get("/replenishment-rules", middleware.RequirePermission(domain.PermissionAutomationRead), replenishmentHandler.GetListRule)
+	api.Delete("/replenishment-rules/:id", middleware.RequirePermission(domain.PermissionAutomationManage), replenishmentHandler.DeleteRule)
+
+	// stock alert thresholds
+	api.Put("/stock-alert-thresholds", middleware.RequirePermission(domain.PermissionAutomationManage), stockAlertHandler.UpsertThreshold)
+	api.Get("/stock-alert-thresholds", middleware.RequirePermission(domain.PermissionAutomationRead), stockAlertHandler.GetThresholds)
+	api.Delete("/stock-alert-thresholds/:id", middleware.RequirePermission(domain.PermissionAutomationManage), stockAlertHandler.DeleteThreshold)
+
+	// webhooks
+	api.Post("/webhooks", middleware.RequirePermission(domain.PermissionWebhookManage), webhookHandler.Create)
+	api.Get("/webhooks", middleware.RequirePermission(domain.PermissionWebhookManage), webhookHandler.GetByShopID)
+	api.Delete("/webhooks/:id", middleware.RequirePermission(domain.PermissionWebhookManage), webhookHandler.Delete)
+	api.Get("/webhooks/:id/deliveries", middleware.RequirePermission(domain.PermissionWebhookManage), webhookHandler.GetListDelivery)
+	api.Post("/webhooks/:id/deliveries/:delivery_id/redeliver", middleware.RequirePermission(domain.PermissionWebhookManage), webhookHandler.Redeliver)
+
+	// shop user roles
+	api.Put("/user-roles", middleware.RequirePermission(domain.PermissionRoleManage), userRoleHandler.Upsert)
+	api.Get("/user-roles", middleware.RequirePermission(domain.PermissionRoleManage), userRoleHandler.GetByShopID)
+	api.Delete("/user-roles/:user_id", middleware.RequirePermission(domain.PermissionRoleManage), userRoleHandler.Delete)
+
+	// runtime config
+	warehouseAdmin.Get("/config", middleware.RequireScope(domain.ApiKeyScopeConfig), configHandler.Get)
+
+	// availability snapshots
+	warehouseAdmin.Post("/snapshots/availability", middleware.RequireScope(domain.ApiKeyScopeSnapshot), snapshotHandler.PublishAvailabilitySnapshot)
 
 	// reserved stocks
-	internal.Post("/reserved-stocks", reservedStockHandler.CreateReservedStock)
-	internal.Patch("/orders/:order_id/reserved-stocks/status", reservedStockHandler.UpdateReservedStockStatus)
+	internal.Post("/reserved-stocks", middleware.RequireScope(domain.ApiKeyScopeReserve), reservedStockHandler.CreateReservedStock)
+	internal.Patch("/orders/:order_id/reserved-stocks/status", middleware.RequireScope(domain.ApiKeyScopeReserve), reservedStockHandler.UpdateReservedStockStatus)
 
 }
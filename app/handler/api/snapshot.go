@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"log/slog"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SnapshotHandler struct {
+	snapshotUsecase domain.SnapshotUsecase
+	validator       *validator.Validate
+}
+
+func NewSnapshotHandler(snapshotUsecase domain.SnapshotUsecase, validator *validator.Validate) *SnapshotHandler {
+	return &SnapshotHandler{snapshotUsecase, validator}
+}
+
+func (h *SnapshotHandler) PublishAvailabilitySnapshot(c *fiber.Ctx) error {
+	var req domain.AvailabilitySnapshotRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[snapshotHandler] PublishAvailabilitySnapshot", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[snapshotHandler] PublishAvailabilitySnapshot", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	result, err := h.snapshotUsecase.PublishAvailabilitySnapshot(c.UserContext(), req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[snapshotHandler] PublishAvailabilitySnapshot", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(result))
+}
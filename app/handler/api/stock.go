@@ -27,20 +27,19 @@ func NewStockHandler(stockUsecase domain.StockService, validator *validator.Vali
 func (h *StockHandler) Create(c *fiber.Ctx) error {
 	var req domain.StockCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] Create", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] Create", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] Create", "validation", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrValidation))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] Create", "validation", err)
+		return response.ValidationError(c, err)
 	}
 
-	stock, err := h.stockUsecase.InitStock(c.Context(), req)
+	stock, err := h.stockUsecase.InitStock(c.UserContext(), req)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] Create", "usecase", err)
-		status, resp := response.FromError(err)
-		return c.Status(status).JSON(resp)
+		slog.ErrorContext(c.UserContext(), "[stockHandler] Create", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(response.Success(stock))
@@ -49,24 +48,23 @@ func (h *StockHandler) Create(c *fiber.Ctx) error {
 func (h *StockHandler) GetByProductID(c *fiber.Ctx) error {
 	productIDStr := c.Params("product_id")
 	if productIDStr == "" {
-		slog.ErrorContext(c.Context(), "[stockHandler] GetByProductID", "productID", "missing")
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetByProductID", "productID", "missing")
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	productID, err := strconv.ParseInt(productIDStr, 10, 64)
 	if err != nil || productID <= 0 {
-		slog.ErrorContext(c.Context(), "[stockHandler] GetByProductID", "parseInt:"+productIDStr, err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetByProductID", "parseInt:"+productIDStr, err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
-	stocks, err := h.stockUsecase.GetAvailableStockByProductID(c.Context(), productID)
+	stocks, err := h.stockUsecase.GetAvailableStockByProductID(c.UserContext(), productID)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] GetByProductID", "usecase", err)
-		status, resp := response.FromError(err)
-		return c.Status(status).JSON(resp)
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetByProductID", "usecase", err)
+		return response.Error(c, err)
 	}
 
-	slog.InfoContext(c.Context(), "[stockHandler] GetByProductID", "stocks", stocks)
+	slog.InfoContext(c.UserContext(), "[stockHandler] GetByProductID", "stocks", stocks)
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(stocks))
 }
@@ -74,48 +72,53 @@ func (h *StockHandler) GetByProductID(c *fiber.Ctx) error {
 func (h *StockHandler) UpdateQuantity(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	if idStr == "" {
-		slog.ErrorContext(c.Context(), "[stockHandler] UpdateQuantity", "id", "missing")
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] UpdateQuantity", "id", "missing")
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		slog.ErrorContext(c.Context(), "[stockHandler] UpdateQuantity", "parseInt:"+idStr, err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] UpdateQuantity", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	var req domain.UpdateQuantityRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] UpdateQuantity", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] UpdateQuantity", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
-	shopID, err := ctxutil.GetShopIDCtx(c.Context())
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] UpdateQuantity", "getShopIDCtx", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(domain.ErrInternal))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] UpdateQuantity", "getShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
 	}
 
-	err = h.stockUsecase.UpdateQuantity(c.Context(), id, shopID, req)
+	userID, err := ctxutil.GetUserIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] UpdateQuantity", "usecase", err)
-		status, resp := response.FromError(err)
-		return c.Status(status).JSON(resp)
+		slog.ErrorContext(c.UserContext(), "[stockHandler] UpdateQuantity", "getUserIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	err = h.stockUsecase.UpdateQuantity(c.UserContext(), id, shopID, userID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] UpdateQuantity", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
 }
 
 func (h *StockHandler) GetListStock(c *fiber.Ctx) error {
-	shopID, err := ctxutil.GetShopIDCtx(c.Context())
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] GetListStock", "getShopIDCtx", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(domain.ErrInternal))
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetListStock", "getShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
 	}
 
 	param := domain.GetListStockRequest{}
 	if err := c.QueryParser(&param); err != nil {
-		slog.WarnContext(c.Context(), "[stockHandler] GetListStock", "queryParser", err)
+		slog.WarnContext(c.UserContext(), "[stockHandler] GetListStock", "queryParser", err)
 	}
 
 	if param.Page <= 0 {
@@ -134,12 +137,113 @@ func (h *StockHandler) GetListStock(c *fiber.Ctx) error {
 		param.SortOrder = "desc"
 	}
 
-	stocks, metadata, err := h.stockUsecase.GetListStock(c.Context(), shopID, param)
+	stocks, metadata, err := h.stockUsecase.GetListStock(c.UserContext(), shopID, param)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockHandler] GetListStock", "usecase", err)
-		status, resp := response.FromError(err)
-		return c.Status(status).JSON(resp)
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetListStock", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.SuccessWithMetadata(stocks, metadata))
 }
+
+func (h *StockHandler) AdjustQuantity(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] AdjustQuantity", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	var req domain.StockAdjustRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] AdjustQuantity", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] AdjustQuantity", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] AdjustQuantity", "getShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	userID, err := ctxutil.GetUserIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] AdjustQuantity", "getUserIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	adjustment, err := h.stockUsecase.AdjustQuantity(c.UserContext(), id, shopID, userID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] AdjustQuantity", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(adjustment))
+}
+
+func (h *StockHandler) GetListStockAdjustment(c *fiber.Ctx) error {
+	var param domain.GetListStockAdjustmentRequest
+	if err := c.QueryParser(&param); err != nil {
+		slog.WarnContext(c.UserContext(), "[stockHandler] GetListStockAdjustment", "queryParser", err)
+	}
+
+	if err := h.validator.Struct(param); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetListStockAdjustment", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetListStockAdjustment", "getShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.Limit <= 0 {
+		param.Limit = 10
+	}
+	if param.Limit > 20 {
+		param.Limit = 20
+	}
+
+	adjustments, metadata, err := h.stockUsecase.GetListStockAdjustment(c.UserContext(), shopID, param)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetListStockAdjustment", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithMetadata(adjustments, metadata))
+}
+
+func (h *StockHandler) GetAdjustmentReport(c *fiber.Ctx) error {
+	var param domain.StockAdjustmentReportRequest
+	if err := c.QueryParser(&param); err != nil {
+		slog.WarnContext(c.UserContext(), "[stockHandler] GetAdjustmentReport", "queryParser", err)
+	}
+
+	if err := h.validator.Struct(param); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetAdjustmentReport", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetAdjustmentReport", "getShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	totals, err := h.stockUsecase.GetAdjustmentReport(c.UserContext(), shopID, param)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockHandler] GetAdjustmentReport", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(totals))
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"log/slog"
+	"strconv"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockAlertHandler struct {
+	stockAlertUsecase domain.StockAlertUsecase
+	validator         *validator.Validate
+}
+
+func NewStockAlertHandler(stockAlertUsecase domain.StockAlertUsecase, validator *validator.Validate) *StockAlertHandler {
+	return &StockAlertHandler{stockAlertUsecase, validator}
+}
+
+func (h *StockAlertHandler) UpsertThreshold(c *fiber.Ctx) error {
+	var req domain.StockAlertThresholdUpsertRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] UpsertThreshold", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] UpsertThreshold", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] UpsertThreshold", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	threshold, err := h.stockAlertUsecase.UpsertThreshold(c.UserContext(), shopID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] UpsertThreshold", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(threshold))
+}
+
+func (h *StockAlertHandler) GetThresholds(c *fiber.Ctx) error {
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] GetThresholds", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	thresholds, err := h.stockAlertUsecase.GetThresholds(c.UserContext(), shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] GetThresholds", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(thresholds))
+}
+
+func (h *StockAlertHandler) DeleteThreshold(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] DeleteThreshold", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] DeleteThreshold", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	err = h.stockAlertUsecase.DeleteThreshold(c.UserContext(), id, shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockAlertHandler] DeleteThreshold", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
+}
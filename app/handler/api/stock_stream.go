@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockStreamHandler struct {
+	stockStreamUsecase domain.StockStreamUsecase
+	heartbeat          time.Duration
+}
+
+func NewStockStreamHandler(stockStreamUsecase domain.StockStreamUsecase, heartbeat time.Duration) *StockStreamHandler {
+	return &StockStreamHandler{stockStreamUsecase, heartbeat}
+}
+
+func (h *StockStreamHandler) Stream(c *fiber.Ctx) error {
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockStreamHandler] Stream", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	// Browsers resend Last-Event-ID on reconnect; the query parameter lets
+	// clients resume after a full page reload.
+	lastEventIDStr := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	var lastEventID int64
+	if lastEventIDStr != "" {
+		lastEventID, err = strconv.ParseInt(lastEventIDStr, 10, 64)
+		if err != nil || lastEventID < 0 {
+			slog.ErrorContext(c.UserContext(), "[stockStreamHandler] Stream", "parseInt:"+lastEventIDStr, err)
+			return response.Error(c, domain.ErrBadRequest)
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		sub := h.stockStreamUsecase.Subscribe(shopID, lastEventID)
+		defer sub.Cancel()
+
+		fmt.Fprintf(w, "retry: %d\n\n", time.Second.Milliseconds()*3)
+		for _, event := range sub.Backlog {
+			if err := writeStockStreamEvent(w, event); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(h.heartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				if err := writeStockStreamEvent(w, event); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+
+			// A failed flush means the client went away.
+			if err := w.Flush(); err != nil {
+				slog.Info("[stockStreamHandler] Stream", "disconnected", shopID)
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeStockStreamEvent(w *bufio.Writer, event domain.StockStreamEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		slog.Error("[stockStreamHandler] writeStockStreamEvent", "marshal", err)
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -24,26 +24,25 @@ func NewStockTransferHandler(stockTransferUsecase domain.StockTransferUsecase, v
 func (h *StockTransferHandler) Create(c *fiber.Ctx) error {
 	var req domain.StockTransferCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] Create", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] Create", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] Create", "validation", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrValidation))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] Create", "validation", err)
+		return response.ValidationError(c, err)
 	}
 
-	shopID, err := ctxutil.GetShopIDCtx(c.Context())
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] Create", "GetShopIDCtx", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(domain.ErrInternal))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] Create", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
 	}
 
-	stockTransfer, err := h.stockTransferUsecase.CreateTransfer(c.Context(), shopID, req)
+	stockTransfer, err := h.stockTransferUsecase.CreateTransfer(c.UserContext(), shopID, req)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] Create", "usecase", err)
-		status, response := response.FromError(err)
-		return c.Status(status).JSON(response)
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] Create", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(response.Success(stockTransfer))
@@ -52,20 +51,20 @@ func (h *StockTransferHandler) Create(c *fiber.Ctx) error {
 func (h *StockTransferHandler) GetByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	if idStr == "" {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] GetByID", "id", "missing")
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] GetByID", "id", "missing")
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] GetByID", "parseInt:"+idStr, err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] GetByID", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
-	shopIDCtx, err := ctxutil.GetShopIDCtx(c.Context())
+	shopIDCtx, err := ctxutil.GetShopIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] GetByID", "GetShopIDCtx", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(domain.ErrInternal))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] GetByID", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
 	}
 
 	var shopID *int64
@@ -73,11 +72,10 @@ func (h *StockTransferHandler) GetByID(c *fiber.Ctx) error {
 		shopID = &shopIDCtx
 	}
 
-	stockTransfer, err := h.stockTransferUsecase.GetTransferByID(c.Context(), id, shopID)
+	stockTransfer, err := h.stockTransferUsecase.GetTransferByID(c.UserContext(), id, shopID)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] GetByID", "usecase", err)
-		status, response := response.FromError(err)
-		return c.Status(status).JSON(response)
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] GetByID", "usecase", err)
+		return response.Error(c, err)
 	}
 	return c.Status(fiber.StatusOK).JSON(response.Success(stockTransfer))
 }
@@ -85,32 +83,31 @@ func (h *StockTransferHandler) GetByID(c *fiber.Ctx) error {
 func (h *StockTransferHandler) UpdateStatus(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	if idStr == "" {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] UpdateStatus", "id", "missing")
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] UpdateStatus", "id", "missing")
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] UpdateStatus", "parseInt:"+idStr, err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] UpdateStatus", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	var req domain.StockTransferUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] UpdateStatus", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] UpdateStatus", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] UpdateStatus", "validation", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrValidation))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] UpdateStatus", "validation", err)
+		return response.ValidationError(c, err)
 	}
 
-	err = h.stockTransferUsecase.UpdateTransferStatus(c.Context(), id, req)
+	err = h.stockTransferUsecase.UpdateTransferStatus(c.UserContext(), id, req)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] UpdateStatus", "usecase", err)
-		status, response := response.FromError(err)
-		return c.Status(status).JSON(response)
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] UpdateStatus", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
@@ -119,13 +116,13 @@ func (h *StockTransferHandler) UpdateStatus(c *fiber.Ctx) error {
 func (h *StockTransferHandler) GetListStockTransfer(c *fiber.Ctx) error {
 	var param domain.GetListStockTransferRequest
 	if err := c.QueryParser(&param); err != nil {
-		slog.WarnContext(c.Context(), "[stockTransferHandler] GetListStockTransfer", "queryParser", err)
+		slog.WarnContext(c.UserContext(), "[stockTransferHandler] GetListStockTransfer", "queryParser", err)
 	}
 
-	shopID, err := ctxutil.GetShopIDCtx(c.Context())
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] GetListStockTransfer", "GetShopIDCtx", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(domain.ErrInternal))
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] GetListStockTransfer", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
 	}
 
 	if param.Page <= 0 {
@@ -151,11 +148,10 @@ func (h *StockTransferHandler) GetListStockTransfer(c *fiber.Ctx) error {
 		param.Status = ""
 	}
 
-	stockTransfers, metadata, err := h.stockTransferUsecase.GetListStockTransfer(c.Context(), shopID, param)
+	stockTransfers, metadata, err := h.stockTransferUsecase.GetListStockTransfer(c.UserContext(), shopID, param)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[stockTransferHandler] GetListStockTransfer", "usecase", err)
-		status, response := response.FromError(err)
-		return c.Status(status).JSON(response)
+		slog.ErrorContext(c.UserContext(), "[stockTransferHandler] GetListStockTransfer", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.SuccessWithMetadata(stockTransfers, metadata))
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"log/slog"
+	"strconv"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockTransferScheduleHandler struct {
+	scheduleUsecase domain.StockTransferScheduleUsecase
+	validator       *validator.Validate
+}
+
+func NewStockTransferScheduleHandler(scheduleUsecase domain.StockTransferScheduleUsecase, validator *validator.Validate) *StockTransferScheduleHandler {
+	return &StockTransferScheduleHandler{scheduleUsecase, validator}
+}
+
+func (h *StockTransferScheduleHandler) Create(c *fiber.Ctx) error {
+	var req domain.StockTransferScheduleCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] Create", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] Create", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] Create", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	schedule, err := h.scheduleUsecase.CreateSchedule(c.UserContext(), shopID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] Create", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(schedule))
+}
+
+func (h *StockTransferScheduleHandler) GetListSchedule(c *fiber.Ctx) error {
+	var param domain.GetListStockTransferScheduleRequest
+	if err := c.QueryParser(&param); err != nil {
+		slog.WarnContext(c.UserContext(), "[stockTransferScheduleHandler] GetListSchedule", "queryParser", err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] GetListSchedule", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.Limit <= 0 {
+		param.Limit = 10
+	}
+	if param.Limit > 20 {
+		param.Limit = 20
+	}
+	if param.SortBy == "" || (param.SortBy != "scheduled_at" && param.SortBy != "created_at" && param.SortBy != "id") {
+		param.SortBy = "scheduled_at"
+	}
+	if param.SortOrder == "" || (param.SortOrder != "asc" && param.SortOrder != "desc") {
+		param.SortOrder = "asc"
+	}
+
+	schedules, metadata, err := h.scheduleUsecase.GetListSchedule(c.UserContext(), shopID, param)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] GetListSchedule", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithMetadata(schedules, metadata))
+}
+
+func (h *StockTransferScheduleHandler) GetRuns(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] GetRuns", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] GetRuns", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	runs, err := h.scheduleUsecase.GetRuns(c.UserContext(), id, shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] GetRuns", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(runs))
+}
+
+func (h *StockTransferScheduleHandler) Cancel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] Cancel", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] Cancel", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	err = h.scheduleUsecase.CancelSchedule(c.UserContext(), id, shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stockTransferScheduleHandler] Cancel", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
+}
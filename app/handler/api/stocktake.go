@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type StocktakeHandler struct {
+	stocktakeUsecase domain.StocktakeUsecase
+	validator        *validator.Validate
+}
+
+func NewStocktakeHandler(stocktakeUsecase domain.StocktakeUsecase, validator *validator.Validate) *StocktakeHandler {
+	return &StocktakeHandler{stocktakeUsecase, validator}
+}
+
+func (h *StocktakeHandler) Open(c *fiber.Ctx) error {
+	var req domain.StocktakeCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] Open", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] Open", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] Open", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	userID, err := ctxutil.GetUserIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] Open", "GetUserIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	stocktake, err := h.stocktakeUsecase.Open(c.UserContext(), shopID, userID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] Open", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(stocktake))
+}
+
+func (h *StocktakeHandler) GetByID(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] GetByID", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] GetByID", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	stocktake, err := h.stocktakeUsecase.GetByID(c.UserContext(), id, shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] GetByID", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(stocktake))
+}
+
+func (h *StocktakeHandler) GetListStocktake(c *fiber.Ctx) error {
+	var param domain.GetListStocktakeRequest
+	if err := c.QueryParser(&param); err != nil {
+		slog.WarnContext(c.UserContext(), "[stocktakeHandler] GetListStocktake", "queryParser", err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] GetListStocktake", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.Limit <= 0 {
+		param.Limit = 10
+	}
+	if param.Limit > 20 {
+		param.Limit = 20
+	}
+
+	stocktakes, metadata, err := h.stocktakeUsecase.GetListStocktake(c.UserContext(), shopID, param)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] GetListStocktake", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithMetadata(stocktakes, metadata))
+}
+
+func (h *StocktakeHandler) RecordCounts(c *fiber.Ctx) error {
+	var req domain.StocktakeCountRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] RecordCounts", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] RecordCounts", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	return h.change(c, "RecordCounts", func(ctx context.Context, id, shopID, userID int64) (domain.Stocktake, error) {
+		return h.stocktakeUsecase.RecordCounts(ctx, id, shopID, userID, req)
+	})
+}
+
+func (h *StocktakeHandler) Submit(c *fiber.Ctx) error {
+	return h.change(c, "Submit", h.stocktakeUsecase.Submit)
+}
+
+func (h *StocktakeHandler) Approve(c *fiber.Ctx) error {
+	return h.change(c, "Approve", h.stocktakeUsecase.Approve)
+}
+
+func (h *StocktakeHandler) Cancel(c *fiber.Ctx) error {
+	return h.change(c, "Cancel", h.stocktakeUsecase.Cancel)
+}
+
+// change runs a state change of the stocktake in the :id param on behalf of
+// the calling user and responds with the updated stocktake.
+func (h *StocktakeHandler) change(c *fiber.Ctx, method string, fn func(ctx context.Context, id, shopID, userID int64) (domain.Stocktake, error)) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] "+method, "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] "+method, "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	userID, err := ctxutil.GetUserIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] "+method, "GetUserIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	stocktake, err := fn(c.UserContext(), id, shopID, userID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[stocktakeHandler] "+method, "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(stocktake))
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"log/slog"
+	"strconv"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserRoleHandler struct {
+	userRoleUsecase domain.UserRoleUsecase
+	validator       *validator.Validate
+}
+
+func NewUserRoleHandler(userRoleUsecase domain.UserRoleUsecase, validator *validator.Validate) *UserRoleHandler {
+	return &UserRoleHandler{userRoleUsecase, validator}
+}
+
+func (h *UserRoleHandler) Upsert(c *fiber.Ctx) error {
+	var req domain.UserRoleUpsertRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Upsert", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Upsert", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Upsert", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	userID, err := ctxutil.GetUserIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Upsert", "GetUserIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	userRole, err := h.userRoleUsecase.Upsert(c.UserContext(), shopID, userID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Upsert", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(userRole))
+}
+
+func (h *UserRoleHandler) GetByShopID(c *fiber.Ctx) error {
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] GetByShopID", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	userRoles, err := h.userRoleUsecase.GetByShopID(c.UserContext(), shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] GetByShopID", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(userRoles))
+}
+
+func (h *UserRoleHandler) Delete(c *fiber.Ctx) error {
+	userIDStr := c.Params("user_id")
+	targetUserID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil || targetUserID <= 0 {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Delete", "parseInt:"+userIDStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Delete", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	userID, err := ctxutil.GetUserIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Delete", "GetUserIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	if err := h.userRoleUsecase.Delete(c.UserContext(), shopID, userID, targetUserID); err != nil {
+		slog.ErrorContext(c.UserContext(), "[userRoleHandler] Delete", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
+}
@@ -23,26 +23,25 @@ func NewWarehouseHandler(warehouseUsecase domain.WarehouseService, validator *va
 func (h *WarehouseHandler) Create(c *fiber.Ctx) error {
 	var req domain.WarehouseCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] Create", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] Create", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] Create", "validation", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrValidation))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] Create", "validation", err)
+		return response.ValidationError(c, err)
 	}
 
-	shopID, err := ctxutil.GetShopIDCtx(c.Context())
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] Create", "GetShopIDCtx", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(domain.ErrInternal))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] Create", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
 	}
 
-	warehouse, err := h.warehouseUsecase.Create(c.Context(), shopID, &req)
+	warehouse, err := h.warehouseUsecase.Create(c.UserContext(), shopID, &req)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] Create", "usecase", err)
-		status, response := response.FromError(err)
-		return c.Status(status).JSON(response)
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] Create", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(response.Success(warehouse))
@@ -51,21 +50,20 @@ func (h *WarehouseHandler) Create(c *fiber.Ctx) error {
 func (h *WarehouseHandler) GetByShopID(c *fiber.Ctx) error {
 	shopIDStr := c.Params("shop_id")
 	if shopIDStr == "" {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] GetByShopID", "shopID", "missing")
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] GetByShopID", "shopID", "missing")
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	shopID, err := strconv.ParseInt(shopIDStr, 10, 64)
 	if err != nil || shopID <= 0 {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] GetByShopID", "parseInt:"+shopIDStr, err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] GetByShopID", "parseInt:"+shopIDStr, err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
-	warehouses, err := h.warehouseUsecase.GetByShopID(c.Context(), shopID)
+	warehouses, err := h.warehouseUsecase.GetByShopID(c.UserContext(), shopID)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] GetByShopID", "usecase", err)
-		status, response := response.FromError(err)
-		return c.Status(status).JSON(response)
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] GetByShopID", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(warehouses))
@@ -74,33 +72,32 @@ func (h *WarehouseHandler) GetByShopID(c *fiber.Ctx) error {
 func (h *WarehouseHandler) UpdateStatus(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	if idStr == "" {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] UpdateStatus", "id", "missing")
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] UpdateStatus", "id", "missing")
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] UpdateStatus", "parseInt:"+idStr, err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] UpdateStatus", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
 	var req domain.WarehouseUpdateStatusRequest
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] UpdateStatus", "bodyParser", err)
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(domain.ErrBadRequest))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] UpdateStatus", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
 	}
 
-	shopID, err := ctxutil.GetShopIDCtx(c.Context())
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] UpdateStatus", "GetShopIDCtx", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(domain.ErrInternal))
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] UpdateStatus", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
 	}
 
-	err = h.warehouseUsecase.UpdateStatus(c.Context(), id, shopID, req)
+	err = h.warehouseUsecase.UpdateStatus(c.UserContext(), id, shopID, req)
 	if err != nil {
-		slog.ErrorContext(c.Context(), "[warehouseHandler] UpdateStatus", "usecase", err)
-		status, response := response.FromError(err)
-		return c.Status(status).JSON(response)
+		slog.ErrorContext(c.UserContext(), "[warehouseHandler] UpdateStatus", "usecase", err)
+		return response.Error(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
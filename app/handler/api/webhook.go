@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"log/slog"
+	"strconv"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookUsecase domain.WebhookUsecase
+	validator      *validator.Validate
+}
+
+func NewWebhookHandler(webhookUsecase domain.WebhookUsecase, validator *validator.Validate) *WebhookHandler {
+	return &WebhookHandler{webhookUsecase, validator}
+}
+
+func (h *WebhookHandler) Create(c *fiber.Ctx) error {
+	var req domain.WebhookCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Create", "bodyParser", err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Create", "validation", err)
+		return response.ValidationError(c, err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Create", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	webhook, err := h.webhookUsecase.Create(c.UserContext(), shopID, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Create", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(webhook))
+}
+
+func (h *WebhookHandler) GetByShopID(c *fiber.Ctx) error {
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] GetByShopID", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	webhooks, err := h.webhookUsecase.GetByShopID(c.UserContext(), shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] GetByShopID", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(webhooks))
+}
+
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Delete", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Delete", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	err = h.webhookUsecase.Delete(c.UserContext(), id, shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Delete", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil))
+}
+
+func (h *WebhookHandler) GetListDelivery(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] GetListDelivery", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	var param domain.GetListWebhookDeliveryRequest
+	if err := c.QueryParser(&param); err != nil {
+		slog.WarnContext(c.UserContext(), "[webhookHandler] GetListDelivery", "queryParser", err)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] GetListDelivery", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.Limit <= 0 {
+		param.Limit = 10
+	}
+	if param.Limit > 50 {
+		param.Limit = 50
+	}
+	if param.Status != string(domain.WebhookDeliveryStatusPending) &&
+		param.Status != string(domain.WebhookDeliveryStatusSucceeded) &&
+		param.Status != string(domain.WebhookDeliveryStatusFailed) {
+		param.Status = ""
+	}
+
+	deliveries, metadata, err := h.webhookUsecase.GetListDelivery(c.UserContext(), id, shopID, param)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] GetListDelivery", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithMetadata(deliveries, metadata))
+}
+
+func (h *WebhookHandler) Redeliver(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Redeliver", "parseInt:"+idStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	deliveryIDStr := c.Params("delivery_id")
+	deliveryID, err := strconv.ParseInt(deliveryIDStr, 10, 64)
+	if err != nil || deliveryID <= 0 {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Redeliver", "parseInt:"+deliveryIDStr, err)
+		return response.Error(c, domain.ErrBadRequest)
+	}
+
+	shopID, err := ctxutil.GetShopIDCtx(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Redeliver", "GetShopIDCtx", err)
+		return response.Error(c, domain.ErrInternal)
+	}
+
+	err = h.webhookUsecase.Redeliver(c.UserContext(), id, deliveryID, shopID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "[webhookHandler] Redeliver", "usecase", err)
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(response.Success(nil))
+}
@@ -0,0 +1,52 @@
+package grpchandler
+
+import (
+	"errors"
+	"warehouse-service/app/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusError mirrors response.NewProblem for gRPC callers. Coded domain
+// errors carry their code as the ErrorInfo reason.
+func toStatusError(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		code = codes.InvalidArgument
+	case errors.Is(err, domain.ErrInvalidRequest):
+		code = codes.InvalidArgument
+	case errors.Is(err, domain.ErrBadRequest):
+		code = codes.InvalidArgument
+	case errors.Is(err, domain.ErrUnauthorized):
+		code = codes.PermissionDenied
+	case errors.Is(err, domain.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, domain.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, domain.ErrConflict):
+		code = codes.FailedPrecondition
+	case errors.Is(err, domain.ErrVersionMismatch):
+		code = codes.Aborted
+	case errors.Is(err, domain.ErrTooManyRequests):
+		code = codes.ResourceExhausted
+	default:
+		return status.Error(codes.Internal, domain.ErrInternal.Error())
+	}
+
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		return status.Error(code, err.Error())
+	}
+
+	st, detailErr := status.New(code, domainErr.Detail).WithDetails(&errdetails.ErrorInfo{
+		Reason: domainErr.Code,
+		Domain: "warehouse-service",
+	})
+	if detailErr != nil {
+		return status.Error(code, domainErr.Detail)
+	}
+	return st.Err()
+}
@@ -0,0 +1,180 @@
+package grpchandler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+	"warehouse-service/pkg/ratelimit"
+	warehousev1 "warehouse-service/proto/warehouse/v1"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIDMetadataKey    = "x-request-id"
+	authInternalMetadataKey = "x-internal-auth"
+)
+
+// RequestIDInterceptor is the gRPC equivalent of middleware.RequestIDMiddleware.
+func RequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		reqID := metadataValue(ctx, requestIDMetadataKey)
+		if reqID == "" {
+			uuidV4, err := uuid.NewV4()
+			if err != nil {
+				slog.WarnContext(ctx, "[RequestIDInterceptor] Error generating UUID", "error", err)
+			}
+			reqID = uuidV4.String()
+		}
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, reqID)); err != nil {
+			slog.WarnContext(ctx, "[RequestIDInterceptor] SetHeader", "error", err)
+		}
+		return handler(ctxutil.WithRequestID(ctx, reqID), req)
+	}
+}
+
+func LoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		slog.InfoContext(ctx, "[grpc] request",
+			"method", info.FullMethod,
+			"code", status.Code(err).String(),
+			"latency", time.Since(start).String(),
+		)
+		return resp, err
+	}
+}
+
+func RecoverInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.ErrorContext(ctx, "[RecoverInterceptor] panic", "method", info.FullMethod, "panic", r, "stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, domain.ErrInternal.Error())
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// methodScopes lists the API key scopes accepted by each RPC, any one of
+// them is enough.
+var methodScopes = map[string][]domain.ApiKeyScope{
+	warehousev1.WarehouseService_InitStock_FullMethodName:               {domain.ApiKeyScopeInitStock},
+	warehousev1.WarehouseService_GetAvailability_FullMethodName:         {domain.ApiKeyScopeReserve, domain.ApiKeyScopeInitStock},
+	warehousev1.WarehouseService_Reserve_FullMethodName:                 {domain.ApiKeyScopeReserve},
+	warehousev1.WarehouseService_UpdateReservationStatus_FullMethodName: {domain.ApiKeyScopeReserve},
+	warehousev1.WarehouseService_CreateTransfer_FullMethodName:          {domain.ApiKeyScopeTransferAdmin},
+	warehousev1.WarehouseService_GetTransfer_FullMethodName:             {domain.ApiKeyScopeTransferAdmin},
+	warehousev1.WarehouseService_UpdateTransferStatus_FullMethodName:    {domain.ApiKeyScopeTransferAdmin},
+}
+
+// legacyScopes are granted to the static internal secret, the same as
+// middleware.AuthInternal does, so it can't reach the transfer RPCs.
+var legacyScopes = []domain.ApiKeyScope{domain.ApiKeyScopeReserve, domain.ApiKeyScopeInitStock}
+
+// AuthInternalInterceptor is the gRPC equivalent of middleware.AuthInternal
+// and middleware.RequireScope, with the key sent as `x-internal-auth`
+// metadata.
+func AuthInternalInterceptor(internalAuthHeader string, apiKeyUsecase domain.ApiKeyUsecase) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		authHeader := metadataValue(ctx, authInternalMetadataKey)
+		if authHeader == "" {
+			slog.ErrorContext(ctx, "[AuthInternalInterceptor]", "method", info.FullMethod, "error", domain.ErrUnauthorized)
+			return nil, status.Error(codes.Unauthenticated, domain.ErrUnauthorized.Error())
+		}
+
+		if internalAuthHeader != "" && subtle.ConstantTimeCompare([]byte(authHeader), []byte(internalAuthHeader)) == 1 {
+			slog.WarnContext(ctx, "[AuthInternalInterceptor]", "method", info.FullMethod, "legacySecret", true)
+			if !hasMethodScope(info.FullMethod, legacyScopes) {
+				return nil, toStatusError(domain.ErrApiKeyScopeMissing)
+			}
+			return handler(ctx, req)
+		}
+
+		apiKey, err := apiKeyUsecase.Authenticate(ctx, authHeader)
+		if errors.Is(err, domain.ErrUnauthorized) {
+			slog.ErrorContext(ctx, "[AuthInternalInterceptor]", "method", info.FullMethod, "error", err)
+			return nil, status.Error(codes.Unauthenticated, domain.ErrUnauthorized.Error())
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "[AuthInternalInterceptor]", "method", info.FullMethod, "error", err)
+			return nil, toStatusError(err)
+		}
+
+		if hasMethodScope(info.FullMethod, apiKey.Scopes) {
+			return handler(context.WithValue(ctx, ctxutil.ApiKeyIDKey, apiKey.ID), req)
+		}
+		slog.WarnContext(ctx, "[AuthInternalInterceptor]", "method", info.FullMethod, "apiKeyID", apiKey.ID, "scopes", apiKey.Scopes)
+		return nil, toStatusError(domain.ErrApiKeyScopeMissing)
+	}
+}
+
+// hasMethodScope reports whether granted holds one of the method's scopes.
+// Methods missing from methodScopes are denied.
+func hasMethodScope(method string, granted []domain.ApiKeyScope) bool {
+	return domain.HasAnyScope(granted, methodScopes[method]...)
+}
+
+// RateLimitInterceptor is the gRPC equivalent of middleware.RateLimit. It
+// runs after AuthInternalInterceptor and draws from the same per API key
+// budget as the internal HTTP routes.
+func RateLimitInterceptor(store ratelimit.Store, concurrency *ratelimit.Concurrency, limits func() (ratelimit.Limit, int)) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		// Legacy secret callers have no key ID and fall back to their address
+		var key string
+		if apiKeyID, err := ctxutil.GetApiKeyIDCtx(ctx); err == nil {
+			key = fmt.Sprintf("internal:apikey:%d", apiKeyID)
+		} else if p, ok := peer.FromContext(ctx); ok {
+			host, _, _ := net.SplitHostPort(p.Addr.String())
+			key = "internal:ip:" + host
+		}
+
+		limit, maxConcurrent := limits()
+		result, err := store.Take(ctx, key, limit)
+		if err != nil {
+			slog.WarnContext(ctx, "[RateLimitInterceptor] Take", "error", err)
+			return handler(ctx, req)
+		}
+		if !result.Allowed {
+			slog.WarnContext(ctx, "[RateLimitInterceptor]", "method", info.FullMethod, "limited", key)
+			return nil, toStatusError(domain.ErrTooManyRequests)
+		}
+
+		if maxConcurrent > 0 {
+			if !concurrency.Acquire(key, maxConcurrent) {
+				slog.WarnContext(ctx, "[RateLimitInterceptor]", "method", info.FullMethod, "concurrencyLimited", key)
+				return nil, toStatusError(domain.ErrConcurrencyLimited)
+			}
+			defer concurrency.Release(key)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,65 @@
+package grpchandler
+
+import (
+	"context"
+	"testing"
+	"warehouse-service/app/domain"
+	warehousev1 "warehouse-service/proto/warehouse/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type staticApiKeys struct {
+	domain.ApiKeyUsecase
+	keys map[string]domain.ApiKey
+}
+
+func (s staticApiKeys) Authenticate(ctx context.Context, key string) (domain.ApiKey, error) {
+	apiKey, ok := s.keys[key]
+	if !ok {
+		return domain.ApiKey{}, domain.ErrUnauthorized
+	}
+	return apiKey, nil
+}
+
+func TestAuthInternalInterceptor(t *testing.T) {
+	interceptor := AuthInternalInterceptor("legacy", staticApiKeys{keys: map[string]domain.ApiKey{
+		"checkout": {ID: 1, Scopes: []domain.ApiKeyScope{domain.ApiKeyScopeReserve}},
+		"admin":    {ID: 2, Scopes: []domain.ApiKeyScope{domain.ApiKeyScopeTransferAdmin}},
+	}})
+
+	tests := []struct {
+		name   string
+		key    string
+		method string
+		want   codes.Code
+	}{
+		{"legacy secret reserves", "legacy", warehousev1.WarehouseService_Reserve_FullMethodName, codes.OK},
+		{"legacy secret inits stock", "legacy", warehousev1.WarehouseService_InitStock_FullMethodName, codes.OK},
+		{"legacy secret reads availability", "legacy", warehousev1.WarehouseService_GetAvailability_FullMethodName, codes.OK},
+		{"legacy secret creates transfer", "legacy", warehousev1.WarehouseService_CreateTransfer_FullMethodName, codes.PermissionDenied},
+		{"legacy secret reads transfer", "legacy", warehousev1.WarehouseService_GetTransfer_FullMethodName, codes.PermissionDenied},
+		{"legacy secret moves transfer", "legacy", warehousev1.WarehouseService_UpdateTransferStatus_FullMethodName, codes.PermissionDenied},
+		{"reserve key reserves", "checkout", warehousev1.WarehouseService_Reserve_FullMethodName, codes.OK},
+		{"reserve key moves transfer", "checkout", warehousev1.WarehouseService_UpdateTransferStatus_FullMethodName, codes.PermissionDenied},
+		{"transfer key moves transfer", "admin", warehousev1.WarehouseService_UpdateTransferStatus_FullMethodName, codes.OK},
+		{"transfer key reserves", "admin", warehousev1.WarehouseService_Reserve_FullMethodName, codes.PermissionDenied},
+		{"unknown key", "nope", warehousev1.WarehouseService_Reserve_FullMethodName, codes.Unauthenticated},
+		{"no key", "", warehousev1.WarehouseService_Reserve_FullMethodName, codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authInternalMetadataKey, tt.key))
+			handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s (err %v)", got, tt.want, err)
+			}
+		})
+	}
+}
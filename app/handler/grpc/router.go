@@ -0,0 +1,33 @@
+package grpchandler
+
+import (
+	"warehouse-service/app/domain"
+	"warehouse-service/config"
+	"warehouse-service/pkg/ratelimit"
+	warehousev1 "warehouse-service/proto/warehouse/v1"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+func NewServer(warehouseServer *WarehouseServer, apiKeyUsecase domain.ApiKeyUsecase, rateLimitStore ratelimit.Store, concurrency *ratelimit.Concurrency, live *config.Live) *grpc.Server {
+	cfg := live.Get()
+	interceptors := []grpc.UnaryServerInterceptor{
+		RequestIDInterceptor(),
+		LoggingInterceptor(),
+		RecoverInterceptor(),
+		AuthInternalInterceptor(cfg.InternalAuthHeader, apiKeyUsecase),
+	}
+	if cfg.RateLimit.Enabled {
+		interceptors = append(interceptors, RateLimitInterceptor(rateLimitStore, concurrency, func() (ratelimit.Limit, int) {
+			rl := live.Get().RateLimit
+			return ratelimit.Limit{Rate: rl.InternalRate, Burst: rl.InternalBurst}, rl.InternalConcurrency
+		}))
+	}
+
+	// The stats handler starts the server span (from grpc-trace metadata)
+	// before the interceptors run
+	server := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()), grpc.ChainUnaryInterceptor(interceptors...))
+	warehousev1.RegisterWarehouseServiceServer(server, warehouseServer)
+	return server
+}
@@ -0,0 +1,219 @@
+package grpchandler
+
+import (
+	"context"
+	"log/slog"
+	"warehouse-service/app/domain"
+	warehousev1 "warehouse-service/proto/warehouse/v1"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type WarehouseServer struct {
+	warehousev1.UnimplementedWarehouseServiceServer
+	stockUsecase         domain.StockService
+	reservedStockUsecase domain.ReservedStockUsecase
+	stockTransferUsecase domain.StockTransferUsecase
+	validator            *validator.Validate
+}
+
+func NewWarehouseServer(
+	stockUsecase domain.StockService,
+	reservedStockUsecase domain.ReservedStockUsecase,
+	stockTransferUsecase domain.StockTransferUsecase,
+	validator *validator.Validate,
+) *WarehouseServer {
+	return &WarehouseServer{
+		stockUsecase:         stockUsecase,
+		reservedStockUsecase: reservedStockUsecase,
+		stockTransferUsecase: stockTransferUsecase,
+		validator:            validator,
+	}
+}
+
+func (s *WarehouseServer) InitStock(ctx context.Context, in *warehousev1.InitStockRequest) (*warehousev1.InitStockResponse, error) {
+	req := domain.StockCreateRequest{
+		ShopID:    in.GetShopId(),
+		ProductID: in.GetProductId(),
+	}
+	if err := s.validator.Struct(req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] InitStock", "validation", err)
+		return nil, toStatusError(domain.ErrValidation)
+	}
+
+	stocks, err := s.stockUsecase.InitStock(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] InitStock", "usecase", err)
+		return nil, toStatusError(err)
+	}
+
+	resp := &warehousev1.InitStockResponse{Stocks: make([]*warehousev1.Stock, 0, len(stocks))}
+	for _, stock := range stocks {
+		resp.Stocks = append(resp.Stocks, &warehousev1.Stock{
+			Id:          stock.ID,
+			ProductId:   stock.ProductID,
+			WarehouseId: stock.WarehouseID,
+			Quantity:    stock.Quantity,
+			CreatedAt:   timestamppb.New(stock.CreatedAt),
+			UpdatedAt:   timestamppb.New(stock.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *WarehouseServer) GetAvailability(ctx context.Context, in *warehousev1.GetAvailabilityRequest) (*warehousev1.GetAvailabilityResponse, error) {
+	if len(in.GetProductIds()) == 0 {
+		slog.ErrorContext(ctx, "[warehouseServer] GetAvailability", "productIDs", "empty")
+		return nil, toStatusError(domain.ErrValidation)
+	}
+
+	availableStocks, err := s.stockUsecase.GetAvailableStockByProductIDs(ctx, in.GetProductIds())
+	if err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] GetAvailability", "usecase", err)
+		return nil, toStatusError(err)
+	}
+
+	resp := &warehousev1.GetAvailabilityResponse{Items: make([]*warehousev1.ProductAvailability, 0, len(availableStocks))}
+	for _, availableStock := range availableStocks {
+		resp.Items = append(resp.Items, &warehousev1.ProductAvailability{
+			ProductId:      availableStock.ProductID,
+			AvailableStock: availableStock.AvailableStock,
+		})
+	}
+	return resp, nil
+}
+
+func (s *WarehouseServer) Reserve(ctx context.Context, in *warehousev1.ReserveRequest) (*warehousev1.ReserveResponse, error) {
+	req := domain.ReservedStockCreateRequest{
+		ProductID: in.GetProductId(),
+		Quantity:  in.GetQuantity(),
+		OrderID:   in.GetOrderId(),
+	}
+	if err := s.validator.Struct(req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] Reserve", "validation", err)
+		return nil, toStatusError(domain.ErrValidation)
+	}
+
+	if err := s.reservedStockUsecase.CreateReservedStock(ctx, req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] Reserve", "usecase", err)
+		return nil, toStatusError(err)
+	}
+	return &warehousev1.ReserveResponse{}, nil
+}
+
+func (s *WarehouseServer) UpdateReservationStatus(ctx context.Context, in *warehousev1.UpdateReservationStatusRequest) (*warehousev1.UpdateReservationStatusResponse, error) {
+	req := domain.ReservedStockUpdateRequest{
+		Status: reservationStatusFromProto[in.GetStatus()],
+	}
+	if in.GetOrderId() <= 0 {
+		slog.ErrorContext(ctx, "[warehouseServer] UpdateReservationStatus", "orderID", in.GetOrderId())
+		return nil, toStatusError(domain.ErrValidation)
+	}
+	if err := s.validator.Struct(req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] UpdateReservationStatus", "validation", err)
+		return nil, toStatusError(domain.ErrValidation)
+	}
+
+	if err := s.reservedStockUsecase.UpdateReservedStockStatusByOrderID(ctx, in.GetOrderId(), req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] UpdateReservationStatus", "usecase", err)
+		return nil, toStatusError(err)
+	}
+	return &warehousev1.UpdateReservationStatusResponse{}, nil
+}
+
+func (s *WarehouseServer) CreateTransfer(ctx context.Context, in *warehousev1.CreateTransferRequest) (*warehousev1.CreateTransferResponse, error) {
+	req := domain.StockTransferCreateRequest{
+		ProductID:     in.GetProductId(),
+		FromWarehouse: in.GetFromWarehouse(),
+		ToWarehouse:   in.GetToWarehouse(),
+		Quantity:      in.GetQuantity(),
+		Description:   in.GetDescription(),
+	}
+	if in.GetShopId() <= 0 {
+		slog.ErrorContext(ctx, "[warehouseServer] CreateTransfer", "shopID", in.GetShopId())
+		return nil, toStatusError(domain.ErrValidation)
+	}
+	if err := s.validator.Struct(req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] CreateTransfer", "validation", err)
+		return nil, toStatusError(domain.ErrValidation)
+	}
+
+	transfer, err := s.stockTransferUsecase.CreateTransfer(ctx, in.GetShopId(), req)
+	if err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] CreateTransfer", "usecase", err)
+		return nil, toStatusError(err)
+	}
+	return &warehousev1.CreateTransferResponse{Transfer: toProtoTransfer(*transfer)}, nil
+}
+
+func (s *WarehouseServer) GetTransfer(ctx context.Context, in *warehousev1.GetTransferRequest) (*warehousev1.GetTransferResponse, error) {
+	if in.GetId() <= 0 {
+		slog.ErrorContext(ctx, "[warehouseServer] GetTransfer", "id", in.GetId())
+		return nil, toStatusError(domain.ErrValidation)
+	}
+
+	transfer, err := s.stockTransferUsecase.GetTransferByID(ctx, in.GetId(), in.ShopId)
+	if err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] GetTransfer", "usecase", err)
+		return nil, toStatusError(err)
+	}
+	return &warehousev1.GetTransferResponse{Transfer: toProtoTransfer(transfer)}, nil
+}
+
+func (s *WarehouseServer) UpdateTransferStatus(ctx context.Context, in *warehousev1.UpdateTransferStatusRequest) (*warehousev1.UpdateTransferStatusResponse, error) {
+	req := domain.StockTransferUpdateRequest{
+		Status:      transferStatusFromProto[in.GetStatus()],
+		Description: in.GetDescription(),
+	}
+	if in.GetId() <= 0 {
+		slog.ErrorContext(ctx, "[warehouseServer] UpdateTransferStatus", "id", in.GetId())
+		return nil, toStatusError(domain.ErrValidation)
+	}
+	if err := s.validator.Struct(req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] UpdateTransferStatus", "validation", err)
+		return nil, toStatusError(domain.ErrValidation)
+	}
+
+	if err := s.stockTransferUsecase.UpdateTransferStatus(ctx, in.GetId(), req); err != nil {
+		slog.ErrorContext(ctx, "[warehouseServer] UpdateTransferStatus", "usecase", err)
+		return nil, toStatusError(err)
+	}
+	return &warehousev1.UpdateTransferStatusResponse{}, nil
+}
+
+var reservationStatusFromProto = map[warehousev1.ReservationStatus]domain.ReservedStockStatus{
+	warehousev1.ReservationStatus_RESERVATION_STATUS_ACTIVE:    domain.ReservedStockStatusActive,
+	warehousev1.ReservationStatus_RESERVATION_STATUS_COMPLETED: domain.ReservedStockStatusCompleted,
+	warehousev1.ReservationStatus_RESERVATION_STATUS_CANCELLED: domain.ReservedStockStatusCancelled,
+}
+
+var transferStatusFromProto = map[warehousev1.TransferStatus]domain.TransferStatus{
+	warehousev1.TransferStatus_TRANSFER_STATUS_NOT_STARTED: domain.TransferStatusNotStarted,
+	warehousev1.TransferStatus_TRANSFER_STATUS_IN_PROGRESS: domain.TransferStatusInProgress,
+	warehousev1.TransferStatus_TRANSFER_STATUS_REVERTED:    domain.TransferStatusReverted,
+	warehousev1.TransferStatus_TRANSFER_STATUS_COMPLETED:   domain.TransferStatusCompleted,
+	warehousev1.TransferStatus_TRANSFER_STATUS_FAILED:      domain.TransferStatusFailed,
+}
+
+func toProtoTransfer(transfer domain.StockTransfer) *warehousev1.StockTransfer {
+	var status warehousev1.TransferStatus
+	for protoStatus, domainStatus := range transferStatusFromProto {
+		if domainStatus == transfer.Status {
+			status = protoStatus
+			break
+		}
+	}
+
+	return &warehousev1.StockTransfer{
+		Id:            transfer.ID,
+		ProductId:     transfer.ProductID,
+		FromWarehouse: transfer.FromWarehouse,
+		ToWarehouse:   transfer.ToWarehouse,
+		Quantity:      transfer.Quantity,
+		Status:        status,
+		Description:   transfer.Description,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+		UpdatedAt:     timestamppb.New(transfer.UpdatedAt),
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
 	"log/slog"
 	"warehouse-service/app/domain"
 	"warehouse-service/app/handler/api/response"
@@ -18,65 +20,108 @@ const (
 	AuthWarehouseAdminHeaderKey AuthInternalHeader = "X-Warehouse-Admin-Auth"
 )
 
-func AuthInternal(cfg *config.Config) fiber.Handler {
+// AuthInternal authenticates internal callers with an API key sent in
+// X-Internal-Auth. The static INTERNAL_AUTH_HEADER secret, when set, is still
+// accepted with the internal scopes while callers move to API keys.
+func AuthInternal(cfg *config.Config, apiKeyUsecase domain.ApiKeyUsecase) fiber.Handler {
+	return authApiKey(AuthInternalHeaderKey, cfg.InternalAuthHeader, apiKeyUsecase,
+		domain.ApiKeyScopeReserve, domain.ApiKeyScopeInitStock)
+}
+
+// AuthWarehouseAdmin is AuthInternal for the admin routes, using
+// X-Warehouse-Admin-Auth and WAREHOUSE_ADMIN_AUTH_HEADER.
+func AuthWarehouseAdmin(cfg *config.Config, apiKeyUsecase domain.ApiKeyUsecase) fiber.Handler {
+	return authApiKey(AuthWarehouseAdminHeaderKey, cfg.WarehouseAdminAuthHeader, apiKeyUsecase,
+		domain.ApiKeyScopeTransferAdmin, domain.ApiKeyScopeSnapshot, domain.ApiKeyScopeConfig)
+}
+
+func authApiKey(header AuthInternalHeader, legacySecret string, apiKeyUsecase domain.ApiKeyUsecase, legacyScopes ...domain.ApiKeyScope) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the auth header from the request
-		authHeader := c.Get(string(AuthInternalHeaderKey))
+		authHeader := c.Get(string(header))
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
+			return response.Error(c, domain.ErrUnauthorized)
 		}
-		// Check if the auth header is valid (you can implement your own logic here)
-		if authHeader != cfg.InternalAuthHeader {
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
+
+		if legacySecret != "" && subtle.ConstantTimeCompare([]byte(authHeader), []byte(legacySecret)) == 1 {
+			slog.WarnContext(c.UserContext(), "[middleware] AuthApiKey", "legacySecret", string(header))
+			setContextValue(c, ctxutil.ApiKeyScopesKey, legacyScopes)
+			return c.Next()
+		}
+
+		apiKey, err := apiKeyUsecase.Authenticate(c.UserContext(), authHeader)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "[middleware] AuthApiKey", "Authenticate", err)
+			return response.Error(c, err)
 		}
 
+		setContextValue(c, ctxutil.ApiKeyIDKey, apiKey.ID)
+		setContextValue(c, ctxutil.ApiKeyScopesKey, apiKey.Scopes)
 		return c.Next()
 	}
 }
 
-func AuthWarehouseAdmin(cfg *config.Config) fiber.Handler {
+// RequireScope must run after AuthInternal or AuthWarehouseAdmin. The API key
+// needs at least one of the scopes.
+func RequireScope(scopes ...domain.ApiKeyScope) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get the auth header from the request
-		authHeader := c.Get(string(AuthWarehouseAdminHeaderKey))
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
-		}
-		// Check if the auth header is valid (you can implement your own logic here)
-		if authHeader != cfg.WarehouseAdminAuthHeader {
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
+		granted, _ := c.Locals(ctxutil.ApiKeyScopesKey).([]domain.ApiKeyScope)
+		if domain.HasAnyScope(granted, scopes...) {
+			return c.Next()
 		}
 
-		return c.Next()
+		slog.WarnContext(c.UserContext(), "[middleware] RequireScope", "granted", granted, "required", scopes)
+		return response.Error(c, domain.ErrApiKeyScopeMissing)
 	}
 }
 
-func Auth(secretKey string) fiber.Handler {
+// Auth verifies the JWT and stores the user, shop and role in the request
+// context. The role comes from the `role` claim when present, otherwise from
+// the shop role table.
+func Auth(verifier *pkg.JwtVerifier, userRoleUsecase domain.UserRoleUsecase) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
 		token, err := pkg.GetTokenFromHeaders(c.Get("Authorization"))
 		if err != nil {
-			slog.ErrorContext(c.Context(), "[middleware] Auth", "GetTokenFromHeaders", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
+			slog.ErrorContext(c.UserContext(), "[middleware] Auth", "GetTokenFromHeaders", err)
+			return response.Error(c, domain.ErrUnauthorized)
 		}
 
-		claims, err := pkg.ParseJwtToken(token, secretKey)
+		claims, err := verifier.Parse(c.UserContext(), token)
 		if err != nil {
-			slog.ErrorContext(c.Context(), "[middleware] Auth", "ParseJwtToken", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
+			slog.ErrorContext(c.UserContext(), "[middleware] Auth", "ParseJwt", err)
+			return response.Error(c, domain.ErrUnauthorized)
 		}
 
 		if claims.UID == 0 {
-			slog.ErrorContext(c.Context(), "[middleware] Auth", "userID", "0")
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
+			slog.ErrorContext(c.UserContext(), "[middleware] Auth", "userID", "0")
+			return response.Error(c, domain.ErrUnauthorized)
 		}
 
 		if claims.SID == nil {
-			slog.ErrorContext(c.Context(), "[middleware] Auth", "shopID", "nil")
-			return c.Status(fiber.StatusUnauthorized).JSON(response.Error(domain.ErrUnauthorized))
+			slog.ErrorContext(c.UserContext(), "[middleware] Auth", "shopID", "nil")
+			return response.Error(c, domain.ErrUnauthorized)
 		}
 
-		c.Locals(ctxutil.UserIDKey, claims.UID)
-		c.Locals(ctxutil.ShopIDKey, *claims.SID)
+		role := domain.Role(claims.Role)
+		if role == "" {
+			role, err = userRoleUsecase.ResolveRole(c.UserContext(), *claims.SID, claims.UID)
+			if err != nil {
+				slog.ErrorContext(c.UserContext(), "[middleware] Auth", "ResolveRole", err)
+				return response.Error(c, domain.ErrInternal)
+			}
+		}
+
+		setContextValue(c, ctxutil.UserIDKey, claims.UID)
+		setContextValue(c, ctxutil.ShopIDKey, *claims.SID)
+		setContextValue(c, ctxutil.RoleKey, string(role))
 		return c.Next()
 	}
 }
+
+// setContextValue stores value both in Locals and in the user context that
+// handlers pass to the usecases.
+func setContextValue(c *fiber.Ctx, key, value any) {
+	c.Locals(key, value)
+	c.SetUserContext(context.WithValue(c.UserContext(), key, value))
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+	"warehouse-service/pkg/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Metrics records the latency of every request by route pattern, so
+// /stocks/1 and /stocks/2 share one series.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		route := c.Route().Path
+		if status == fiber.StatusNotFound && route == "/" {
+			// Unmatched paths would otherwise create a series per URL
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequestDuration.WithLabelValues(c.Method(), route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log/slog"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission must run after Auth. It rejects callers whose role lacks
+// the permission with 403.
+func RequirePermission(permission domain.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, err := ctxutil.GetRoleCtx(c.UserContext())
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "[middleware] RequirePermission", "GetRoleCtx", err)
+			return response.Error(c, domain.ErrPermissionDenied)
+		}
+
+		if !domain.Role(role).Can(permission) {
+			slog.WarnContext(c.UserContext(), "[middleware] RequirePermission", "role", role, "permission", permission)
+			return response.Error(c, domain.ErrPermissionDenied)
+		}
+
+		return c.Next()
+	}
+}
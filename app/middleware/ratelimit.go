@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/handler/api/response"
+	"warehouse-service/pkg/ctxutil"
+	"warehouse-service/pkg/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RateLimitKey string
+
+const (
+	RateLimitKeyShop   RateLimitKey = "shop"
+	RateLimitKeyUser   RateLimitKey = "user"
+	RateLimitKeyApiKey RateLimitKey = "apikey"
+)
+
+type RateLimitConfig struct {
+	// Group separates budgets, e.g. "public" and "internal".
+	Group string
+	KeyBy RateLimitKey
+	// Limits returns the bucket limit and the cap on in-flight requests per
+	// key (0 is unlimited). It is called per request so reloaded limits apply
+	// right away.
+	Limits func() (limit ratelimit.Limit, maxConcurrent int)
+}
+
+// RateLimit must run after the group's auth middleware, which provides the
+// shop, user or API key the bucket is keyed by. Callers without one fall back
+// to their IP.
+func RateLimit(store ratelimit.Store, concurrency *ratelimit.Concurrency, cfg RateLimitConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := cfg.Group + ":" + rateLimitKey(c, cfg.KeyBy)
+		limit, maxConcurrent := cfg.Limits()
+
+		result, err := store.Take(c.UserContext(), key, limit)
+		if err != nil {
+			// Fail open, an unavailable store should not take the API down
+			slog.WarnContext(c.UserContext(), "[middleware] RateLimit", "take", err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+		if !result.Allowed {
+			slog.WarnContext(c.UserContext(), "[middleware] RateLimit", "limited", key)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+			return response.Error(c, domain.ErrTooManyRequests)
+		}
+
+		if maxConcurrent > 0 {
+			if !concurrency.Acquire(key, maxConcurrent) {
+				slog.WarnContext(c.UserContext(), "[middleware] RateLimit", "concurrencyLimited", key)
+				c.Set(fiber.HeaderRetryAfter, "1")
+				return response.Error(c, domain.ErrConcurrencyLimited)
+			}
+			defer concurrency.Release(key)
+		}
+
+		return c.Next()
+	}
+}
+
+func rateLimitKey(c *fiber.Ctx, keyBy RateLimitKey) string {
+	switch keyBy {
+	case RateLimitKeyShop:
+		if shopID, err := ctxutil.GetShopIDCtx(c.UserContext()); err == nil {
+			return fmt.Sprintf("shop:%d", shopID)
+		}
+	case RateLimitKeyUser:
+		if userID, err := ctxutil.GetUserIDCtx(c.UserContext()); err == nil {
+			return fmt.Sprintf("user:%d", userID)
+		}
+	case RateLimitKeyApiKey:
+		if apiKeyID, err := ctxutil.GetApiKeyIDCtx(c.UserContext()); err == nil {
+			return fmt.Sprintf("apikey:%d", apiKeyID)
+		}
+	}
+	return "ip:" + c.IP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofrs/uuid/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func RequestIDMiddleware() fiber.Handler {
@@ -19,6 +21,8 @@ func RequestIDMiddleware() fiber.Handler {
 			reqID = uuidV4.String()
 		}
 		c.Locals(ctxutil.RequestIDKey, reqID)
+		c.SetUserContext(ctxutil.WithRequestID(c.UserContext(), reqID))
+		trace.SpanFromContext(c.UserContext()).SetAttributes(attribute.String("request.id", reqID))
 		return c.Next()
 	}
 }
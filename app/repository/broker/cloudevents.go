@@ -0,0 +1,135 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"warehouse-service/pkg/ctxutil"
+	"warehouse-service/pkg/metrics"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("warehouse-service/app/repository/broker")
+
+const (
+	cloudEventsSpecVersion = "1.0"
+
+	CloudEventsModeBinary     = "binary"
+	CloudEventsModeStructured = "structured"
+
+	contentTypeJSON       = "application/json"
+	contentTypeCloudEvent = "application/cloudevents+json"
+)
+
+// cloudEvent holds the CloudEvents context attributes of an outgoing message.
+// In structured mode it is the message body; in binary mode the attributes
+// travel as ce-* headers and Data is the body.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+func (s *stockBroker) newCloudEvent(ctx context.Context, id, eventType, subject string, occurredAt time.Time, data []byte) cloudEvent {
+	return cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              id,
+		Source:          s.cfg.Event.Source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            occurredAt,
+		DataContentType: contentTypeJSON,
+		CorrelationID:   ctxutil.GetRequestID(ctx),
+		Data:            data,
+	}
+}
+
+// toNatsMsg encodes the event for the configured CloudEvents mode.
+func (s *stockBroker) toNatsMsg(natsSubject string, event cloudEvent) (*nats.Msg, error) {
+	msg := nats.NewMsg(natsSubject)
+
+	if s.cfg.Event.CloudEventsMode == CloudEventsModeStructured {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("marshal cloudevent: %w", err)
+		}
+		msg.Header.Set("Content-Type", contentTypeCloudEvent)
+		msg.Data = body
+		return msg, nil
+	}
+
+	msg.Header.Set("Content-Type", event.DataContentType)
+	msg.Header.Set("ce-specversion", event.SpecVersion)
+	msg.Header.Set("ce-id", event.ID)
+	msg.Header.Set("ce-source", event.Source)
+	msg.Header.Set("ce-type", event.Type)
+	msg.Header.Set("ce-time", event.Time.Format(time.RFC3339Nano))
+	if event.Subject != "" {
+		msg.Header.Set("ce-subject", event.Subject)
+	}
+	if event.CorrelationID != "" {
+		msg.Header.Set("ce-correlationid", event.CorrelationID)
+	}
+	msg.Data = event.Data
+	return msg, nil
+}
+
+// publishCloudEvent publishes with Nats-Msg-Id set to the event ID so the
+// stream's duplicate window drops retried publishes.
+func (s *stockBroker) publishCloudEvent(ctx context.Context, natsSubject string, event cloudEvent) error {
+	ctx, span := tracer.Start(ctx, natsSubject+" publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "nats"),
+			attribute.String("messaging.destination.name", natsSubject),
+			attribute.String("messaging.message.id", event.ID),
+		))
+	defer span.End()
+
+	msg, err := s.toNatsMsg(natsSubject, event)
+	if err != nil {
+		return err
+	}
+	// traceparent/tracestate headers let consumers continue the trace
+	otel.GetTextMapPropagator().Inject(ctx, natsHeaderCarrier(msg.Header))
+
+	if _, err = s.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.ID)); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		metrics.NatsPublishTotal.WithLabelValues(natsSubject, "failure").Inc()
+		return err
+	}
+	metrics.NatsPublishTotal.WithLabelValues(natsSubject, "success").Inc()
+	return nil
+}
+
+// natsHeaderCarrier keeps header names as given, NATS headers are case
+// sensitive unlike HTTP ones.
+type natsHeaderCarrier nats.Header
+
+func (h natsHeaderCarrier) Get(key string) string {
+	return nats.Header(h).Get(key)
+}
+
+func (h natsHeaderCarrier) Set(key, value string) {
+	nats.Header(h).Set(key, value)
+}
+
+func (h natsHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -3,34 +3,172 @@ package broker
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"time"
 	"warehouse-service/app/domain"
+	"warehouse-service/config"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+const (
+	subjectStockAvailable = "stock.available"
+	subjectStockChanged   = "stock.changed"
+	subjectStockSnapshot  = "stock.snapshot"
+	subjectStockTransfer  = "stock.transfer"
+)
+
 type stockBroker struct {
-	js jetstream.JetStream
+	js           jetstream.JetStream
+	sequenceRepo domain.EventSequenceRepository
+	cfg          *config.Config
 }
 
-func NewStockBrokerPublisher(stream jetstream.JetStream) domain.BrokerPublisher {
+func NewStockBrokerPublisher(stream jetstream.JetStream, sequenceRepo domain.EventSequenceRepository, cfg *config.Config) domain.BrokerPublisher {
 	return &stockBroker{
-		js: stream,
+		js:           stream,
+		sequenceRepo: sequenceRepo,
+		cfg:          cfg,
 	}
 }
 
 func (s *stockBroker) PublishStockAvailable(ctx context.Context, data domain.StockMessage) error {
+	event, err := s.newStockEvent(ctx, data)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockAvailable", "newStockEvent", err)
+		return err
+	}
 
-	msg, err := json.Marshal(data)
+	msg, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockAvailable", "json.Marshal", err)
+		return err
+	}
+
+	productSubject := strconv.FormatInt(data.ProductID, 10)
+	ce := s.newCloudEvent(ctx, event.EventID, event.Type, productSubject, event.OccurredAt, msg)
+	if err = s.publishCloudEvent(ctx, subjectStockChanged, ce); err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockAvailable", "Publish", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "[stockBroker] PublishStockAvailable", "subject", subjectStockChanged, "message", msg)
+
+	// The legacy payload stays on stock.available until every consumer has
+	// moved to the envelope.
+	if !s.cfg.Event.LegacyPayload {
+		return nil
+	}
+
+	legacyMsg, err := json.Marshal(data)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockBroker] PublishStockAvailable", "json.Marshal", err)
+		return err
 	}
 
-	if _, err = s.js.Publish(ctx, "stock.available", msg); err != nil {
+	legacyCE := s.newCloudEvent(ctx, event.EventID+"-legacy", subjectStockAvailable, productSubject, event.OccurredAt, legacyMsg)
+	if err = s.publishCloudEvent(ctx, subjectStockAvailable, legacyCE); err != nil {
 		slog.ErrorContext(ctx, "[stockBroker] PublishStockAvailable", "Publish", err)
 		return err
 	}
 
-	slog.InfoContext(ctx, "[stockBroker] PublishStockAvailable", "message", msg)
+	slog.InfoContext(ctx, "[stockBroker] PublishStockAvailable", "subject", subjectStockAvailable, "message", legacyMsg)
+	return nil
+}
+
+func (s *stockBroker) newStockEvent(ctx context.Context, data domain.StockMessage) (domain.StockEvent, error) {
+	eventID, err := uuid.NewV4()
+	if err != nil {
+		return domain.StockEvent{}, fmt.Errorf("generate event id: %w", err)
+	}
+
+	sequence, err := s.sequenceRepo.NextSequence(ctx, data.ProductID, data.Tx)
+	if err != nil {
+		return domain.StockEvent{}, fmt.Errorf("next sequence: %w", err)
+	}
+
+	return domain.StockEvent{
+		EventID:       eventID.String(),
+		Type:          domain.StockEventTypeAvailabilityChanged,
+		SchemaVersion: domain.StockEventSchemaVersion,
+		OccurredAt:    time.Now().UTC(),
+		ShopID:        data.ShopID,
+		WarehouseID:   data.WarehouseID,
+		Cause:         data.Cause,
+		Sequence:      sequence,
+		Data: domain.StockEventData{
+			ProductID: data.ProductID,
+			Available: data.Available,
+		},
+	}, nil
+}
+
+func (s *stockBroker) PublishStockAlert(ctx context.Context, data domain.StockAlertMessage) error {
+	msg, err := json.Marshal(data)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockAlert", "json.Marshal", err)
+		return err
+	}
+
+	eventID, err := uuid.NewV4()
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockAlert", "uuid.NewV4", err)
+		return err
+	}
+
+	subject := fmt.Sprintf("stock.%s", data.Type)
+	ce := s.newCloudEvent(ctx, eventID.String(), subject, strconv.FormatInt(data.ProductID, 10), time.Now().UTC(), msg)
+	if err = s.publishCloudEvent(ctx, subject, ce); err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockAlert", "Publish", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "[stockBroker] PublishStockAlert", "subject", subject, "message", msg)
+	return nil
+}
+
+func (s *stockBroker) PublishStockSnapshot(ctx context.Context, data domain.StockSnapshotMessage) error {
+	msg, err := json.Marshal(data)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockSnapshot", "json.Marshal", err)
+		return err
+	}
+
+	// One message per product per snapshot run, so a resumed page that
+	// overlaps the previous one is deduplicated by the stream.
+	eventID := fmt.Sprintf("%s-%d", data.SnapshotID, data.ProductID)
+	ce := s.newCloudEvent(ctx, eventID, subjectStockSnapshot, strconv.FormatInt(data.ProductID, 10), data.SnapshotAt, msg)
+	if err = s.publishCloudEvent(ctx, subjectStockSnapshot, ce); err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockSnapshot", "Publish", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *stockBroker) PublishStockTransfer(ctx context.Context, data domain.StockTransferMessage) error {
+	msg, err := json.Marshal(data)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockTransfer", "json.Marshal", err)
+		return err
+	}
+
+	eventID, err := uuid.NewV4()
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockTransfer", "uuid.NewV4", err)
+		return err
+	}
+
+	ce := s.newCloudEvent(ctx, eventID.String(), domain.StockTransferEventTypeUpdated,
+		strconv.FormatInt(data.Transfer.ID, 10), time.Now().UTC(), msg)
+	if err = s.publishCloudEvent(ctx, subjectStockTransfer, ce); err != nil {
+		slog.ErrorContext(ctx, "[stockBroker] PublishStockTransfer", "Publish", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "[stockBroker] PublishStockTransfer", "subject", subjectStockTransfer, "message", msg)
 	return nil
 }
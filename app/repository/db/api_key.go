@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"warehouse-service/app/domain"
+)
+
+type apiKeyRepository struct {
+	conn *sql.DB
+}
+
+func NewApiKeyRepository(db *sql.DB) domain.ApiKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanApiKey(row interface{ Scan(...any) error }) (domain.ApiKey, error) {
+	var apiKey domain.ApiKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&apiKey.ID, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash, &scopes,
+		&expiresAt, &lastUsedAt, &revokedAt, &apiKey.CreatedAt)
+	for _, scope := range strings.Split(scopes, ",") {
+		if scope != "" {
+			apiKey.Scopes = append(apiKey.Scopes, domain.ApiKeyScope(scope))
+		}
+	}
+	if expiresAt.Valid {
+		apiKey.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		apiKey.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		apiKey.RevokedAt = &revokedAt.Time
+	}
+	return apiKey, err
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey *domain.ApiKey) error {
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	query := `INSERT INTO api_keys (name, prefix, key_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at`
+	err := r.conn.QueryRowContext(ctx, query, apiKey.Name, apiKey.Prefix, apiKey.KeyHash,
+		strings.Join(scopes, ","), apiKey.ExpiresAt).
+		Scan(&apiKey.ID, &apiKey.CreatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyRepository] Create", "queryRowContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (domain.ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+
+	apiKey, err := scanApiKey(r.conn.QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apiKey, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "[apiKeyRepository] GetByPrefix", "queryRowContext", err)
+		return apiKey, err
+	}
+	return apiKey, nil
+}
+
+func (r *apiKeyRepository) GetList(ctx context.Context) ([]domain.ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id ASC`
+
+	rows, err := r.conn.QueryContext(ctx, query)
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyRepository] GetList", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apiKeys []domain.ApiKey
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "[apiKeyRepository] GetList", "scan", err)
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[apiKeyRepository] GetList", "rowError", err)
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	result, err := r.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyRepository] Revoke", "execContext", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyRepository] Revoke", "rowsAffected", err)
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET last_used_at = NOW()
+	WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+	_, err := r.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyRepository] TouchLastUsed", "execContext", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"warehouse-service/app/domain"
+)
+
+type eventSequenceRepository struct {
+	conn *sql.DB
+}
+
+func NewEventSequenceRepository(db *sql.DB) domain.EventSequenceRepository {
+	return &eventSequenceRepository{db}
+}
+
+func (r *eventSequenceRepository) NextSequence(ctx context.Context, productID int64, tx *sql.Tx) (int64, error) {
+	query := `INSERT INTO product_event_sequences (product_id, last_sequence) VALUES ($1, 1)
+	ON CONFLICT (product_id) DO UPDATE SET last_sequence = product_event_sequences.last_sequence + 1
+	RETURNING last_sequence`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, productID)
+	} else {
+		row = r.conn.QueryRowContext(ctx, query, productID)
+	}
+
+	var sequence int64
+	err := row.Scan(&sequence)
+	if err != nil {
+		slog.ErrorContext(ctx, "[eventSequenceRepository] NextSequence", "queryRowContext", err)
+		return 0, err
+	}
+	return sequence, nil
+}
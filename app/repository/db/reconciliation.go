@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"warehouse-service/app/domain"
+)
+
+type reconciliationRepository struct {
+	conn *sql.DB
+}
+
+func NewReconciliationRepository(conn *sql.DB) domain.ReconciliationRepository {
+	return &reconciliationRepository{conn}
+}
+
+// Every check selects shop_id, product_id, warehouse_id, stock_id,
+// transfer_id, quantity, reserved and detail, see getViolations.
+
+func (r *reconciliationRepository) GetOverReservedStocks(ctx context.Context) ([]domain.ReconciliationViolation, error) {
+	query := `SELECT w.shop_id, s.product_id, s.warehouse_id, s.id, 0, s.quantity, r.reserved, ''
+	FROM stocks s
+	JOIN warehouses w ON s.warehouse_id = w.id
+	JOIN (
+		SELECT stock_id, SUM(quantity) AS reserved FROM reserved_stocks WHERE status = 'active' GROUP BY stock_id
+	) r ON r.stock_id = s.id
+	WHERE r.reserved > s.quantity
+	ORDER BY s.id ASC`
+	return r.getViolations(ctx, "GetOverReservedStocks", domain.InvariantReservedWithinQuantity, query)
+}
+
+func (r *reconciliationRepository) GetNegativeStocks(ctx context.Context) ([]domain.ReconciliationViolation, error) {
+	query := `SELECT w.shop_id, s.product_id, s.warehouse_id, s.id, 0, s.quantity, COALESCE(r.reserved, 0), ''
+	FROM stocks s
+	JOIN warehouses w ON s.warehouse_id = w.id
+	LEFT JOIN (
+		SELECT stock_id, SUM(quantity) AS reserved FROM reserved_stocks WHERE status = 'active' GROUP BY stock_id
+	) r ON r.stock_id = s.id
+	WHERE s.quantity < 0
+	ORDER BY s.id ASC`
+	return r.getViolations(ctx, "GetNegativeStocks", domain.InvariantNonNegativeQuantity, query)
+}
+
+func (r *reconciliationRepository) GetInProgressTransfersWithoutStock(ctx context.Context) ([]domain.ReconciliationViolation, error) {
+	// One row per missing side, the warehouse is the one without a stock row
+	query := `SELECT w.shop_id, t.product_id, m.warehouse_id, 0, t.id, t.quantity, 0,
+		CASE WHEN m.warehouse_id = t.from_warehouse THEN 'source stock row missing' ELSE 'destination stock row missing' END
+	FROM stock_transfers t
+	CROSS JOIN LATERAL (VALUES (t.from_warehouse), (t.to_warehouse)) AS m(warehouse_id)
+	JOIN warehouses w ON w.id = m.warehouse_id
+	WHERE t.status = 'in_progress'
+		AND NOT EXISTS (SELECT 1 FROM stocks s WHERE s.product_id = t.product_id AND s.warehouse_id = m.warehouse_id)
+	ORDER BY t.id ASC, m.warehouse_id ASC`
+	return r.getViolations(ctx, "GetInProgressTransfersWithoutStock", domain.InvariantTransferStockRows, query)
+}
+
+func (r *reconciliationRepository) GetMissingStocks(ctx context.Context) ([]domain.ReconciliationViolation, error) {
+	// A product belongs to a shop once any of the shop's warehouses stocks it
+	query := `SELECT w.shop_id, p.product_id, w.id, 0, 0, 0, 0, ''
+	FROM (
+		SELECT DISTINCT s.product_id, sw.shop_id FROM stocks s JOIN warehouses sw ON s.warehouse_id = sw.id
+	) p
+	JOIN warehouses w ON w.shop_id = p.shop_id AND w.active
+	WHERE NOT EXISTS (SELECT 1 FROM stocks s WHERE s.product_id = p.product_id AND s.warehouse_id = w.id)
+	ORDER BY p.product_id ASC, w.id ASC`
+	return r.getViolations(ctx, "GetMissingStocks", domain.InvariantStockRowPerWarehouse, query)
+}
+
+func (r *reconciliationRepository) CreateMissingStock(ctx context.Context, productID, warehouseID int64) (int64, error) {
+	query := `INSERT INTO stocks (product_id, warehouse_id)
+	SELECT $1, $2
+	WHERE NOT EXISTS (SELECT 1 FROM stocks WHERE product_id = $1 AND warehouse_id = $2)
+	RETURNING id`
+
+	var id int64
+	err := r.conn.QueryRowContext(ctx, query, productID, warehouseID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "[reconciliationRepository] CreateMissingStock", "queryRowContext", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *reconciliationRepository) getViolations(ctx context.Context, method string, invariant domain.ReconciliationInvariant, query string, args ...any) ([]domain.ReconciliationViolation, error) {
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[reconciliationRepository] "+method, "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var violations []domain.ReconciliationViolation
+	for rows.Next() {
+		violation := domain.ReconciliationViolation{Invariant: invariant}
+		err := rows.Scan(&violation.ShopID, &violation.ProductID, &violation.WarehouseID, &violation.StockID,
+			&violation.TransferID, &violation.Quantity, &violation.Reserved, &violation.Detail)
+		if err != nil {
+			slog.ErrorContext(ctx, "[reconciliationRepository] "+method, "scan", err)
+			return nil, err
+		}
+		violations = append(violations, violation)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[reconciliationRepository] "+method, "rowError", err)
+		return nil, err
+	}
+
+	return violations, nil
+}
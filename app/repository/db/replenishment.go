@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"warehouse-service/app/domain"
+)
+
+type replenishmentRuleRepository struct {
+	conn *sql.DB
+}
+
+func NewReplenishmentRuleRepository(db *sql.DB) domain.ReplenishmentRuleRepository {
+	return &replenishmentRuleRepository{db}
+}
+
+const replenishmentRuleColumns = `id, shop_id, product_id, warehouse_id, source_warehouse_id, min_level, max_level,
+	active, created_at, updated_at`
+
+func scanReplenishmentRule(row interface{ Scan(...any) error }) (domain.ReplenishmentRule, error) {
+	var rule domain.ReplenishmentRule
+	err := row.Scan(&rule.ID, &rule.ShopID, &rule.ProductID, &rule.WarehouseID, &rule.SourceWarehouseID,
+		&rule.MinLevel, &rule.MaxLevel, &rule.Active, &rule.CreatedAt, &rule.UpdatedAt)
+	return rule, err
+}
+
+func (r *replenishmentRuleRepository) Upsert(ctx context.Context, rule *domain.ReplenishmentRule) error {
+	query := `INSERT INTO replenishment_rules (shop_id, product_id, warehouse_id, source_warehouse_id, min_level, max_level, active)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (product_id, warehouse_id) DO UPDATE SET
+		source_warehouse_id = EXCLUDED.source_warehouse_id,
+		min_level = EXCLUDED.min_level,
+		max_level = EXCLUDED.max_level,
+		active = EXCLUDED.active,
+		updated_at = NOW()
+	RETURNING id, created_at, updated_at`
+	err := r.conn.QueryRowContext(ctx, query, rule.ShopID, rule.ProductID, rule.WarehouseID, rule.SourceWarehouseID,
+		rule.MinLevel, rule.MaxLevel, rule.Active).
+		Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentRuleRepository] Upsert", "queryRowContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *replenishmentRuleRepository) GetByID(ctx context.Context, id int64) (domain.ReplenishmentRule, error) {
+	query := `SELECT ` + replenishmentRuleColumns + ` FROM replenishment_rules WHERE id = $1`
+
+	rule, err := scanReplenishmentRule(r.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentRuleRepository] GetByID", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return rule, domain.ErrNotFound
+		}
+		return rule, err
+	}
+	return rule, nil
+}
+
+func (r *replenishmentRuleRepository) GetByProductIDAndWarehouseID(ctx context.Context, productID, warehouseID int64) (domain.ReplenishmentRule, error) {
+	query := `SELECT ` + replenishmentRuleColumns + ` FROM replenishment_rules WHERE product_id = $1 AND warehouse_id = $2`
+
+	rule, err := scanReplenishmentRule(r.conn.QueryRowContext(ctx, query, productID, warehouseID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return rule, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "[replenishmentRuleRepository] GetByProductIDAndWarehouseID", "queryRowContext", err)
+		return rule, err
+	}
+	return rule, nil
+}
+
+func (r *replenishmentRuleRepository) GetListRule(ctx context.Context, shopID int64, param domain.GetListReplenishmentRuleRequest) ([]domain.ReplenishmentRule, error) {
+	query := `SELECT ` + replenishmentRuleColumns + ` FROM replenishment_rules WHERE shop_id = $1`
+	args := []any{shopID}
+	placeholder := 2
+
+	if param.ProductID != 0 {
+		query += fmt.Sprintf(" AND product_id = $%d", placeholder)
+		args = append(args, param.ProductID)
+		placeholder++
+	}
+	if param.WarehouseID != 0 {
+		query += fmt.Sprintf(" AND warehouse_id = $%d", placeholder)
+		args = append(args, param.WarehouseID)
+		placeholder++
+	}
+
+	offset := (param.Page - 1) * param.Limit
+	query += fmt.Sprintf(" ORDER BY id ASC LIMIT $%d OFFSET $%d", placeholder, placeholder+1)
+	args = append(args, param.Limit, offset)
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentRuleRepository] GetListRule", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []domain.ReplenishmentRule
+	for rows.Next() {
+		rule, err := scanReplenishmentRule(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "[replenishmentRuleRepository] GetListRule", "scan", err)
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[replenishmentRuleRepository] GetListRule", "rowError", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *replenishmentRuleRepository) GetListRuleCount(ctx context.Context, shopID int64, param domain.GetListReplenishmentRuleRequest) (int64, error) {
+	query := `SELECT COUNT(*) FROM replenishment_rules WHERE shop_id = $1`
+	args := []any{shopID}
+	placeholder := 2
+
+	if param.ProductID != 0 {
+		query += fmt.Sprintf(" AND product_id = $%d", placeholder)
+		args = append(args, param.ProductID)
+		placeholder++
+	}
+	if param.WarehouseID != 0 {
+		query += fmt.Sprintf(" AND warehouse_id = $%d", placeholder)
+		args = append(args, param.WarehouseID)
+	}
+
+	var count int64
+	err := r.conn.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentRuleRepository] GetListRuleCount", "queryRowContext", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *replenishmentRuleRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM replenishment_rules WHERE id = $1`
+	_, err := r.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentRuleRepository] Delete", "execContext", err)
+		return err
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"log/slog"
+	"time"
 	"warehouse-service/app/domain"
 )
 
@@ -110,3 +111,26 @@ func (r *reservedStockRepository) GetTotalReservedStockByStockIDsAndStatus(ctx c
 
 	return result, nil
 }
+
+func (r *reservedStockRepository) GetActiveReservedStocksCreatedBefore(ctx context.Context, before time.Time, limit int64) ([]domain.ReservedStock, error) {
+	query := `SELECT id, stock_id, quantity, order_id, status, created_at, updated_at FROM reserved_stocks
+		WHERE status = $1 AND created_at < $2 ORDER BY created_at ASC LIMIT $3`
+	rows, err := r.conn.QueryContext(ctx, query, domain.ReservedStockStatusActive, before, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "[reservedStockRepository] GetActiveReservedStocksCreatedBefore", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservedStocks []domain.ReservedStock
+	for rows.Next() {
+		var reservedStock domain.ReservedStock
+		if err := rows.Scan(&reservedStock.ID, &reservedStock.StockID, &reservedStock.Quantity, &reservedStock.OrderID, &reservedStock.Status, &reservedStock.CreatedAt, &reservedStock.UpdatedAt); err != nil {
+			slog.ErrorContext(ctx, "[reservedStockRepository] GetActiveReservedStocksCreatedBefore", "scan", err)
+			return nil, err
+		}
+		reservedStocks = append(reservedStocks, reservedStock)
+	}
+
+	return reservedStocks, rows.Err()
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"strings"
 	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
 )
 
 type stockRepository struct {
@@ -128,13 +129,14 @@ func (r *stockRepository) GetAvailableStockByProductID(ctx context.Context, prod
 }
 
 func (r *stockRepository) WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	txCtx, runAfterCommit := ctxutil.WithAfterCommit(ctx)
 	tx, err := r.conn.BeginTx(ctx, nil)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockRepository] WithTransaction", "beginTx", err)
 		return err
 	}
 
-	if err := fn(ctx, tx); err != nil {
+	if err := fn(txCtx, tx); err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			slog.ErrorContext(ctx, "[stockRepository] WithTransaction", "rollback", rollbackErr)
 			return err
@@ -147,6 +149,7 @@ func (r *stockRepository) WithTransaction(ctx context.Context, fn func(context.C
 		return err
 	}
 
+	runAfterCommit(ctx)
 	return nil
 }
 
@@ -338,3 +341,69 @@ func (r *stockRepository) GetAvailableStockByProductIDs(ctx context.Context, pro
 
 	return availableStocks, nil
 }
+
+func (r *stockRepository) GetShopIDByProductID(ctx context.Context, productID int64) (int64, error) {
+	query := `SELECT w.shop_id FROM stocks s
+	JOIN warehouses w ON s.warehouse_id = w.id
+	WHERE s.product_id = $1 LIMIT 1`
+
+	var shopID int64
+	err := r.conn.QueryRowContext(ctx, query, productID).Scan(&shopID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockRepository] GetShopIDByProductID", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return 0, domain.ErrNotFound
+		}
+		return 0, err
+	}
+
+	return shopID, nil
+}
+
+func (r *stockRepository) GetProductAvailabilities(ctx context.Context, shopID, afterProductID, limit int64) ([]domain.ProductAvailability, error) {
+	// Reservations are pre-aggregated per stock row so quantities aren't
+	// multiplied by the number of reservations.
+	query := `SELECT s.product_id, MIN(w.shop_id),
+		COALESCE(SUM(s.quantity) FILTER (WHERE w.active), 0) - COALESCE(SUM(r.reserved) FILTER (WHERE w.active), 0)
+	FROM stocks s
+	JOIN warehouses w ON s.warehouse_id = w.id
+	LEFT JOIN (
+		SELECT stock_id, SUM(quantity) AS reserved FROM reserved_stocks WHERE status = 'active' GROUP BY stock_id
+	) r ON r.stock_id = s.id
+	WHERE s.product_id > $1`
+	args := []any{afterProductID}
+	placeholder := 2
+
+	if shopID != 0 {
+		query += fmt.Sprintf(" AND w.shop_id = $%d", placeholder)
+		args = append(args, shopID)
+		placeholder++
+	}
+
+	query += fmt.Sprintf(" GROUP BY s.product_id ORDER BY s.product_id ASC LIMIT $%d", placeholder)
+	args = append(args, limit)
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockRepository] GetProductAvailabilities", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var availabilities []domain.ProductAvailability
+	for rows.Next() {
+		var availability domain.ProductAvailability
+		if err := rows.Scan(&availability.ProductID, &availability.ShopID, &availability.Available); err != nil {
+			slog.ErrorContext(ctx, "[stockRepository] GetProductAvailabilities", "scan", err)
+			return nil, err
+		}
+		availabilities = append(availabilities, availability)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stockRepository] GetProductAvailabilities", "rowError", err)
+		return nil, err
+	}
+
+	return availabilities, nil
+}
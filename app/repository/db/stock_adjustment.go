@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"warehouse-service/app/domain"
+)
+
+type stockAdjustmentRepository struct {
+	conn *sql.DB
+}
+
+func NewStockAdjustmentRepository(db *sql.DB) domain.StockAdjustmentRepository {
+	return &stockAdjustmentRepository{db}
+}
+
+func (r *stockAdjustmentRepository) Create(ctx context.Context, adjustment *domain.StockAdjustment, tx *sql.Tx) error {
+	query := `INSERT INTO stock_adjustments (shop_id, stock_id, product_id, warehouse_id, delta, quantity_before, quantity_after, reason, note, user_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING id, created_at`
+	err := tx.QueryRowContext(ctx, query, adjustment.ShopID, adjustment.StockID, adjustment.ProductID, adjustment.WarehouseID,
+		adjustment.Delta, adjustment.QuantityBefore, adjustment.QuantityAfter, adjustment.Reason, adjustment.Note, adjustment.UserID).
+		Scan(&adjustment.ID, &adjustment.CreatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAdjustmentRepository] Create", "queryRowContext", err)
+		return err
+	}
+	return nil
+}
+
+// adjustmentFilter appends the conditions shared by the list and the report,
+// from and to are RFC 3339 timestamps.
+func adjustmentFilter(query string, args []interface{}, stockID, productID, warehouseID int64, reason domain.AdjustmentReason, from, to string) (string, []interface{}) {
+	if stockID != 0 {
+		args = append(args, stockID)
+		query += fmt.Sprintf(" AND stock_id = $%d", len(args))
+	}
+	if productID != 0 {
+		args = append(args, productID)
+		query += fmt.Sprintf(" AND product_id = $%d", len(args))
+	}
+	if warehouseID != 0 {
+		args = append(args, warehouseID)
+		query += fmt.Sprintf(" AND warehouse_id = $%d", len(args))
+	}
+	if reason != "" {
+		args = append(args, reason)
+		query += fmt.Sprintf(" AND reason = $%d", len(args))
+	}
+	if from != "" {
+		args = append(args, from)
+		query += fmt.Sprintf(" AND created_at >= $%d::timestamptz", len(args))
+	}
+	if to != "" {
+		args = append(args, to)
+		query += fmt.Sprintf(" AND created_at < $%d::timestamptz", len(args))
+	}
+	return query, args
+}
+
+func (r *stockAdjustmentRepository) GetListStockAdjustment(ctx context.Context, shopID int64, param domain.GetListStockAdjustmentRequest) ([]domain.StockAdjustment, error) {
+	query, args := adjustmentFilter(`SELECT id, shop_id, stock_id, product_id, warehouse_id, delta, quantity_before, quantity_after, reason, note, user_id, created_at
+	FROM stock_adjustments WHERE shop_id = $1`, []interface{}{shopID},
+		param.StockID, param.ProductID, param.WarehouseID, param.Reason, param.From, param.To)
+
+	offset := (param.Page - 1) * param.Limit
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, param.Limit, offset)
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAdjustmentRepository] GetListStockAdjustment", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustments := []domain.StockAdjustment{}
+	for rows.Next() {
+		var adjustment domain.StockAdjustment
+		var userID sql.NullInt64
+		err := rows.Scan(&adjustment.ID, &adjustment.ShopID, &adjustment.StockID, &adjustment.ProductID, &adjustment.WarehouseID,
+			&adjustment.Delta, &adjustment.QuantityBefore, &adjustment.QuantityAfter, &adjustment.Reason, &adjustment.Note,
+			&userID, &adjustment.CreatedAt)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockAdjustmentRepository] GetListStockAdjustment", "scan", err)
+			return nil, err
+		}
+		if userID.Valid {
+			adjustment.UserID = &userID.Int64
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stockAdjustmentRepository] GetListStockAdjustment", "rowError", err)
+		return nil, err
+	}
+
+	return adjustments, nil
+}
+
+func (r *stockAdjustmentRepository) GetListStockAdjustmentCount(ctx context.Context, shopID int64, param domain.GetListStockAdjustmentRequest) (int64, error) {
+	query, args := adjustmentFilter(`SELECT COUNT(*) FROM stock_adjustments WHERE shop_id = $1`, []interface{}{shopID},
+		param.StockID, param.ProductID, param.WarehouseID, param.Reason, param.From, param.To)
+
+	var count int64
+	err := r.conn.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAdjustmentRepository] GetListStockAdjustmentCount", "queryRowContext", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *stockAdjustmentRepository) GetTotalsByReason(ctx context.Context, shopID int64, param domain.StockAdjustmentReportRequest) ([]domain.StockAdjustmentReasonTotal, error) {
+	query, args := adjustmentFilter(`SELECT reason, COUNT(*),
+		COALESCE(SUM(delta) FILTER (WHERE delta > 0), 0),
+		COALESCE(-SUM(delta) FILTER (WHERE delta < 0), 0),
+		COALESCE(SUM(delta), 0)
+	FROM stock_adjustments WHERE shop_id = $1`, []interface{}{shopID},
+		0, param.ProductID, param.WarehouseID, "", param.From, param.To)
+	query += " GROUP BY reason ORDER BY reason ASC"
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAdjustmentRepository] GetTotalsByReason", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []domain.StockAdjustmentReasonTotal{}
+	for rows.Next() {
+		var total domain.StockAdjustmentReasonTotal
+		err := rows.Scan(&total.Reason, &total.Adjustments, &total.Increase, &total.Decrease, &total.Net)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockAdjustmentRepository] GetTotalsByReason", "scan", err)
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stockAdjustmentRepository] GetTotalsByReason", "rowError", err)
+		return nil, err
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"warehouse-service/app/domain"
+)
+
+type stockAlertRepository struct {
+	conn *sql.DB
+}
+
+func NewStockAlertRepository(db *sql.DB) domain.StockAlertRepository {
+	return &stockAlertRepository{db}
+}
+
+func (r *stockAlertRepository) UpsertThreshold(ctx context.Context, threshold *domain.StockAlertThreshold) error {
+	query := `INSERT INTO stock_alert_thresholds (shop_id, product_id, low_threshold) VALUES ($1, $2, $3)
+	ON CONFLICT (shop_id, product_id) DO UPDATE SET low_threshold = EXCLUDED.low_threshold, updated_at = NOW()
+	RETURNING id, created_at, updated_at`
+	err := r.conn.QueryRowContext(ctx, query, threshold.ShopID, threshold.ProductID, threshold.LowThreshold).
+		Scan(&threshold.ID, &threshold.CreatedAt, &threshold.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertRepository] UpsertThreshold", "queryRowContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stockAlertRepository) GetThresholdByID(ctx context.Context, id int64) (domain.StockAlertThreshold, error) {
+	query := `SELECT id, shop_id, product_id, low_threshold, created_at, updated_at
+	FROM stock_alert_thresholds WHERE id = $1`
+
+	var threshold domain.StockAlertThreshold
+	err := r.conn.QueryRowContext(ctx, query, id).Scan(&threshold.ID, &threshold.ShopID, &threshold.ProductID,
+		&threshold.LowThreshold, &threshold.CreatedAt, &threshold.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertRepository] GetThresholdByID", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return threshold, domain.ErrNotFound
+		}
+		return threshold, err
+	}
+	return threshold, nil
+}
+
+func (r *stockAlertRepository) GetThresholdsByShopID(ctx context.Context, shopID int64) ([]domain.StockAlertThreshold, error) {
+	query := `SELECT id, shop_id, product_id, low_threshold, created_at, updated_at
+	FROM stock_alert_thresholds WHERE shop_id = $1 ORDER BY product_id ASC`
+
+	rows, err := r.conn.QueryContext(ctx, query, shopID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertRepository] GetThresholdsByShopID", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var thresholds []domain.StockAlertThreshold
+	for rows.Next() {
+		var threshold domain.StockAlertThreshold
+		if err := rows.Scan(&threshold.ID, &threshold.ShopID, &threshold.ProductID,
+			&threshold.LowThreshold, &threshold.CreatedAt, &threshold.UpdatedAt); err != nil {
+			slog.ErrorContext(ctx, "[stockAlertRepository] GetThresholdsByShopID", "scan", err)
+			return nil, err
+		}
+		thresholds = append(thresholds, threshold)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stockAlertRepository] GetThresholdsByShopID", "rowError", err)
+		return nil, err
+	}
+	return thresholds, nil
+}
+
+func (r *stockAlertRepository) GetEffectiveThreshold(ctx context.Context, shopID, productID int64) (domain.StockAlertThreshold, error) {
+	query := `SELECT id, shop_id, product_id, low_threshold, created_at, updated_at
+	FROM stock_alert_thresholds WHERE shop_id = $1 AND product_id IN ($2, 0)
+	ORDER BY product_id DESC LIMIT 1`
+
+	var threshold domain.StockAlertThreshold
+	err := r.conn.QueryRowContext(ctx, query, shopID, productID).Scan(&threshold.ID, &threshold.ShopID, &threshold.ProductID,
+		&threshold.LowThreshold, &threshold.CreatedAt, &threshold.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return threshold, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "[stockAlertRepository] GetEffectiveThreshold", "queryRowContext", err)
+		return threshold, err
+	}
+	return threshold, nil
+}
+
+func (r *stockAlertRepository) DeleteThreshold(ctx context.Context, id int64) error {
+	query := `DELETE FROM stock_alert_thresholds WHERE id = $1`
+	_, err := r.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertRepository] DeleteThreshold", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stockAlertRepository) SwapState(ctx context.Context, shopID, productID int64, state domain.StockAlertState) (domain.StockAlertState, bool, error) {
+	// The CTE reads the row before the upsert touches it, so RETURNING yields
+	// the previous state. No row is returned when the state is unchanged.
+	query := `WITH prev AS (
+		SELECT state FROM stock_alert_states WHERE product_id = $1 FOR UPDATE
+	)
+	INSERT INTO stock_alert_states (product_id, shop_id, state) VALUES ($1, $2, $3)
+	ON CONFLICT (product_id) DO UPDATE SET state = EXCLUDED.state, updated_at = NOW()
+	WHERE stock_alert_states.state <> EXCLUDED.state
+	RETURNING (SELECT state FROM prev)`
+
+	var previous sql.NullString
+	err := r.conn.QueryRowContext(ctx, query, productID, shopID, state).Scan(&previous)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return state, false, nil
+		}
+		slog.ErrorContext(ctx, "[stockAlertRepository] SwapState", "queryRowContext", err)
+		return "", false, err
+	}
+
+	if !previous.Valid {
+		return domain.StockAlertStateOK, state != domain.StockAlertStateOK, nil
+	}
+	return domain.StockAlertState(previous.String), true, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
 )
 
 type stockTransferRepository struct {
@@ -16,10 +17,10 @@ func NewStockTransferRepository(db *sql.DB) domain.StockTransferRepository {
 	return &stockTransferRepository{db}
 }
 
-func (r *stockTransferRepository) Create(ctx context.Context, data *domain.StockTransfer) error {
+func (r *stockTransferRepository) Create(ctx context.Context, data *domain.StockTransfer, tx *sql.Tx) error {
 	query := `INSERT INTO stock_transfers (product_id, from_warehouse, to_warehouse, quantity, description)
 	VALUES ($1, $2, $3, $4, $5) Returning id, created_at, updated_at`
-	err := r.conn.QueryRowContext(ctx, query, data.ProductID, data.FromWarehouse, data.ToWarehouse, data.Quantity, data.Description).
+	err := tx.QueryRowContext(ctx, query, data.ProductID, data.FromWarehouse, data.ToWarehouse, data.Quantity, data.Description).
 		Scan(&data.ID, &data.CreatedAt, &data.UpdatedAt)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockTransferRepository] Create", "queryRowContext", err)
@@ -48,6 +49,25 @@ func (r *stockTransferRepository) GetByID(ctx context.Context, id int64) (domain
 	return stockTransfer, nil
 }
 
+func (r *stockTransferRepository) LockForUpdate(ctx context.Context, id int64, tx *sql.Tx) (domain.StockTransfer, error) {
+	query := `SELECT id, product_id, from_warehouse, to_warehouse, quantity, status, description, created_at, updated_at
+	FROM stock_transfers WHERE id = $1 FOR UPDATE`
+
+	var stockTransfer domain.StockTransfer
+	err := tx.QueryRowContext(ctx, query, id).Scan(&stockTransfer.ID, &stockTransfer.ProductID,
+		&stockTransfer.FromWarehouse, &stockTransfer.ToWarehouse, &stockTransfer.Quantity,
+		&stockTransfer.Status, &stockTransfer.Description, &stockTransfer.CreatedAt, &stockTransfer.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferRepository] LockForUpdate", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return stockTransfer, domain.ErrNotFound
+		}
+		return stockTransfer, err
+	}
+
+	return stockTransfer, nil
+}
+
 func (r *stockTransferRepository) UpdateStatus(ctx context.Context, st domain.StockTransfer, tx *sql.Tx) error {
 	query := `UPDATE stock_transfers SET status = $1, description = $2, updated_at = now() WHERE id = $3`
 	_, err := tx.ExecContext(ctx, query, st.Status, st.Description, st.ID)
@@ -59,13 +79,14 @@ func (r *stockTransferRepository) UpdateStatus(ctx context.Context, st domain.St
 }
 
 func (r *stockTransferRepository) WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	txCtx, runAfterCommit := ctxutil.WithAfterCommit(ctx)
 	tx, err := r.conn.BeginTx(ctx, nil)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockTransferRepository] WithTransaction", "beginTx", err)
 		return err
 	}
 
-	if err := fn(ctx, tx); err != nil {
+	if err := fn(txCtx, tx); err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			slog.ErrorContext(ctx, "[stockTransferRepository] WithTransaction", "rollback", rollbackErr)
 		}
@@ -77,6 +98,7 @@ func (r *stockTransferRepository) WithTransaction(ctx context.Context, fn func(c
 		return err
 	}
 
+	runAfterCommit(ctx)
 	return nil
 }
 
@@ -151,3 +173,16 @@ func (r *stockTransferRepository) GetListStockTransferCount(ctx context.Context,
 	}
 	return count, nil
 }
+
+func (r *stockTransferRepository) GetOpenTransferCount(ctx context.Context, productID, toWarehouse int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM stock_transfers
+	WHERE product_id = $1 AND to_warehouse = $2 AND status IN ('not_started', 'in_progress')`
+
+	var count int64
+	err := r.conn.QueryRowContext(ctx, query, productID, toWarehouse).Scan(&count)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferRepository] GetOpenTransferCount", "queryRowContext", err)
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+)
+
+type stockTransferScheduleRepository struct {
+	conn *sql.DB
+}
+
+func NewStockTransferScheduleRepository(db *sql.DB) domain.StockTransferScheduleRepository {
+	return &stockTransferScheduleRepository{db}
+}
+
+const stockTransferScheduleColumns = `id, shop_id, product_id, from_warehouse, to_warehouse, quantity, top_up_to,
+	recurrence, description, scheduled_at, day_of_month, active, last_run_at, created_at, updated_at`
+
+func scanStockTransferSchedule(row interface{ Scan(...any) error }) (domain.StockTransferSchedule, error) {
+	var schedule domain.StockTransferSchedule
+	var lastRunAt sql.NullTime
+	err := row.Scan(&schedule.ID, &schedule.ShopID, &schedule.ProductID, &schedule.FromWarehouse,
+		&schedule.ToWarehouse, &schedule.Quantity, &schedule.TopUpTo, &schedule.Recurrence,
+		&schedule.Description, &schedule.ScheduledAt, &schedule.DayOfMonth, &schedule.Active, &lastRunAt,
+		&schedule.CreatedAt, &schedule.UpdatedAt)
+	if lastRunAt.Valid {
+		schedule.LastRunAt = &lastRunAt.Time
+	}
+	return schedule, err
+}
+
+func (r *stockTransferScheduleRepository) Create(ctx context.Context, schedule *domain.StockTransferSchedule) error {
+	query := `INSERT INTO stock_transfer_schedules (shop_id, product_id, from_warehouse, to_warehouse, quantity, top_up_to,
+		recurrence, description, scheduled_at, day_of_month, active)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at, updated_at`
+	err := r.conn.QueryRowContext(ctx, query, schedule.ShopID, schedule.ProductID, schedule.FromWarehouse,
+		schedule.ToWarehouse, schedule.Quantity, schedule.TopUpTo, schedule.Recurrence, schedule.Description,
+		schedule.ScheduledAt, schedule.DayOfMonth, schedule.Active).
+		Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] Create", "queryRowContext", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *stockTransferScheduleRepository) GetByID(ctx context.Context, id int64) (domain.StockTransferSchedule, error) {
+	query := `SELECT ` + stockTransferScheduleColumns + ` FROM stock_transfer_schedules WHERE id = $1`
+
+	schedule, err := scanStockTransferSchedule(r.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetByID", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return schedule, domain.ErrNotFound
+		}
+		return schedule, err
+	}
+
+	return schedule, nil
+}
+
+func (r *stockTransferScheduleRepository) ClaimDue(ctx context.Context, now time.Time, skip []int64, tx *sql.Tx) (domain.StockTransferSchedule, error) {
+	query := `SELECT ` + stockTransferScheduleColumns + ` FROM stock_transfer_schedules
+	WHERE active = TRUE AND scheduled_at <= $1 AND id <> ALL($2)
+	ORDER BY scheduled_at ASC LIMIT 1
+	FOR UPDATE SKIP LOCKED`
+
+	schedule, err := scanStockTransferSchedule(tx.QueryRowContext(ctx, query, now, skip))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return schedule, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] ClaimDue", "queryRowContext", err)
+		return schedule, err
+	}
+
+	return schedule, nil
+}
+
+func (r *stockTransferScheduleRepository) GetListSchedule(ctx context.Context, shopID int64, param domain.GetListStockTransferScheduleRequest) ([]domain.StockTransferSchedule, error) {
+	query := `SELECT ` + stockTransferScheduleColumns + ` FROM stock_transfer_schedules WHERE shop_id = $1`
+	args := []interface{}{shopID}
+	placeholder := 2
+
+	if param.Active != nil {
+		query += fmt.Sprintf(" AND active = $%d", placeholder)
+		args = append(args, *param.Active)
+		placeholder++
+	}
+
+	if param.SortBy != "" {
+		query += fmt.Sprintf(" ORDER BY %s", param.SortBy)
+		if param.SortOrder != "" {
+			query += fmt.Sprintf(" %s", param.SortOrder)
+		}
+	} else {
+		query += ` ORDER BY scheduled_at ASC`
+	}
+
+	offset := (param.Page - 1) * param.Limit
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", placeholder, placeholder+1)
+	args = append(args, param.Limit, offset)
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetListSchedule", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []domain.StockTransferSchedule
+	for rows.Next() {
+		schedule, err := scanStockTransferSchedule(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetListSchedule", "scan", err)
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetListSchedule", "rowError", err)
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (r *stockTransferScheduleRepository) GetListScheduleCount(ctx context.Context, shopID int64, param domain.GetListStockTransferScheduleRequest) (int64, error) {
+	query := `SELECT COUNT(*) FROM stock_transfer_schedules WHERE shop_id = $1`
+	args := []interface{}{shopID}
+
+	if param.Active != nil {
+		query += ` AND active = $2`
+		args = append(args, *param.Active)
+	}
+
+	var count int64
+	err := r.conn.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetListScheduleCount", "queryRowContext", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *stockTransferScheduleRepository) UpdateNextRun(ctx context.Context, schedule domain.StockTransferSchedule, tx *sql.Tx) error {
+	query := `UPDATE stock_transfer_schedules SET scheduled_at = $1, active = $2, last_run_at = $3, updated_at = NOW() WHERE id = $4`
+	_, err := tx.ExecContext(ctx, query, schedule.ScheduledAt, schedule.Active, schedule.LastRunAt, schedule.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] UpdateNextRun", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stockTransferScheduleRepository) UpdateActive(ctx context.Context, id int64, active bool) error {
+	query := `UPDATE stock_transfer_schedules SET active = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.conn.ExecContext(ctx, query, active, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] UpdateActive", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stockTransferScheduleRepository) CreateRun(ctx context.Context, run *domain.StockTransferScheduleRun, tx *sql.Tx) error {
+	query := `INSERT INTO stock_transfer_schedule_runs (schedule_id, transfer_id, quantity, status, message, run_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := tx.QueryRowContext(ctx, query, run.ScheduleID, run.TransferID, run.Quantity, run.Status, run.Message, run.RunAt).
+		Scan(&run.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] CreateRun", "queryRowContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stockTransferScheduleRepository) GetRunsByScheduleID(ctx context.Context, scheduleID int64, limit int64) ([]domain.StockTransferScheduleRun, error) {
+	query := `SELECT id, schedule_id, transfer_id, quantity, status, message, run_at
+	FROM stock_transfer_schedule_runs WHERE schedule_id = $1 ORDER BY run_at DESC LIMIT $2`
+
+	rows, err := r.conn.QueryContext(ctx, query, scheduleID, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetRunsByScheduleID", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []domain.StockTransferScheduleRun
+	for rows.Next() {
+		var run domain.StockTransferScheduleRun
+		var transferID sql.NullInt64
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &transferID, &run.Quantity,
+			&run.Status, &run.Message, &run.RunAt); err != nil {
+			slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetRunsByScheduleID", "scan", err)
+			return nil, err
+		}
+		if transferID.Valid {
+			run.TransferID = &transferID.Int64
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] GetRunsByScheduleID", "rowError", err)
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (r *stockTransferScheduleRepository) WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	txCtx, runAfterCommit := ctxutil.WithAfterCommit(ctx)
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] WithTransaction", "beginTx", err)
+		return err
+	}
+
+	if err := fn(txCtx, tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			slog.ErrorContext(ctx, "[stockTransferScheduleRepository] WithTransaction", "rollback", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleRepository] WithTransaction", "commit", err)
+		return err
+	}
+
+	runAfterCommit(ctx)
+	return nil
+}
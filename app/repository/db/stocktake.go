@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+)
+
+type stocktakeRepository struct {
+	conn *sql.DB
+}
+
+func NewStocktakeRepository(db *sql.DB) domain.StocktakeRepository {
+	return &stocktakeRepository{db}
+}
+
+const stocktakeColumns = `id, shop_id, warehouse_id, status, note, opened_by, submitted_by, submitted_at,
+	closed_by, closed_at, created_at, updated_at`
+
+func scanStocktake(row interface{ Scan(...any) error }) (domain.Stocktake, error) {
+	var stocktake domain.Stocktake
+	var submittedBy, closedBy sql.NullInt64
+	var submittedAt, closedAt sql.NullTime
+	err := row.Scan(&stocktake.ID, &stocktake.ShopID, &stocktake.WarehouseID, &stocktake.Status, &stocktake.Note,
+		&stocktake.OpenedBy, &submittedBy, &submittedAt, &closedBy, &closedAt, &stocktake.CreatedAt, &stocktake.UpdatedAt)
+	if submittedBy.Valid {
+		stocktake.SubmittedBy = &submittedBy.Int64
+	}
+	if submittedAt.Valid {
+		stocktake.SubmittedAt = &submittedAt.Time
+	}
+	if closedBy.Valid {
+		stocktake.ClosedBy = &closedBy.Int64
+	}
+	if closedAt.Valid {
+		stocktake.ClosedAt = &closedAt.Time
+	}
+	return stocktake, err
+}
+
+func (r *stocktakeRepository) Create(ctx context.Context, stocktake *domain.Stocktake, tx *sql.Tx) error {
+	// The check and the partial unique index keep one stocktake in progress
+	// per warehouse
+	query := `INSERT INTO stocktakes (shop_id, warehouse_id, status, note, opened_by)
+	SELECT $1, $2, $3, $4, $5
+	WHERE NOT EXISTS (SELECT 1 FROM stocktakes WHERE warehouse_id = $2 AND status IN ('open', 'submitted'))
+	RETURNING id, created_at, updated_at`
+	err := tx.QueryRowContext(ctx, query, stocktake.ShopID, stocktake.WarehouseID, stocktake.Status, stocktake.Note, stocktake.OpenedBy).
+		Scan(&stocktake.ID, &stocktake.CreatedAt, &stocktake.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] Create", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return domain.ErrStocktakeAlreadyOpen
+		}
+		return err
+	}
+
+	// Freeze the system quantities the counts are compared against
+	query = `INSERT INTO stocktake_lines (stocktake_id, stock_id, product_id, system_quantity)
+	SELECT $1, id, product_id, quantity FROM stocks WHERE warehouse_id = $2`
+	_, err = tx.ExecContext(ctx, query, stocktake.ID, stocktake.WarehouseID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] Create", "insertLines", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *stocktakeRepository) GetByID(ctx context.Context, id int64) (domain.Stocktake, error) {
+	query := `SELECT ` + stocktakeColumns + ` FROM stocktakes WHERE id = $1`
+
+	stocktake, err := scanStocktake(r.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] GetByID", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return stocktake, domain.ErrNotFound
+		}
+		return stocktake, err
+	}
+
+	return stocktake, nil
+}
+
+func (r *stocktakeRepository) GetLines(ctx context.Context, stocktakeID int64) ([]domain.StocktakeLine, error) {
+	query := `SELECT id, stocktake_id, stock_id, product_id, system_quantity, counted_quantity, counted_by, counted_at, applied_quantity
+	FROM stocktake_lines WHERE stocktake_id = $1 ORDER BY product_id ASC`
+
+	rows, err := r.conn.QueryContext(ctx, query, stocktakeID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] GetLines", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []domain.StocktakeLine{}
+	for rows.Next() {
+		var line domain.StocktakeLine
+		var countedQuantity, countedBy, appliedQuantity sql.NullInt64
+		var countedAt sql.NullTime
+		err := rows.Scan(&line.ID, &line.StocktakeID, &line.StockID, &line.ProductID, &line.SystemQuantity,
+			&countedQuantity, &countedBy, &countedAt, &appliedQuantity)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stocktakeRepository] GetLines", "scan", err)
+			return nil, err
+		}
+		if countedQuantity.Valid {
+			variance := countedQuantity.Int64 - line.SystemQuantity
+			line.CountedQuantity = &countedQuantity.Int64
+			line.Variance = &variance
+		}
+		if countedBy.Valid {
+			line.CountedBy = &countedBy.Int64
+		}
+		if countedAt.Valid {
+			line.CountedAt = &countedAt.Time
+		}
+		if appliedQuantity.Valid {
+			line.AppliedQuantity = &appliedQuantity.Int64
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] GetLines", "rowError", err)
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func (r *stocktakeRepository) GetListStocktake(ctx context.Context, shopID int64, param domain.GetListStocktakeRequest) ([]domain.Stocktake, error) {
+	query := `SELECT ` + stocktakeColumns + ` FROM stocktakes WHERE shop_id = $1`
+	args := []interface{}{shopID}
+	placeholder := 2
+
+	if param.WarehouseID != 0 {
+		query += fmt.Sprintf(" AND warehouse_id = $%d", placeholder)
+		args = append(args, param.WarehouseID)
+		placeholder++
+	}
+	if param.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", placeholder)
+		args = append(args, param.Status)
+		placeholder++
+	}
+
+	offset := (param.Page - 1) * param.Limit
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", placeholder, placeholder+1)
+	args = append(args, param.Limit, offset)
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] GetListStocktake", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocktakes []domain.Stocktake
+	for rows.Next() {
+		stocktake, err := scanStocktake(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stocktakeRepository] GetListStocktake", "scan", err)
+			return nil, err
+		}
+		stocktakes = append(stocktakes, stocktake)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] GetListStocktake", "rowError", err)
+		return nil, err
+	}
+
+	return stocktakes, nil
+}
+
+func (r *stocktakeRepository) GetListStocktakeCount(ctx context.Context, shopID int64, param domain.GetListStocktakeRequest) (int64, error) {
+	query := `SELECT COUNT(*) FROM stocktakes WHERE shop_id = $1`
+	args := []interface{}{shopID}
+	placeholder := 2
+
+	if param.WarehouseID != 0 {
+		query += fmt.Sprintf(" AND warehouse_id = $%d", placeholder)
+		args = append(args, param.WarehouseID)
+		placeholder++
+	}
+	if param.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", placeholder)
+		args = append(args, param.Status)
+	}
+
+	var count int64
+	err := r.conn.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] GetListStocktakeCount", "queryRowContext", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *stocktakeRepository) LockForUpdate(ctx context.Context, id int64, tx *sql.Tx) (domain.Stocktake, error) {
+	query := `SELECT ` + stocktakeColumns + ` FROM stocktakes WHERE id = $1 FOR UPDATE`
+
+	stocktake, err := scanStocktake(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] LockForUpdate", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return stocktake, domain.ErrNotFound
+		}
+		return stocktake, err
+	}
+
+	return stocktake, nil
+}
+
+func (r *stocktakeRepository) UpdateCount(ctx context.Context, stocktakeID int64, count domain.StocktakeCount, userID int64, tx *sql.Tx) (bool, error) {
+	query := `UPDATE stocktake_lines SET counted_quantity = $1, counted_by = $2, counted_at = NOW()
+	WHERE stocktake_id = $3 AND product_id = $4`
+	res, err := tx.ExecContext(ctx, query, count.CountedQuantity, userID, stocktakeID, count.ProductID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] UpdateCount", "execContext", err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] UpdateCount", "rowsAffected", err)
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *stocktakeRepository) UpdateStatus(ctx context.Context, stocktake domain.Stocktake, tx *sql.Tx) error {
+	query := `UPDATE stocktakes SET status = $1, submitted_by = $2, submitted_at = $3, closed_by = $4, closed_at = $5, updated_at = NOW()
+	WHERE id = $6`
+	_, err := tx.ExecContext(ctx, query, stocktake.Status, stocktake.SubmittedBy, stocktake.SubmittedAt,
+		stocktake.ClosedBy, stocktake.ClosedAt, stocktake.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] UpdateStatus", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stocktakeRepository) UpdateAppliedQuantity(ctx context.Context, lineID, quantity int64, tx *sql.Tx) error {
+	query := `UPDATE stocktake_lines SET applied_quantity = $1 WHERE id = $2`
+	_, err := tx.ExecContext(ctx, query, quantity, lineID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] UpdateAppliedQuantity", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *stocktakeRepository) WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	txCtx, runAfterCommit := ctxutil.WithAfterCommit(ctx)
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] WithTransaction", "beginTx", err)
+		return err
+	}
+
+	if err := fn(txCtx, tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			slog.ErrorContext(ctx, "[stocktakeRepository] WithTransaction", "rollback", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "[stocktakeRepository] WithTransaction", "commit", err)
+		return err
+	}
+
+	runAfterCommit(ctx)
+	return nil
+}
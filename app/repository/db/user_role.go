@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"warehouse-service/app/domain"
+)
+
+type userRoleRepository struct {
+	conn *sql.DB
+}
+
+func NewUserRoleRepository(db *sql.DB) domain.UserRoleRepository {
+	return &userRoleRepository{db}
+}
+
+func (r *userRoleRepository) Upsert(ctx context.Context, userRole *domain.UserRole) error {
+	query := `INSERT INTO shop_user_roles (shop_id, user_id, role) VALUES ($1, $2, $3)
+	ON CONFLICT (shop_id, user_id) DO UPDATE SET role = EXCLUDED.role, updated_at = NOW()
+	RETURNING created_at, updated_at`
+	err := r.conn.QueryRowContext(ctx, query, userRole.ShopID, userRole.UserID, userRole.Role).
+		Scan(&userRole.CreatedAt, &userRole.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleRepository] Upsert", "queryRowContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *userRoleRepository) GetByShopIDAndUserID(ctx context.Context, shopID, userID int64) (domain.UserRole, error) {
+	query := `SELECT shop_id, user_id, role, created_at, updated_at
+	FROM shop_user_roles WHERE shop_id = $1 AND user_id = $2`
+
+	var userRole domain.UserRole
+	err := r.conn.QueryRowContext(ctx, query, shopID, userID).Scan(&userRole.ShopID, &userRole.UserID,
+		&userRole.Role, &userRole.CreatedAt, &userRole.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return userRole, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "[userRoleRepository] GetByShopIDAndUserID", "queryRowContext", err)
+		return userRole, err
+	}
+	return userRole, nil
+}
+
+func (r *userRoleRepository) GetByShopID(ctx context.Context, shopID int64) ([]domain.UserRole, error) {
+	query := `SELECT shop_id, user_id, role, created_at, updated_at
+	FROM shop_user_roles WHERE shop_id = $1 ORDER BY user_id ASC`
+
+	rows, err := r.conn.QueryContext(ctx, query, shopID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleRepository] GetByShopID", "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userRoles []domain.UserRole
+	for rows.Next() {
+		var userRole domain.UserRole
+		if err := rows.Scan(&userRole.ShopID, &userRole.UserID, &userRole.Role,
+			&userRole.CreatedAt, &userRole.UpdatedAt); err != nil {
+			slog.ErrorContext(ctx, "[userRoleRepository] GetByShopID", "scan", err)
+			return nil, err
+		}
+		userRoles = append(userRoles, userRole)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[userRoleRepository] GetByShopID", "rowError", err)
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+func (r *userRoleRepository) ExistsByShopID(ctx context.Context, shopID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM shop_user_roles WHERE shop_id = $1)`
+
+	var exists bool
+	err := r.conn.QueryRowContext(ctx, query, shopID).Scan(&exists)
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleRepository] ExistsByShopID", "queryRowContext", err)
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *userRoleRepository) Delete(ctx context.Context, shopID, userID int64) error {
+	query := `DELETE FROM shop_user_roles WHERE shop_id = $1 AND user_id = $2`
+	result, err := r.conn.ExecContext(ctx, query, shopID, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleRepository] Delete", "execContext", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleRepository] Delete", "rowsAffected", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
 )
 
 type warehouseRepository struct {
@@ -162,13 +163,14 @@ func (r *warehouseRepository) UpdateStatus(ctx context.Context, id int64, active
 }
 
 func (r *warehouseRepository) WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	txCtx, runAfterCommit := ctxutil.WithAfterCommit(ctx)
 	tx, err := r.conn.BeginTx(ctx, nil)
 	if err != nil {
 		slog.ErrorContext(ctx, "[warehouseRepository] WithTransaction", "beginTx", err)
 		return err
 	}
 
-	if err := fn(ctx, tx); err != nil {
+	if err := fn(txCtx, tx); err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			slog.ErrorContext(ctx, "[warehouseRepository] WithTransaction", "rollback", rollbackErr)
 		}
@@ -179,5 +181,7 @@ func (r *warehouseRepository) WithTransaction(ctx context.Context, fn func(conte
 		slog.ErrorContext(ctx, "[warehouseRepository] WithTransaction", "commit", err)
 		return err
 	}
+
+	runAfterCommit(ctx)
 	return nil
 }
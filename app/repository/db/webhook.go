@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"warehouse-service/app/domain"
+)
+
+type webhookRepository struct {
+	conn *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) domain.WebhookRepository {
+	return &webhookRepository{db}
+}
+
+const (
+	webhookColumns         = `id, shop_id, url, secret, event_types, active, created_at, updated_at`
+	webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, last_status_code,
+	last_error, next_attempt_at, delivered_at, created_at, updated_at`
+)
+
+func scanWebhook(row interface{ Scan(...any) error }) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	var eventTypes string
+	err := row.Scan(&webhook.ID, &webhook.ShopID, &webhook.URL, &webhook.Secret, &eventTypes,
+		&webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if eventTypes != "" {
+		webhook.EventTypes = strings.Split(eventTypes, ",")
+	}
+	return webhook, err
+}
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var payload string
+	var deliveredAt sql.NullTime
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &payload,
+		&delivery.Status, &delivery.Attempts, &delivery.LastStatusCode, &delivery.LastError,
+		&delivery.NextAttemptAt, &deliveredAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	delivery.Payload = []byte(payload)
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return delivery, err
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	query := `INSERT INTO webhooks (shop_id, url, secret, event_types, active) VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at, updated_at`
+	err := r.conn.QueryRowContext(ctx, query, webhook.ShopID, webhook.URL, webhook.Secret,
+		strings.Join(webhook.EventTypes, ","), webhook.Active).
+		Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] Create", "queryRowContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id int64) (domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+
+	webhook, err := scanWebhook(r.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] GetByID", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return webhook, domain.ErrNotFound
+		}
+		return webhook, err
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepository) GetByShopID(ctx context.Context, shopID int64) ([]domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE shop_id = $1 ORDER BY id ASC`
+	return r.queryWebhooks(ctx, "GetByShopID", query, shopID)
+}
+
+func (r *webhookRepository) GetActiveByShopIDAndEventType(ctx context.Context, shopID int64, eventType string) ([]domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks
+	WHERE shop_id = $1 AND active = TRUE AND $2 = ANY(string_to_array(event_types, ','))`
+	return r.queryWebhooks(ctx, "GetActiveByShopIDAndEventType", query, shopID, eventType)
+}
+
+func (r *webhookRepository) queryWebhooks(ctx context.Context, method, query string, args ...any) ([]domain.Webhook, error) {
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] "+method, "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []domain.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "[webhookRepository] "+method, "scan", err)
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] "+method, "rowError", err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM webhooks WHERE id = $1`
+	_, err := r.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] Delete", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	valuePlaceholders := []string{}
+	valueArgs := []interface{}{}
+	for i, delivery := range deliveries {
+		valuePlaceholders = append(valuePlaceholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i*5+1, i*5+2, i*5+3, i*5+4, i*5+5))
+		valueArgs = append(valueArgs, delivery.WebhookID, delivery.EventID, delivery.EventType, string(delivery.Payload), delivery.NextAttemptAt)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at) VALUES %s`,
+		strings.Join(valuePlaceholders, ", "))
+
+	_, err := r.conn.ExecContext(ctx, query, valueArgs...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] CreateDeliveries", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDeliveryByID(ctx context.Context, id int64) (domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	delivery, err := scanWebhookDelivery(r.conn.QueryRowContext(ctx, query, id))
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] GetDeliveryByID", "queryRowContext", err)
+		if err == sql.ErrNoRows {
+			return delivery, domain.ErrNotFound
+		}
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (r *webhookRepository) GetListDelivery(ctx context.Context, webhookID int64, param domain.GetListWebhookDeliveryRequest) ([]domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = $1`
+	args := []any{webhookID}
+	placeholder := 2
+
+	if param.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", placeholder)
+		args = append(args, param.Status)
+		placeholder++
+	}
+
+	offset := (param.Page - 1) * param.Limit
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", placeholder, placeholder+1)
+	args = append(args, param.Limit, offset)
+
+	return r.queryDeliveries(ctx, "GetListDelivery", query, args...)
+}
+
+func (r *webhookRepository) GetListDeliveryCount(ctx context.Context, webhookID int64, param domain.GetListWebhookDeliveryRequest) (int64, error) {
+	query := `SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = $1`
+	args := []any{webhookID}
+
+	if param.Status != "" {
+		query += ` AND status = $2`
+		args = append(args, param.Status)
+	}
+
+	var count int64
+	err := r.conn.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] GetListDeliveryCount", "queryRowContext", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int64) ([]domain.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries SET next_attempt_at = $2, updated_at = NOW()
+	WHERE id IN (
+		SELECT id FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at ASC LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + webhookDeliveryColumns
+
+	return r.queryDeliveries(ctx, "ClaimDueDeliveries", query, now, leaseUntil, limit)
+}
+
+func (r *webhookRepository) queryDeliveries(ctx context.Context, method, query string, args ...any) ([]domain.WebhookDelivery, error) {
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] "+method, "queryContext", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "[webhookRepository] "+method, "scan", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] "+method, "rowError", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries SET status = $1, attempts = $2, last_status_code = $3, last_error = $4,
+	next_attempt_at = $5, delivered_at = $6, updated_at = NOW() WHERE id = $7`
+	_, err := r.conn.ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.LastStatusCode,
+		delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] UpdateDelivery", "execContext", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) ResetDelivery(ctx context.Context, id int64) error {
+	query := `UPDATE webhook_deliveries SET status = 'pending', attempts = 0, last_error = '',
+	next_attempt_at = NOW(), updated_at = NOW() WHERE id = $1`
+	_, err := r.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookRepository] ResetDelivery", "execContext", err)
+		return err
+	}
+	return nil
+}
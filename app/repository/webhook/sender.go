@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg"
+)
+
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+var errAddressNotAllowed = errors.New("webhook address is not a public address")
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender returns a sender that only connects to public addresses. The
+// check runs on the resolved IP at dial time, so a host that resolves to an
+// internal address after the webhook was registered is still refused.
+func NewHTTPSender(timeout time.Duration) domain.WebhookSender {
+	return newHTTPSender(timeout, publicAddressOnly)
+}
+
+func newHTTPSender(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *httpSender {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	return &httpSender{client: &http.Client{
+		Timeout: timeout,
+		// No proxy, it would be dialed instead of the webhook host
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		// Redirects are reported as a failed delivery instead of followed
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func publicAddressOnly(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !pkg.IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", errAddressNotAllowed, host)
+	}
+	return nil
+}
+
+// Send signs "<timestamp>.<body>" with the webhook secret so receivers can
+// verify the payload and reject replays outside their tolerance window.
+func (s *httpSender) Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signed := append([]byte(timestamp+"."), delivery.Payload...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookSender] Send", "newRequest", err)
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "warehouse-service-webhook")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+pkg.SignHMACSHA256(webhook.Secret, signed))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.EventID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg"
+)
+
+var testDelivery = domain.WebhookDelivery{
+	ID:        10,
+	WebhookID: 1,
+	EventID:   "evt-1",
+	EventType: domain.WebhookEventStockAvailabilityChanged,
+	Payload:   []byte(`{"id":"evt-1"}`),
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		timestamp := r.Header.Get(HeaderTimestamp)
+		want := "sha256=" + pkg.SignHMACSHA256("s3cret", append([]byte(timestamp+"."), body...))
+		if got := r.Header.Get(HeaderSignature); !hmac.Equal([]byte(got), []byte(want)) {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+		if got := r.Header.Get(HeaderEvent); got != domain.WebhookEventStockAvailabilityChanged {
+			t.Errorf("event header = %q", got)
+		}
+		if got := r.Header.Get(HeaderDelivery); got != "evt-1" {
+			t.Errorf("delivery header = %q", got)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	// the test server listens on loopback
+	sender := newHTTPSender(5*time.Second, func(network, address string, c syscall.RawConn) error { return nil })
+
+	status, err := sender.Send(context.Background(), domain.Webhook{ID: 1, URL: server.URL, Secret: "s3cret"}, testDelivery)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v, want 204", status, err)
+	}
+}
+
+func TestSendRefusesInternalAddresses(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL, http.StatusTemporaryRedirect)
+	}))
+	t.Cleanup(redirect.Close)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"loopback", server.URL},
+		{"localhost name", "http://localhost:1/"},
+	}
+
+	sender := NewHTTPSender(5 * time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sender.Send(context.Background(), domain.Webhook{ID: 1, URL: tt.url, Secret: "s3cret"}, testDelivery)
+			if !errors.Is(err, errAddressNotAllowed) {
+				t.Fatalf("err = %v, want errAddressNotAllowed", err)
+			}
+		})
+	}
+
+	t.Run("redirect is not followed", func(t *testing.T) {
+		sender := newHTTPSender(5*time.Second, func(network, address string, c syscall.RawConn) error { return nil })
+		status, err := sender.Send(context.Background(), domain.Webhook{ID: 1, URL: redirect.URL, Secret: "s3cret"}, testDelivery)
+		if err == nil || status != http.StatusTemporaryRedirect {
+			t.Fatalf("Send = %d, %v, want a failed 307", status, err)
+		}
+	})
+
+	if calls.Load() != 0 {
+		t.Fatalf("internal server was called %d times", calls.Load())
+	}
+}
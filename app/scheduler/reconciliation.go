@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/lifecycle"
+)
+
+type ReconciliationScheduler struct {
+	usecase    domain.ReconciliationUsecase
+	interval   time.Duration
+	autoRepair bool
+}
+
+func NewReconciliationScheduler(usecase domain.ReconciliationUsecase, interval time.Duration, autoRepair bool) *ReconciliationScheduler {
+	return &ReconciliationScheduler{usecase, interval, autoRepair}
+}
+
+// Start checks the stock invariants every interval until ctx is cancelled.
+// Results go to the reconciliation metrics, see `main reconcile` for the
+// rows. A run in progress is finished first, bounded by the shutdown
+// deadline.
+func (s *ReconciliationScheduler) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "[ReconciliationScheduler] Start", "interval", s.interval.String(), "autoRepair", s.autoRepair)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "[ReconciliationScheduler] Start", "stopped", ctx.Err())
+			return
+		case <-ticker.C:
+			report, err := s.usecase.Check(lifecycle.WorkContext(ctx), s.autoRepair)
+			if err != nil {
+				slog.ErrorContext(ctx, "[ReconciliationScheduler] Start", "check", err)
+				continue
+			}
+			if unrepaired := int64(len(report.Violations)) - report.Repaired; unrepaired > 0 {
+				slog.WarnContext(ctx, "[ReconciliationScheduler] Start", "unrepaired", unrepaired, "counts", report.Counts)
+			}
+		}
+	}
+}
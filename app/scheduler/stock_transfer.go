@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/lifecycle"
+)
+
+type StockTransferScheduler struct {
+	usecase  domain.StockTransferScheduleUsecase
+	interval time.Duration
+}
+
+func NewStockTransferScheduler(usecase domain.StockTransferScheduleUsecase, interval time.Duration) *StockTransferScheduler {
+	return &StockTransferScheduler{usecase, interval}
+}
+
+// Start polls for due schedules until ctx is cancelled.
+// A run in progress is finished first, bounded by the shutdown deadline.
+func (s *StockTransferScheduler) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "[StockTransferScheduler] Start", "interval", s.interval.String())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "[StockTransferScheduler] Start", "stopped", ctx.Err())
+			return
+		case <-ticker.C:
+			if err := s.usecase.RunDueSchedules(lifecycle.WorkContext(ctx), time.Now().UTC()); err != nil {
+				slog.ErrorContext(ctx, "[StockTransferScheduler] Start", "runDueSchedules", err)
+			}
+		}
+	}
+}
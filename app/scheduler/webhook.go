@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/lifecycle"
+)
+
+type WebhookDispatcher struct {
+	usecase  domain.WebhookUsecase
+	interval time.Duration
+}
+
+func NewWebhookDispatcher(usecase domain.WebhookUsecase, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{usecase, interval}
+}
+
+// Start delivers due webhook deliveries until ctx is cancelled.
+// A run in progress is finished first, bounded by the shutdown deadline.
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "[WebhookDispatcher] Start", "interval", d.interval.String())
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "[WebhookDispatcher] Start", "stopped", ctx.Err())
+			return
+		case <-ticker.C:
+			if err := d.usecase.DeliverDue(lifecycle.WorkContext(ctx), time.Now().UTC()); err != nil {
+				slog.ErrorContext(ctx, "[WebhookDispatcher] Start", "deliverDue", err)
+			}
+		}
+	}
+}
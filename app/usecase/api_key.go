@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"warehouse-service/app/domain"
+)
+
+// Keys look like wsk_<prefix>_<secret>. The prefix is stored in clear to
+// find the row, the whole key only as a SHA-256 hash.
+const apiKeyPrefix = "wsk"
+
+type apiKeyUsecase struct {
+	apiKeyRepo domain.ApiKeyRepository
+}
+
+func NewApiKeyUsecase(apiKeyRepo domain.ApiKeyRepository) domain.ApiKeyUsecase {
+	return &apiKeyUsecase{apiKeyRepo}
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (u *apiKeyUsecase) Issue(ctx context.Context, req domain.ApiKeyCreateRequest) (*domain.ApiKey, string, error) {
+	ctx, span := tracer.Start(ctx, "apiKeyUsecase.Issue")
+	defer span.End()
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyUsecase] Issue", "generatePrefix", err)
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyUsecase] Issue", "generateSecret", err)
+		return nil, "", err
+	}
+	key := apiKeyPrefix + "_" + prefix + "_" + secret
+
+	apiKey := &domain.ApiKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hashApiKey(key),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := u.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		slog.ErrorContext(ctx, "[apiKeyUsecase] Issue", "create", err)
+		return nil, "", err
+	}
+
+	slog.InfoContext(ctx, "[apiKeyUsecase] Issue", "id", apiKey.ID, "name", apiKey.Name, "scopes", apiKey.Scopes)
+	return apiKey, key, nil
+}
+
+func (u *apiKeyUsecase) Authenticate(ctx context.Context, key string) (domain.ApiKey, error) {
+	ctx, span := tracer.Start(ctx, "apiKeyUsecase.Authenticate")
+	defer span.End()
+
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return domain.ApiKey{}, domain.ErrUnauthorized
+	}
+
+	apiKey, err := u.apiKeyRepo.GetByPrefix(ctx, parts[1])
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ApiKey{}, domain.ErrUnauthorized
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyUsecase] Authenticate", "getByPrefix", err)
+		return domain.ApiKey{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashApiKey(key)), []byte(apiKey.KeyHash)) != 1 {
+		slog.WarnContext(ctx, "[apiKeyUsecase] Authenticate", "hashMismatch", apiKey.ID)
+		return domain.ApiKey{}, domain.ErrUnauthorized
+	}
+	if apiKey.RevokedAt != nil {
+		slog.WarnContext(ctx, "[apiKeyUsecase] Authenticate", "revoked", apiKey.ID)
+		return domain.ApiKey{}, domain.ErrUnauthorized
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		slog.WarnContext(ctx, "[apiKeyUsecase] Authenticate", "expired", apiKey.ID)
+		return domain.ApiKey{}, domain.ErrUnauthorized
+	}
+
+	if err := u.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID); err != nil {
+		slog.WarnContext(ctx, "[apiKeyUsecase] Authenticate", "touchLastUsed", err)
+	}
+
+	return apiKey, nil
+}
+
+func (u *apiKeyUsecase) GetList(ctx context.Context) ([]domain.ApiKey, error) {
+	ctx, span := tracer.Start(ctx, "apiKeyUsecase.GetList")
+	defer span.End()
+
+	apiKeys, err := u.apiKeyRepo.GetList(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[apiKeyUsecase] GetList", "getList", err)
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (u *apiKeyUsecase) Revoke(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "apiKeyUsecase.Revoke")
+	defer span.End()
+
+	if err := u.apiKeyRepo.Revoke(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "[apiKeyUsecase] Revoke", "revoke", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "[apiKeyUsecase] Revoke", "id", id)
+	return nil
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"errors"
+	"warehouse-service/app/domain"
+)
+
+// metricReason labels a failure for the business metrics, using the domain
+// error code when there is one.
+func metricReason(err error) string {
+	var domainErr *domain.Error
+	switch {
+	case errors.As(err, &domainErr):
+		return domainErr.Code
+	case errors.Is(err, domain.ErrNotFound):
+		return "NOT_FOUND"
+	default:
+		return "INTERNAL"
+	}
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/metrics"
+)
+
+type reconciliationUsecase struct {
+	reconciliationRepo domain.ReconciliationRepository
+}
+
+func NewReconciliationUsecase(reconciliationRepo domain.ReconciliationRepository) domain.ReconciliationUsecase {
+	return &reconciliationUsecase{reconciliationRepo}
+}
+
+func (u *reconciliationUsecase) Check(ctx context.Context, repair bool) (domain.ReconciliationReport, error) {
+	ctx, span := tracer.Start(ctx, "reconciliationUsecase.Check")
+	defer span.End()
+
+	report := domain.ReconciliationReport{
+		CheckedAt:  time.Now().UTC(),
+		Counts:     make(map[domain.ReconciliationInvariant]int64, len(domain.ReconciliationInvariants)),
+		Violations: []domain.ReconciliationViolation{},
+		Unchecked:  domain.UncheckedReconciliationInvariants,
+	}
+	for _, invariant := range domain.ReconciliationInvariants {
+		report.Counts[invariant] = 0
+	}
+
+	checks := []struct {
+		name  string
+		check func(context.Context) ([]domain.ReconciliationViolation, error)
+	}{
+		{"getOverReservedStocks", u.reconciliationRepo.GetOverReservedStocks},
+		{"getNegativeStocks", u.reconciliationRepo.GetNegativeStocks},
+		{"getInProgressTransfersWithoutStock", u.reconciliationRepo.GetInProgressTransfersWithoutStock},
+		{"getMissingStocks", u.reconciliationRepo.GetMissingStocks},
+	}
+	for _, c := range checks {
+		violations, err := c.check(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[reconciliationUsecase] Check", c.name, err)
+			return report, err
+		}
+		report.Violations = append(report.Violations, violations...)
+	}
+
+	for i := range report.Violations {
+		violation := &report.Violations[i]
+		report.Counts[violation.Invariant]++
+
+		// Only a missing row is repaired: an empty row changes no quantity
+		// or availability. The other invariants need someone to decide
+		// which side is wrong.
+		if !repair || violation.Invariant != domain.InvariantStockRowPerWarehouse {
+			continue
+		}
+		stockID, err := u.reconciliationRepo.CreateMissingStock(ctx, violation.ProductID, violation.WarehouseID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[reconciliationUsecase] Check", "createMissingStock", err)
+			return report, err
+		}
+		// 0 means the row was created since the scan
+		violation.StockID = stockID
+		violation.Repaired = true
+		report.Repaired++
+		metrics.ReconciliationRepairsTotal.WithLabelValues(string(violation.Invariant)).Inc()
+	}
+
+	for invariant, count := range report.Counts {
+		if repair && invariant == domain.InvariantStockRowPerWarehouse {
+			count -= report.Repaired
+		}
+		metrics.ReconciliationViolations.WithLabelValues(string(invariant)).Set(float64(count))
+	}
+	metrics.ReconciliationLastRunTimestamp.SetToCurrentTime()
+
+	slog.InfoContext(ctx, "[reconciliationUsecase] Check", "counts", report.Counts, "repaired", report.Repaired)
+	return report, nil
+}
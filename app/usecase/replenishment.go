@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+)
+
+type replenishmentUsecase struct {
+	ruleRepo             domain.ReplenishmentRuleRepository
+	warehouseRepo        domain.WarehouseRepository
+	stockRepo            domain.StockRepository
+	reservedStockRepo    domain.ReservedStockRepository
+	stockTransferRepo    domain.StockTransferRepository
+	stockTransferUsecase domain.StockTransferUsecase
+}
+
+func NewReplenishmentUsecase(ruleRepo domain.ReplenishmentRuleRepository,
+	warehouseRepo domain.WarehouseRepository,
+	stockRepo domain.StockRepository,
+	reservedStockRepo domain.ReservedStockRepository,
+	stockTransferRepo domain.StockTransferRepository,
+	stockTransferUsecase domain.StockTransferUsecase) domain.ReplenishmentUsecase {
+	return &replenishmentUsecase{
+		ruleRepo:             ruleRepo,
+		warehouseRepo:        warehouseRepo,
+		stockRepo:            stockRepo,
+		reservedStockRepo:    reservedStockRepo,
+		stockTransferRepo:    stockTransferRepo,
+		stockTransferUsecase: stockTransferUsecase,
+	}
+}
+
+func (u *replenishmentUsecase) UpsertRule(ctx context.Context, shopID int64, req domain.ReplenishmentRuleUpsertRequest) (*domain.ReplenishmentRule, error) {
+	ctx, span := tracer.Start(ctx, "replenishmentUsecase.UpsertRule")
+	defer span.End()
+
+	warehouse, err := u.warehouseRepo.GetByID(ctx, req.WarehouseID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] UpsertRule", "getWarehouse", err)
+		return nil, err
+	}
+
+	sourceWarehouse, err := u.warehouseRepo.GetByID(ctx, req.SourceWarehouseID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] UpsertRule", "getSourceWarehouse", err)
+		return nil, err
+	}
+
+	if warehouse.ShopID != shopID || sourceWarehouse.ShopID != shopID {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] UpsertRule", "invalidShopID", "shopID")
+		return nil, domain.ErrWarehouseShopMismatch
+	}
+
+	if _, err = u.stockRepo.GetByProductIDAndWarehouseID(ctx, req.ProductID, req.WarehouseID); err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] UpsertRule", "getStock", err)
+		return nil, err
+	}
+	if _, err = u.stockRepo.GetByProductIDAndWarehouseID(ctx, req.ProductID, req.SourceWarehouseID); err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] UpsertRule", "getSourceStock", err)
+		return nil, err
+	}
+
+	rule := &domain.ReplenishmentRule{
+		ShopID:            shopID,
+		ProductID:         req.ProductID,
+		WarehouseID:       req.WarehouseID,
+		SourceWarehouseID: req.SourceWarehouseID,
+		MinLevel:          req.MinLevel,
+		MaxLevel:          req.MaxLevel,
+		Active:            req.Active == nil || *req.Active,
+	}
+
+	err = u.ruleRepo.Upsert(ctx, rule)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] UpsertRule", "upsert", err)
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (u *replenishmentUsecase) GetListRule(ctx context.Context, shopID int64, param domain.GetListReplenishmentRuleRequest) ([]domain.ReplenishmentRule, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "replenishmentUsecase.GetListRule")
+	defer span.End()
+
+	rules, err := u.ruleRepo.GetListRule(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] GetListRule", "getListRule", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	count, err := u.ruleRepo.GetListRuleCount(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] GetListRule", "getListRuleCount", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.Metadata{
+		TotalData: count,
+		TotalPage: (count + param.Limit - 1) / param.Limit,
+		Page:      param.Page,
+		Limit:     param.Limit,
+	}
+
+	return rules, metadata, nil
+}
+
+func (u *replenishmentUsecase) DeleteRule(ctx context.Context, id, shopID int64) error {
+	ctx, span := tracer.Start(ctx, "replenishmentUsecase.DeleteRule")
+	defer span.End()
+
+	rule, err := u.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] DeleteRule", "getRule", err)
+		return err
+	}
+
+	if rule.ShopID != shopID {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] DeleteRule", "invalidShopID", "shopID")
+		return domain.ErrUnauthorized
+	}
+
+	if err = u.ruleRepo.Delete(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] DeleteRule", "delete", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *replenishmentUsecase) Replenish(ctx context.Context, stockID int64) (*domain.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "replenishmentUsecase.Replenish")
+	defer span.End()
+
+	stock, err := u.stockRepo.GetByID(ctx, stockID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] Replenish", "getStock", err)
+		return nil, err
+	}
+
+	rule, err := u.ruleRepo.GetByProductIDAndWarehouseID(ctx, stock.ProductID, stock.WarehouseID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+		slog.ErrorContext(ctx, "[replenishmentUsecase] Replenish", "getRule", err)
+		return nil, err
+	}
+
+	if !rule.Active {
+		return nil, nil
+	}
+
+	// The destination stock row stays locked until the transfer is committed,
+	// so concurrent reservations on any instance see it as open and don't
+	// spawn duplicates.
+	var transfer *domain.StockTransfer
+	err = u.stockRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		stock, err := u.stockRepo.LockForUpdate(ctx, stock.ID, tx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[replenishmentUsecase] Replenish", "lockStock", err)
+			return err
+		}
+
+		available, err := u.availableAtStock(ctx, stock)
+		if err != nil {
+			return err
+		}
+
+		if available >= rule.MinLevel {
+			return nil
+		}
+
+		openTransfers, err := u.stockTransferRepo.GetOpenTransferCount(ctx, rule.ProductID, rule.WarehouseID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[replenishmentUsecase] Replenish", "getOpenTransferCount", err)
+			return err
+		}
+
+		if openTransfers > 0 {
+			slog.InfoContext(ctx, "[replenishmentUsecase] Replenish", "skipped", "transfer already open", "ruleID", rule.ID)
+			return nil
+		}
+
+		sourceStock, err := u.stockRepo.GetByProductIDAndWarehouseID(ctx, rule.ProductID, rule.SourceWarehouseID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[replenishmentUsecase] Replenish", "getSourceStock", err)
+			return err
+		}
+
+		sourceAvailable, err := u.availableAtStock(ctx, sourceStock)
+		if err != nil {
+			return err
+		}
+
+		quantity := min(rule.MaxLevel-available, sourceAvailable)
+		if quantity <= 0 {
+			slog.WarnContext(ctx, "[replenishmentUsecase] Replenish", "skipped", "source has no available stock", "ruleID", rule.ID)
+			return nil
+		}
+
+		transfer, err = u.stockTransferUsecase.CreateTransferTx(ctx, rule.ShopID, domain.StockTransferCreateRequest{
+			ProductID:     rule.ProductID,
+			FromWarehouse: rule.SourceWarehouseID,
+			ToWarehouse:   rule.WarehouseID,
+			Quantity:      quantity,
+			Description:   fmt.Sprintf("auto replenishment (rule %d)", rule.ID),
+		}, tx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[replenishmentUsecase] Replenish", "createTransfer", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer != nil {
+		slog.InfoContext(ctx, "[replenishmentUsecase] Replenish", "transfer", transfer)
+	}
+	return transfer, nil
+}
+
+func (u *replenishmentUsecase) availableAtStock(ctx context.Context, stock domain.Stock) (int64, error) {
+	reserved, err := u.reservedStockRepo.GetTotalReservedStockByStockIDAndStatus(ctx, stock.ID, domain.ReservedStockStatusActive)
+	if err != nil {
+		slog.ErrorContext(ctx, "[replenishmentUsecase] availableAtStock", "getReservedStock", err)
+		return 0, err
+	}
+	return stock.Quantity - reserved, nil
+}
+
+// replenishingTransferUsecase checks the source stock's replenishment rule
+// once a transfer moving to in_progress has taken stock out of it.
+type replenishingTransferUsecase struct {
+	domain.StockTransferUsecase
+	stockRepo     domain.StockRepository
+	replenishment domain.ReplenishmentUsecase
+}
+
+func NewReplenishingTransferUsecase(transfers domain.StockTransferUsecase,
+	stockRepo domain.StockRepository,
+	replenishment domain.ReplenishmentUsecase) domain.StockTransferUsecase {
+	return &replenishingTransferUsecase{transfers, stockRepo, replenishment}
+}
+
+func (u *replenishingTransferUsecase) UpdateTransferStatus(ctx context.Context, id int64, req domain.StockTransferUpdateRequest) error {
+	if err := u.StockTransferUsecase.UpdateTransferStatus(ctx, id, req); err != nil {
+		return err
+	}
+
+	if req.Status == domain.TransferStatusInProgress {
+		st, err := u.GetTransferByID(ctx, id, nil)
+		if err != nil {
+			slog.WarnContext(ctx, "[replenishingTransferUsecase] UpdateTransferStatus", "getTransfer", err)
+			return nil
+		}
+		u.replenishSource(ctx, st)
+	}
+	return nil
+}
+
+func (u *replenishingTransferUsecase) UpdateTransferStatusTx(ctx context.Context, st *domain.StockTransfer, req domain.StockTransferUpdateRequest, tx *sql.Tx) error {
+	if err := u.StockTransferUsecase.UpdateTransferStatusTx(ctx, st, req, tx); err != nil {
+		return err
+	}
+
+	if req.Status == domain.TransferStatusInProgress {
+		transfer := *st
+		ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+			u.replenishSource(ctx, transfer)
+		})
+	}
+	return nil
+}
+
+func (u *replenishingTransferUsecase) replenishSource(ctx context.Context, st domain.StockTransfer) {
+	stock, err := u.stockRepo.GetByProductIDAndWarehouseID(ctx, st.ProductID, st.FromWarehouse)
+	if err != nil {
+		slog.WarnContext(ctx, "[replenishingTransferUsecase] replenishSource", "getStock", err)
+		return
+	}
+
+	if _, err = u.replenishment.Replenish(ctx, stock.ID); err != nil {
+		slog.WarnContext(ctx, "[replenishingTransferUsecase] replenishSource", "replenish", err)
+	}
+}
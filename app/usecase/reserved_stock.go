@@ -3,16 +3,18 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"log/slog"
+	"time"
 	"warehouse-service/app/domain"
 	"warehouse-service/config"
+	"warehouse-service/pkg/metrics"
 )
 
 type reservedStockUsecase struct {
 	stockRepo          domain.StockRepository
 	reservedStockRepo  domain.ReservedStockRepository
 	stockPublishBroker domain.BrokerPublisher
+	replenishment      domain.ReplenishmentUsecase
 	cfg                *config.Config
 }
 
@@ -20,11 +22,22 @@ func NewReservedStockUsecase(
 	stockRepo domain.StockRepository,
 	reservedStockRepo domain.ReservedStockRepository,
 	stockPublishBroker domain.BrokerPublisher,
+	replenishment domain.ReplenishmentUsecase,
 	cfg *config.Config) domain.ReservedStockUsecase {
-	return &reservedStockUsecase{stockRepo, reservedStockRepo, stockPublishBroker, cfg}
+	return &reservedStockUsecase{stockRepo, reservedStockRepo, stockPublishBroker, replenishment, cfg}
 }
 
-func (u *reservedStockUsecase) CreateReservedStock(ctx context.Context, req domain.ReservedStockCreateRequest) error {
+func (u *reservedStockUsecase) CreateReservedStock(ctx context.Context, req domain.ReservedStockCreateRequest) (err error) {
+	ctx, span := tracer.Start(ctx, "reservedStockUsecase.CreateReservedStock")
+	defer span.End()
+
+	defer func() {
+		if err != nil {
+			metrics.ReservationsTotal.WithLabelValues("failed", metricReason(err)).Inc()
+			return
+		}
+		metrics.ReservationsTotal.WithLabelValues("created", "").Inc()
+	}()
 
 	stocks, err := u.stockRepo.GetByProductID(ctx, req.ProductID)
 	if err != nil {
@@ -69,7 +82,7 @@ func (u *reservedStockUsecase) CreateReservedStock(ctx context.Context, req doma
 
 	if createReservedStock == nil {
 		slog.ErrorContext(ctx, "[reservedStockUsecase] CreateReservedStock", "insufficientStock", "no available stock")
-		return fmt.Errorf("%w: insufficient stock available", domain.ErrValidation)
+		return domain.ErrInsufficientStock
 	}
 
 	if err = u.stockRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
@@ -87,6 +100,12 @@ func (u *reservedStockUsecase) CreateReservedStock(ctx context.Context, req doma
 			return err
 		}
 
+		shopID, err := u.stockRepo.GetShopIDByProductID(ctx, stock.ProductID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[reservedStockUsecase] CreateReservedStock", "getShopID", err)
+			return err
+		}
+
 		err = u.reservedStockRepo.CreateReservedStock(ctx, createReservedStock, tx)
 		if err != nil {
 			slog.ErrorContext(ctx, "[reservedStockUsecase] CreateReservedStock", "createReservedStock", err)
@@ -94,8 +113,12 @@ func (u *reservedStockUsecase) CreateReservedStock(ctx context.Context, req doma
 		}
 
 		err = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
-			ProductID: stock.ProductID,
-			Available: availableStock - createReservedStock.Quantity,
+			ProductID:   stock.ProductID,
+			Available:   availableStock - createReservedStock.Quantity,
+			ShopID:      shopID,
+			WarehouseID: stock.WarehouseID,
+			Cause:       domain.StockEventCauseReservation,
+			Tx:          tx,
 		})
 		if err != nil {
 			slog.ErrorContext(ctx, "[reservedStockUsecase] CreateReservedStock", "publishStockAvailable", err)
@@ -108,10 +131,17 @@ func (u *reservedStockUsecase) CreateReservedStock(ctx context.Context, req doma
 		return err
 	}
 
+	if _, err = u.replenishment.Replenish(ctx, createReservedStock.StockID); err != nil {
+		slog.WarnContext(ctx, "[reservedStockUsecase] CreateReservedStock", "replenish", err)
+	}
+
 	return nil
 }
 
 func (u *reservedStockUsecase) UpdateReservedStockStatusByOrderID(ctx context.Context, orderID int64, req domain.ReservedStockUpdateRequest) error {
+	ctx, span := tracer.Start(ctx, "reservedStockUsecase.UpdateReservedStockStatusByOrderID")
+	defer span.End()
+
 	reservedStock, err := u.reservedStockRepo.GetReservedStockByOrderID(ctx, orderID)
 	if err != nil {
 		slog.ErrorContext(ctx, "[reservedStockUsecase] UpdateReservedStockStatusByOrderID", "getReservedStockByOrderID", err)
@@ -138,6 +168,12 @@ func (u *reservedStockUsecase) UpdateReservedStockStatusByOrderID(ctx context.Co
 			return err
 		}
 
+		shopID, err := u.stockRepo.GetShopIDByProductID(ctx, stock.ProductID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[reservedStockUsecase] UpdateReservedStockStatusByOrderID", "getShopID", err)
+			return err
+		}
+
 		if req.Status == domain.ReservedStockStatusCancelled {
 			availableStock += reservedStock.Quantity
 		} else if req.Status == domain.ReservedStockStatusCompleted {
@@ -157,8 +193,12 @@ func (u *reservedStockUsecase) UpdateReservedStockStatusByOrderID(ctx context.Co
 		}
 
 		err = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
-			ProductID: stock.ProductID,
-			Available: availableStock,
+			ProductID:   stock.ProductID,
+			Available:   availableStock,
+			ShopID:      shopID,
+			WarehouseID: stock.WarehouseID,
+			Cause:       domain.StockEventCauseReservation,
+			Tx:          tx,
 		})
 		if err != nil {
 			slog.ErrorContext(ctx, "[reservedStockUsecase] UpdateReservedStockStatusByOrderID", "publishStockAvailable", err)
@@ -174,3 +214,33 @@ func (u *reservedStockUsecase) UpdateReservedStockStatusByOrderID(ctx context.Co
 
 	return nil
 }
+
+func (u *reservedStockUsecase) ExpireReservedStocks(ctx context.Context, before time.Time, limit int64, dryRun bool) ([]domain.ReservedStock, error) {
+	ctx, span := tracer.Start(ctx, "reservedStockUsecase.ExpireReservedStocks")
+	defer span.End()
+
+	reservedStocks, err := u.reservedStockRepo.GetActiveReservedStocksCreatedBefore(ctx, before, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "[reservedStockUsecase] ExpireReservedStocks", "getActiveReservedStocksCreatedBefore", err)
+		return nil, err
+	}
+	if dryRun {
+		return reservedStocks, nil
+	}
+
+	// Cancel through the same path as the order service, so stock is
+	// released and availability published per reservation
+	var expired []domain.ReservedStock
+	for _, reservedStock := range reservedStocks {
+		err := u.UpdateReservedStockStatusByOrderID(ctx, reservedStock.OrderID, domain.ReservedStockUpdateRequest{Status: domain.ReservedStockStatusCancelled})
+		if err != nil {
+			slog.ErrorContext(ctx, "[reservedStockUsecase] ExpireReservedStocks", "updateReservedStockStatusByOrderID", err)
+			return expired, err
+		}
+		metrics.ReservationsTotal.WithLabelValues("expired", "").Inc()
+		expired = append(expired, reservedStock)
+	}
+
+	slog.InfoContext(ctx, "[reservedStockUsecase] ExpireReservedStocks", "expired", len(expired))
+	return expired, nil
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"warehouse-service/app/domain"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	defaultSnapshotLimit         = 1000
+	defaultSnapshotRatePerSecond = 100
+	snapshotPageSize             = 500
+	// snapshotMaxDuration bounds one request, a slow rate publishes fewer
+	// products and the caller resumes from the returned cursor
+	snapshotMaxDuration = 30 * time.Second
+)
+
+type snapshotUsecase struct {
+	stockRepo          domain.StockRepository
+	stockPublishBroker domain.BrokerPublisher
+	maxDuration        time.Duration
+}
+
+func NewSnapshotUsecase(stockRepo domain.StockRepository, stockPublishBroker domain.BrokerPublisher) domain.SnapshotUsecase {
+	return &snapshotUsecase{stockRepo, stockPublishBroker, snapshotMaxDuration}
+}
+
+func (u *snapshotUsecase) PublishAvailabilitySnapshot(ctx context.Context, req domain.AvailabilitySnapshotRequest) (domain.AvailabilitySnapshotResult, error) {
+	ctx, span := tracer.Start(ctx, "snapshotUsecase.PublishAvailabilitySnapshot")
+	defer span.End()
+
+	if req.Limit <= 0 {
+		req.Limit = defaultSnapshotLimit
+	}
+	if req.RatePerSecond <= 0 {
+		req.RatePerSecond = defaultSnapshotRatePerSecond
+	}
+
+	result := domain.AvailabilitySnapshotResult{
+		SnapshotID: req.SnapshotID,
+		NextCursor: req.Cursor,
+	}
+	if result.SnapshotID == "" {
+		snapshotID, err := uuid.NewV4()
+		if err != nil {
+			slog.ErrorContext(ctx, "[snapshotUsecase] PublishAvailabilitySnapshot", "uuid.NewV4", err)
+			return result, err
+		}
+		result.SnapshotID = snapshotID.String()
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(req.RatePerSecond))
+	defer ticker.Stop()
+	deadline := time.NewTimer(u.maxDuration)
+	defer deadline.Stop()
+
+	snapshotAt := time.Now().UTC()
+	for result.Published < req.Limit {
+		pageSize := min(snapshotPageSize, req.Limit-result.Published)
+		availabilities, err := u.stockRepo.GetProductAvailabilities(ctx, req.ShopID, result.NextCursor, pageSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "[snapshotUsecase] PublishAvailabilitySnapshot", "getProductAvailabilities", err)
+			return result, err
+		}
+
+		for _, availability := range availabilities {
+			select {
+			case <-ctx.Done():
+				// the cursor still points at the last published product
+				return result, ctx.Err()
+			case <-deadline.C:
+				slog.InfoContext(ctx, "[snapshotUsecase] PublishAvailabilitySnapshot", "maxDurationReached", result)
+				return result, nil
+			case <-ticker.C:
+			}
+
+			err = u.stockPublishBroker.PublishStockSnapshot(ctx, domain.StockSnapshotMessage{
+				SnapshotID: result.SnapshotID,
+				ProductID:  availability.ProductID,
+				ShopID:     availability.ShopID,
+				Available:  availability.Available,
+				SnapshotAt: snapshotAt,
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "[snapshotUsecase] PublishAvailabilitySnapshot", "publishStockSnapshot", err)
+				return result, err
+			}
+
+			result.Published++
+			result.NextCursor = availability.ProductID
+		}
+
+		if int64(len(availabilities)) < pageSize {
+			result.Done = true
+			break
+		}
+	}
+
+	slog.InfoContext(ctx, "[snapshotUsecase] PublishAvailabilitySnapshot", "result", result)
+	return result, nil
+}
+
+func (u *snapshotUsecase) ReplayAvailability(ctx context.Context, req domain.AvailabilityReplayRequest) (int64, error) {
+	ctx, span := tracer.Start(ctx, "snapshotUsecase.ReplayAvailability")
+	defer span.End()
+
+	if req.RatePerSecond <= 0 {
+		req.RatePerSecond = defaultSnapshotRatePerSecond
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(req.RatePerSecond))
+	defer ticker.Stop()
+
+	var published int64
+	publish := func(availability domain.ProductAvailability) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		err := u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
+			ProductID: availability.ProductID,
+			Available: availability.Available,
+			ShopID:    availability.ShopID,
+			Cause:     domain.StockEventCauseReplay,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "[snapshotUsecase] ReplayAvailability", "publishStockAvailable", err)
+			return err
+		}
+		published++
+		return nil
+	}
+
+	if len(req.ProductIDs) > 0 {
+		for _, productID := range req.ProductIDs {
+			available, err := u.stockRepo.GetAvailableStockByProductID(ctx, productID)
+			if err != nil {
+				slog.ErrorContext(ctx, "[snapshotUsecase] ReplayAvailability", "getAvailableStockByProductID", err)
+				return published, err
+			}
+			shopID, err := u.stockRepo.GetShopIDByProductID(ctx, productID)
+			if err != nil {
+				slog.ErrorContext(ctx, "[snapshotUsecase] ReplayAvailability", "getShopIDByProductID", err)
+				return published, err
+			}
+			if err := publish(domain.ProductAvailability{ProductID: productID, ShopID: shopID, Available: available}); err != nil {
+				return published, err
+			}
+		}
+		return published, nil
+	}
+
+	var cursor int64
+	for {
+		availabilities, err := u.stockRepo.GetProductAvailabilities(ctx, req.ShopID, cursor, snapshotPageSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "[snapshotUsecase] ReplayAvailability", "getProductAvailabilities", err)
+			return published, err
+		}
+		for _, availability := range availabilities {
+			if err := publish(availability); err != nil {
+				return published, err
+			}
+			cursor = availability.ProductID
+		}
+		if len(availabilities) < snapshotPageSize {
+			return published, nil
+		}
+	}
+}
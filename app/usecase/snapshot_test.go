@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"warehouse-service/app/domain"
+)
+
+// pagedAvailabilities serves count products with IDs 1..count.
+type pagedAvailabilities struct {
+	domain.StockRepository
+	count int64
+}
+
+func (r pagedAvailabilities) GetProductAvailabilities(ctx context.Context, shopID, cursor, limit int64) ([]domain.ProductAvailability, error) {
+	var page []domain.ProductAvailability
+	for id := cursor + 1; id <= r.count && int64(len(page)) < limit; id++ {
+		page = append(page, domain.ProductAvailability{ProductID: id, ShopID: 1, Available: 5})
+	}
+	return page, nil
+}
+
+type snapshotRecorder struct {
+	domain.BrokerPublisher
+	published []int64
+}
+
+func (b *snapshotRecorder) PublishStockSnapshot(ctx context.Context, data domain.StockSnapshotMessage) error {
+	b.published = append(b.published, data.ProductID)
+	return nil
+}
+
+func TestPublishAvailabilitySnapshot(t *testing.T) {
+	tests := []struct {
+		name        string
+		products    int64
+		req         domain.AvailabilitySnapshotRequest
+		maxDuration time.Duration
+		wantCount   int64
+		wantCursor  int64
+		wantDone    bool
+	}{
+		{
+			name:        "publishes every product",
+			products:    3,
+			req:         domain.AvailabilitySnapshotRequest{RatePerSecond: 1000},
+			maxDuration: time.Minute,
+			wantCount:   3,
+			wantCursor:  3,
+			wantDone:    true,
+		},
+		{
+			name:        "stops at the limit",
+			products:    10,
+			req:         domain.AvailabilitySnapshotRequest{Limit: 4, RatePerSecond: 1000},
+			maxDuration: time.Minute,
+			wantCount:   4,
+			wantCursor:  4,
+		},
+		{
+			name:        "resumes after the cursor",
+			products:    10,
+			req:         domain.AvailabilitySnapshotRequest{Cursor: 8, RatePerSecond: 1000},
+			maxDuration: time.Minute,
+			wantCount:   2,
+			wantCursor:  10,
+			wantDone:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &snapshotRecorder{}
+			u := &snapshotUsecase{pagedAvailabilities{count: tt.products}, broker, tt.maxDuration}
+
+			result, err := u.PublishAvailabilitySnapshot(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("PublishAvailabilitySnapshot: %v", err)
+			}
+			if result.Published != tt.wantCount || result.NextCursor != tt.wantCursor || result.Done != tt.wantDone {
+				t.Fatalf("result = %+v, want published %d, cursor %d, done %t", result, tt.wantCount, tt.wantCursor, tt.wantDone)
+			}
+			if int64(len(broker.published)) != result.Published {
+				t.Fatalf("broker got %d snapshots, result says %d", len(broker.published), result.Published)
+			}
+			if result.SnapshotID == "" {
+				t.Fatal("no snapshot id")
+			}
+		})
+	}
+}
+
+func TestPublishAvailabilitySnapshotStopsAtTimeBudget(t *testing.T) {
+	broker := &snapshotRecorder{}
+	u := &snapshotUsecase{pagedAvailabilities{count: 10000}, broker, 250 * time.Millisecond}
+
+	start := time.Now()
+	result, err := u.PublishAvailabilitySnapshot(context.Background(), domain.AvailabilitySnapshotRequest{Limit: 10000, RatePerSecond: 10})
+	if err != nil {
+		t.Fatalf("PublishAvailabilitySnapshot: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("took %s, want about the 250ms budget", elapsed)
+	}
+	if result.Done || result.Published == 0 || result.Published >= 10 {
+		t.Fatalf("result = %+v, want a few products and not done", result)
+	}
+	if result.NextCursor != result.Published {
+		t.Fatalf("cursor = %d, want the last published product %d", result.NextCursor, result.Published)
+	}
+}
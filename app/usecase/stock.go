@@ -3,25 +3,31 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"log/slog"
+	"strconv"
 	"warehouse-service/app/domain"
 	"warehouse-service/config"
+	"warehouse-service/pkg/metrics"
 )
 
 type stockUsecase struct {
-	stockRepo          domain.StockRepository
-	warehouseRepo      domain.WarehouseRepository
-	reservedStockRepo  domain.ReservedStockRepository
-	stockPublishBroker domain.BrokerPublisher
-	cfg                *config.Config
+	stockRepo           domain.StockRepository
+	warehouseRepo       domain.WarehouseRepository
+	reservedStockRepo   domain.ReservedStockRepository
+	stockPublishBroker  domain.BrokerPublisher
+	replenishment       domain.ReplenishmentUsecase
+	stockAdjustmentRepo domain.StockAdjustmentRepository
+	cfg                 *config.Config
 }
 
-func NewStockUsecase(stockRepo domain.StockRepository, warehouseRepo domain.WarehouseRepository, reservedStockRepo domain.ReservedStockRepository, stockPublishBroker domain.BrokerPublisher, cfg *config.Config) domain.StockService {
-	return &stockUsecase{stockRepo, warehouseRepo, reservedStockRepo, stockPublishBroker, cfg}
+func NewStockUsecase(stockRepo domain.StockRepository, warehouseRepo domain.WarehouseRepository, reservedStockRepo domain.ReservedStockRepository, stockPublishBroker domain.BrokerPublisher, replenishment domain.ReplenishmentUsecase, stockAdjustmentRepo domain.StockAdjustmentRepository, cfg *config.Config) domain.StockService {
+	return &stockUsecase{stockRepo, warehouseRepo, reservedStockRepo, stockPublishBroker, replenishment, stockAdjustmentRepo, cfg}
 }
 
 func (u *stockUsecase) InitStock(ctx context.Context, req domain.StockCreateRequest) ([]domain.Stock, error) {
+	ctx, span := tracer.Start(ctx, "stockUsecase.InitStock")
+	defer span.End()
+
 	warehouses, err := u.warehouseRepo.GetByShopID(ctx, req.ShopID)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockUsecase] InitStock", "getWarehouses", err)
@@ -44,6 +50,8 @@ func (u *stockUsecase) InitStock(ctx context.Context, req domain.StockCreateRequ
 	// Publish the stock available event to the broker
 	err = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
 		ProductID: req.ProductID,
+		ShopID:    req.ShopID,
+		Cause:     domain.StockEventCauseInit,
 	})
 	if err != nil {
 		slog.WarnContext(ctx, "[stockUsecase] InitStock", "publishStockInit", err)
@@ -54,6 +62,9 @@ func (u *stockUsecase) InitStock(ctx context.Context, req domain.StockCreateRequ
 }
 
 func (u *stockUsecase) GetAvailableStockByProductID(ctx context.Context, productID int64) (domain.AvailableStock, error) {
+	ctx, span := tracer.Start(ctx, "stockUsecase.GetAvailableStockByProductID")
+	defer span.End()
+
 	availableStock, err := u.stockRepo.GetAvailableStockByProductID(ctx, productID)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockUsecase] GetAvailableStockByProductID", "getAvailableStock", err)
@@ -66,22 +77,34 @@ func (u *stockUsecase) GetAvailableStockByProductID(ctx context.Context, product
 	}, nil
 }
 
-func (u *stockUsecase) UpdateQuantity(ctx context.Context, id, shopID int64, req domain.UpdateQuantityRequest) error {
-	stock, err := u.stockRepo.GetByID(ctx, id)
+func (u *stockUsecase) GetAvailableStockByProductIDs(ctx context.Context, productIDs []int64) ([]domain.AvailableStock, error) {
+	ctx, span := tracer.Start(ctx, "stockUsecase.GetAvailableStockByProductIDs")
+	defer span.End()
+
+	availableStocks, err := u.stockRepo.GetAvailableStockByProductIDs(ctx, productIDs)
 	if err != nil {
-		slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "getStock", err)
-		return err
+		slog.ErrorContext(ctx, "[stockUsecase] GetAvailableStockByProductIDs", "getAvailableStocks", err)
+		return nil, err
 	}
 
-	warehouse, err := u.warehouseRepo.GetByID(ctx, stock.WarehouseID)
-	if err != nil {
-		slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "getWarehouse", err)
-		return err
+	result := make([]domain.AvailableStock, 0, len(productIDs))
+	for _, productID := range productIDs {
+		result = append(result, domain.AvailableStock{
+			ProductID:      productID,
+			AvailableStock: availableStocks[productID],
+		})
 	}
 
-	if warehouse.ShopID != shopID {
-		slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "invalidShopID", "shopID unauthorized")
-		return domain.ErrUnauthorized
+	return result, nil
+}
+
+func (u *stockUsecase) UpdateQuantity(ctx context.Context, id, shopID, userID int64, req domain.UpdateQuantityRequest) error {
+	ctx, span := tracer.Start(ctx, "stockUsecase.UpdateQuantity")
+	defer span.End()
+
+	stock, err := u.getShopStock(ctx, "UpdateQuantity", id, shopID)
+	if err != nil {
+		return err
 	}
 
 	if stock.Quantity == req.Quantity {
@@ -89,62 +112,157 @@ func (u *stockUsecase) UpdateQuantity(ctx context.Context, id, shopID int64, req
 		return nil
 	}
 
-	availableStock, err := u.stockRepo.GetAvailableStockByProductID(ctx, stock.ProductID)
+	_, err = u.adjust(ctx, "UpdateQuantity", stock, shopID, userID, domain.AdjustmentReasonCorrection, "", func(int64) int64 {
+		return req.Quantity
+	})
 	if err != nil {
-		slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "getAvailableStock", err)
 		return err
 	}
 
-	reservedStock, err := u.reservedStockRepo.GetTotalReservedStockByStockIDAndStatus(ctx, stock.ID, domain.ReservedStockStatusActive)
+	slog.InfoContext(ctx, "[stockUsecase] UpdateQuantity", "quantityUpdated", req.Quantity)
+	return nil
+}
+
+func (u *stockUsecase) AdjustQuantity(ctx context.Context, id, shopID, userID int64, req domain.StockAdjustRequest) (domain.StockAdjustment, error) {
+	ctx, span := tracer.Start(ctx, "stockUsecase.AdjustQuantity")
+	defer span.End()
+
+	stock, err := u.getShopStock(ctx, "AdjustQuantity", id, shopID)
 	if err != nil {
-		slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "getReservedStock", err)
-		return err
+		return domain.StockAdjustment{}, err
 	}
 
-	if req.Quantity < reservedStock {
-		return fmt.Errorf("%w: quantity insufficient for reserved stock at warehouse", domain.ErrInvalidRequest)
+	adjustment, err := u.adjust(ctx, "AdjustQuantity", stock, shopID, userID, req.Reason, req.Note, func(current int64) int64 {
+		return current + req.Delta
+	})
+	if err != nil {
+		return domain.StockAdjustment{}, err
+	}
+
+	slog.InfoContext(ctx, "[stockUsecase] AdjustQuantity", "stockID", id, "delta", req.Delta, "reason", req.Reason)
+	return adjustment, nil
+}
+
+// getShopStock returns the stock row when its warehouse belongs to shopID.
+func (u *stockUsecase) getShopStock(ctx context.Context, method string, id, shopID int64) (domain.Stock, error) {
+	stock, err := u.stockRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockUsecase] "+method, "getStock", err)
+		return stock, err
+	}
+
+	warehouse, err := u.warehouseRepo.GetByID(ctx, stock.WarehouseID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockUsecase] "+method, "getWarehouse", err)
+		return stock, err
 	}
 
-	if err = u.stockRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+	if warehouse.ShopID != shopID {
+		slog.ErrorContext(ctx, "[stockUsecase] "+method, "invalidShopID", "shopID unauthorized")
+		return stock, domain.ErrUnauthorized
+	}
+	return stock, nil
+}
+
+// adjust sets the stock to newQuantity of its locked quantity and records the
+// change in the adjustment ledger in the same transaction.
+func (u *stockUsecase) adjust(ctx context.Context, method string, stock domain.Stock, shopID, userID int64,
+	reason domain.AdjustmentReason, note string, newQuantity func(current int64) int64) (domain.StockAdjustment, error) {
+	adjustment := domain.StockAdjustment{
+		ShopID:      shopID,
+		StockID:     stock.ID,
+		ProductID:   stock.ProductID,
+		WarehouseID: stock.WarehouseID,
+		Reason:      reason,
+		Note:        note,
+	}
+	if userID != 0 {
+		adjustment.UserID = &userID
+	}
+
+	err := u.stockRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		// Lock the stock row for update
-		stock, err := u.stockRepo.LockForUpdate(ctx, id, tx)
+		locked, err := u.stockRepo.LockForUpdate(ctx, stock.ID, tx)
 		if err != nil {
-			slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "lockForUpdate", err)
+			slog.ErrorContext(ctx, "[stockUsecase] "+method, "lockForUpdate", err)
 			return err
 		}
 
-		err = u.stockRepo.UpdateQuantity(ctx, id, req.Quantity, tx)
+		quantity := newQuantity(locked.Quantity)
+		if quantity < 0 {
+			return domain.ErrInsufficientStock
+		}
+
+		reservedStock, err := u.reservedStockRepo.GetTotalReservedStockByStockIDAndStatus(ctx, stock.ID, domain.ReservedStockStatusActive)
 		if err != nil {
-			slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "updateStock", err)
+			slog.ErrorContext(ctx, "[stockUsecase] "+method, "getReservedStock", err)
 			return err
 		}
+		if quantity < reservedStock {
+			return domain.ErrQuantityBelowReserved
+		}
 
-		change := req.Quantity - stock.Quantity
-		updatedStock := availableStock + change
+		// Read under the lock and before the update, so a concurrent change
+		// of this row is included and this one is added once below
+		availableStock, err := u.stockRepo.GetAvailableStockByProductID(ctx, stock.ProductID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockUsecase] "+method, "getAvailableStock", err)
+			return err
+		}
+
+		err = u.stockRepo.UpdateQuantity(ctx, stock.ID, quantity, tx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockUsecase] "+method, "updateStock", err)
+			return err
+		}
+
+		adjustment.QuantityBefore = locked.Quantity
+		adjustment.QuantityAfter = quantity
+		adjustment.Delta = quantity - locked.Quantity
+		err = u.stockAdjustmentRepo.Create(ctx, &adjustment, tx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockUsecase] "+method, "createAdjustment", err)
+			return err
+		}
+
+		updatedStock := availableStock + adjustment.Delta
 		if updatedStock < 0 {
-			slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "insufficientStock", nil)
-			return fmt.Errorf("%w: insufficient stock", domain.ErrInvalidRequest)
+			slog.ErrorContext(ctx, "[stockUsecase] "+method, "insufficientStock", nil)
+			return domain.ErrInsufficientStock
 		}
 
 		err = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
-			ProductID: stock.ProductID,
-			Available: updatedStock,
+			ProductID:   stock.ProductID,
+			Available:   updatedStock,
+			ShopID:      shopID,
+			WarehouseID: stock.WarehouseID,
+			Cause:       domain.StockEventCauseAdjustment,
+			Tx:          tx,
 		})
 		if err != nil {
-			slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "publishStockAvailable", err)
+			slog.ErrorContext(ctx, "[stockUsecase] "+method, "publishStockAvailable", err)
 			return err
 		}
 		return nil
-	}); err != nil {
-		slog.ErrorContext(ctx, "[stockUsecase] UpdateQuantity", "transactionError", err)
-		return err
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockUsecase] "+method, "transactionError", err)
+		return adjustment, err
 	}
 
-	slog.InfoContext(ctx, "[stockUsecase] UpdateQuantity", "quantityUpdated", req.Quantity)
-	return nil
+	metrics.StockAdjustmentsTotal.WithLabelValues(strconv.FormatInt(shopID, 10), string(reason)).Inc()
+
+	if _, err = u.replenishment.Replenish(ctx, stock.ID); err != nil {
+		slog.WarnContext(ctx, "[stockUsecase] "+method, "replenish", err)
+	}
+
+	return adjustment, nil
 }
 
 func (u *stockUsecase) GetListStock(ctx context.Context, shopID int64, param domain.GetListStockRequest) ([]domain.Stock, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "stockUsecase.GetListStock")
+	defer span.End()
+
 	var metadata domain.Metadata
 
 	stocks, err := u.stockRepo.GetListStock(ctx, shopID, param)
@@ -175,3 +293,46 @@ func (u *stockUsecase) GetListStock(ctx context.Context, shopID int64, param dom
 
 	return stocks, metadata, nil
 }
+
+func (u *stockUsecase) GetListStockAdjustment(ctx context.Context, shopID int64, param domain.GetListStockAdjustmentRequest) ([]domain.StockAdjustment, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "stockUsecase.GetListStockAdjustment")
+	defer span.End()
+
+	var metadata domain.Metadata
+
+	adjustments, err := u.stockAdjustmentRepo.GetListStockAdjustment(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockUsecase] GetListStockAdjustment", "getListStockAdjustment", err)
+		return nil, metadata, err
+	}
+
+	count, err := u.stockAdjustmentRepo.GetListStockAdjustmentCount(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockUsecase] GetListStockAdjustment", "getListStockAdjustmentCount", err)
+		return nil, metadata, err
+	}
+
+	metadata = domain.Metadata{
+		TotalData: count,
+		TotalPage: (count + param.Limit - 1) / param.Limit,
+		Page:      param.Page,
+		Limit:     param.Limit,
+		SortBy:    "id",
+		SortOrder: "desc",
+	}
+
+	return adjustments, metadata, nil
+}
+
+func (u *stockUsecase) GetAdjustmentReport(ctx context.Context, shopID int64, param domain.StockAdjustmentReportRequest) ([]domain.StockAdjustmentReasonTotal, error) {
+	ctx, span := tracer.Start(ctx, "stockUsecase.GetAdjustmentReport")
+	defer span.End()
+
+	totals, err := u.stockAdjustmentRepo.GetTotalsByReason(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockUsecase] GetAdjustmentReport", "getTotalsByReason", err)
+		return nil, err
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"warehouse-service/app/domain"
+	"warehouse-service/config"
+	"warehouse-service/pkg/ctxutil"
+)
+
+type stockAlertUsecase struct {
+	alertRepo          domain.StockAlertRepository
+	stockRepo          domain.StockRepository
+	stockPublishBroker domain.BrokerPublisher
+	live               *config.Live
+}
+
+func NewStockAlertUsecase(alertRepo domain.StockAlertRepository, stockRepo domain.StockRepository, stockPublishBroker domain.BrokerPublisher, live *config.Live) domain.StockAlertUsecase {
+	return &stockAlertUsecase{alertRepo, stockRepo, stockPublishBroker, live}
+}
+
+func (u *stockAlertUsecase) UpsertThreshold(ctx context.Context, shopID int64, req domain.StockAlertThresholdUpsertRequest) (*domain.StockAlertThreshold, error) {
+	ctx, span := tracer.Start(ctx, "stockAlertUsecase.UpsertThreshold")
+	defer span.End()
+
+	if req.ProductID != 0 {
+		productShopID, err := u.stockRepo.GetShopIDByProductID(ctx, req.ProductID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockAlertUsecase] UpsertThreshold", "getShopIDByProductID", err)
+			return nil, err
+		}
+		if productShopID != shopID {
+			slog.ErrorContext(ctx, "[stockAlertUsecase] UpsertThreshold", "invalidShopID", "shopID")
+			return nil, domain.ErrUnauthorized
+		}
+	}
+
+	threshold := &domain.StockAlertThreshold{
+		ShopID:       shopID,
+		ProductID:    req.ProductID,
+		LowThreshold: req.LowThreshold,
+	}
+
+	if err := u.alertRepo.UpsertThreshold(ctx, threshold); err != nil {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] UpsertThreshold", "upsertThreshold", err)
+		return nil, err
+	}
+
+	return threshold, nil
+}
+
+func (u *stockAlertUsecase) GetThresholds(ctx context.Context, shopID int64) ([]domain.StockAlertThreshold, error) {
+	ctx, span := tracer.Start(ctx, "stockAlertUsecase.GetThresholds")
+	defer span.End()
+
+	thresholds, err := u.alertRepo.GetThresholdsByShopID(ctx, shopID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] GetThresholds", "getThresholds", err)
+		return nil, err
+	}
+
+	if thresholds == nil {
+		thresholds = []domain.StockAlertThreshold{}
+	}
+
+	return thresholds, nil
+}
+
+func (u *stockAlertUsecase) DeleteThreshold(ctx context.Context, id, shopID int64) error {
+	ctx, span := tracer.Start(ctx, "stockAlertUsecase.DeleteThreshold")
+	defer span.End()
+
+	threshold, err := u.alertRepo.GetThresholdByID(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] DeleteThreshold", "getThreshold", err)
+		return err
+	}
+
+	if threshold.ShopID != shopID {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] DeleteThreshold", "invalidShopID", "shopID")
+		return domain.ErrUnauthorized
+	}
+
+	if err = u.alertRepo.DeleteThreshold(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] DeleteThreshold", "deleteThreshold", err)
+		return err
+	}
+
+	return nil
+}
+
+// Evaluate publishes an alert only when availability crosses into a different
+// state, so repeated updates within the same band stay silent.
+func (u *stockAlertUsecase) Evaluate(ctx context.Context, productID, available int64) error {
+	ctx, span := tracer.Start(ctx, "stockAlertUsecase.Evaluate")
+	defer span.End()
+
+	shopID, err := u.stockRepo.GetShopIDByProductID(ctx, productID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] Evaluate", "getShopIDByProductID", err)
+		return err
+	}
+
+	// Without a product or shop threshold the configured default applies
+	lowThreshold := u.live.Get().Alert.DefaultLowThreshold
+	threshold, err := u.alertRepo.GetEffectiveThreshold(ctx, shopID, productID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] Evaluate", "getEffectiveThreshold", err)
+		return err
+	}
+	if err == nil {
+		lowThreshold = threshold.LowThreshold
+	}
+
+	state := domain.StockAlertStateOK
+	switch {
+	case available <= 0:
+		state = domain.StockAlertStateOut
+	case available <= lowThreshold:
+		state = domain.StockAlertStateLow
+	}
+
+	previous, changed, err := u.alertRepo.SwapState(ctx, shopID, productID, state)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] Evaluate", "swapState", err)
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	var alertType domain.StockAlertType
+	switch state {
+	case domain.StockAlertStateOut:
+		alertType = domain.StockAlertTypeOut
+	case domain.StockAlertStateLow:
+		alertType = domain.StockAlertTypeLow
+	default:
+		if previous == domain.StockAlertStateOK {
+			return nil
+		}
+		alertType = domain.StockAlertTypeRestocked
+	}
+
+	err = u.stockPublishBroker.PublishStockAlert(ctx, domain.StockAlertMessage{
+		Type:         alertType,
+		ShopID:       shopID,
+		ProductID:    productID,
+		Available:    available,
+		LowThreshold: lowThreshold,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockAlertUsecase] Evaluate", "publishStockAlert", err)
+		return err
+	}
+
+	return nil
+}
+
+// alertingPublisher evaluates alert thresholds after every successful
+// availability publish so usecases don't have to call Evaluate themselves.
+// Inside a transaction the evaluation waits for the commit, so a rolled back
+// change never stores alert state or emits an alert.
+type alertingPublisher struct {
+	domain.BrokerPublisher
+	alerts domain.StockAlertUsecase
+}
+
+func NewAlertingPublisher(publisher domain.BrokerPublisher, alerts domain.StockAlertUsecase) domain.BrokerPublisher {
+	return &alertingPublisher{publisher, alerts}
+}
+
+func (p *alertingPublisher) PublishStockAvailable(ctx context.Context, data domain.StockMessage) error {
+	if err := p.BrokerPublisher.PublishStockAvailable(ctx, data); err != nil {
+		return err
+	}
+
+	// New products start with nothing available, that is not running out
+	if data.Cause == domain.StockEventCauseInit {
+		return nil
+	}
+
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		if err := p.alerts.Evaluate(ctx, data.ProductID, data.Available); err != nil {
+			slog.WarnContext(ctx, "[alertingPublisher] PublishStockAvailable", "evaluate", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"warehouse-service/app/domain"
+	"warehouse-service/config"
+	"warehouse-service/pkg/ctxutil"
+)
+
+// memoryAlertRepo stores alert states in memory; a product without a stored
+// state is ok, like a missing row.
+type memoryAlertRepo struct {
+	domain.StockAlertRepository
+
+	threshold *domain.StockAlertThreshold
+	states    map[int64]domain.StockAlertState
+}
+
+func (r *memoryAlertRepo) GetEffectiveThreshold(ctx context.Context, shopID, productID int64) (domain.StockAlertThreshold, error) {
+	if r.threshold == nil {
+		return domain.StockAlertThreshold{}, domain.ErrNotFound
+	}
+	return *r.threshold, nil
+}
+
+func (r *memoryAlertRepo) SwapState(ctx context.Context, shopID, productID int64, state domain.StockAlertState) (domain.StockAlertState, bool, error) {
+	previous, ok := r.states[productID]
+	if !ok {
+		previous = domain.StockAlertStateOK
+	}
+	r.states[productID] = state
+	return previous, previous != state, nil
+}
+
+type shopOfProduct struct {
+	domain.StockRepository
+}
+
+func (shopOfProduct) GetShopIDByProductID(ctx context.Context, productID int64) (int64, error) {
+	return 7, nil
+}
+
+type alertRecorder struct {
+	domain.BrokerPublisher
+	alerts []domain.StockAlertMessage
+}
+
+func (b *alertRecorder) PublishStockAlert(ctx context.Context, data domain.StockAlertMessage) error {
+	b.alerts = append(b.alerts, data)
+	return nil
+}
+
+func (b *alertRecorder) PublishStockAvailable(ctx context.Context, data domain.StockMessage) error {
+	return nil
+}
+
+func newTestStockAlertUsecase(threshold *domain.StockAlertThreshold) (*stockAlertUsecase, *alertRecorder) {
+	broker := &alertRecorder{}
+	live := config.NewLive(&config.Config{Alert: config.AlertConfig{DefaultLowThreshold: 10}})
+	repo := &memoryAlertRepo{threshold: threshold, states: map[int64]domain.StockAlertState{}}
+	return &stockAlertUsecase{repo, shopOfProduct{}, broker, live}, broker
+}
+
+func TestStockAlertEvaluateIsEdgeTriggered(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold *domain.StockAlertThreshold
+		available []int64
+		// alert published after each availability, "" for none
+		want []domain.StockAlertType
+	}{
+		{
+			name:      "stays silent within a band",
+			threshold: &domain.StockAlertThreshold{LowThreshold: 5},
+			available: []int64{20, 15, 6},
+			want:      []domain.StockAlertType{"", "", ""},
+		},
+		{
+			name:      "low once, then out, then restocked",
+			threshold: &domain.StockAlertThreshold{LowThreshold: 5},
+			available: []int64{5, 3, 1, 0, -2, 8, 9},
+			want: []domain.StockAlertType{
+				domain.StockAlertTypeLow, "", "",
+				domain.StockAlertTypeOut, "",
+				domain.StockAlertTypeRestocked, "",
+			},
+		},
+		{
+			name:      "out to low is a low alert",
+			threshold: &domain.StockAlertThreshold{LowThreshold: 5},
+			available: []int64{0, 2, 6},
+			want:      []domain.StockAlertType{domain.StockAlertTypeOut, domain.StockAlertTypeLow, domain.StockAlertTypeRestocked},
+		},
+		{
+			name:      "default threshold without a configured one",
+			available: []int64{11, 10, 11},
+			want:      []domain.StockAlertType{"", domain.StockAlertTypeLow, domain.StockAlertTypeRestocked},
+		},
+		{
+			name:      "zero threshold only alerts when out",
+			threshold: &domain.StockAlertThreshold{LowThreshold: 0},
+			available: []int64{1, 0, 1},
+			want:      []domain.StockAlertType{"", domain.StockAlertTypeOut, domain.StockAlertTypeRestocked},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, broker := newTestStockAlertUsecase(tt.threshold)
+
+			for i, available := range tt.available {
+				before := len(broker.alerts)
+				if err := u.Evaluate(context.Background(), 3, available); err != nil {
+					t.Fatalf("Evaluate(%d): %v", available, err)
+				}
+
+				var got domain.StockAlertType
+				switch len(broker.alerts) - before {
+				case 0:
+				case 1:
+					got = broker.alerts[before].Type
+				default:
+					t.Fatalf("Evaluate(%d) published %d alerts", available, len(broker.alerts)-before)
+				}
+				if got != tt.want[i] {
+					t.Fatalf("Evaluate(%d) alert = %q, want %q", available, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAlertingPublisherWaitsForCommit(t *testing.T) {
+	u, broker := newTestStockAlertUsecase(&domain.StockAlertThreshold{LowThreshold: 5})
+	publisher := NewAlertingPublisher(broker, u)
+
+	// new stock rows start empty, that is not an out alert
+	if err := publisher.PublishStockAvailable(context.Background(), domain.StockMessage{ProductID: 3, Cause: domain.StockEventCauseInit}); err != nil {
+		t.Fatalf("PublishStockAvailable: %v", err)
+	}
+	if len(broker.alerts) != 0 {
+		t.Fatalf("init published %d alerts", len(broker.alerts))
+	}
+
+	txCtx, commit := ctxutil.WithAfterCommit(context.Background())
+	if err := publisher.PublishStockAvailable(txCtx, domain.StockMessage{ProductID: 3, Available: 0, Cause: domain.StockEventCauseAdjustment}); err != nil {
+		t.Fatalf("PublishStockAvailable: %v", err)
+	}
+	if len(broker.alerts) != 0 {
+		t.Fatalf("alert published before the commit")
+	}
+
+	commit(context.Background())
+	types := make([]domain.StockAlertType, 0, len(broker.alerts))
+	for _, alert := range broker.alerts {
+		types = append(types, alert.Type)
+	}
+	if !slices.Equal(types, []domain.StockAlertType{domain.StockAlertTypeOut}) {
+		t.Fatalf("alerts after commit = %v, want [out]", types)
+	}
+}
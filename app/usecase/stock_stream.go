@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+)
+
+const stockStreamSubscriberBuffer = 64
+
+type stockStreamSubscriber struct {
+	shopID int64
+	events chan domain.StockStreamEvent
+}
+
+// stockStreamUsecase is an in-process fan-out hub. Recent events are kept in
+// a ring buffer so reconnecting clients can resume from Last-Event-ID.
+type stockStreamUsecase struct {
+	mu          sync.Mutex
+	lastID      int64
+	buffer      []domain.StockStreamEvent
+	next        int
+	size        int
+	subscribers map[*stockStreamSubscriber]struct{}
+	closed      bool
+}
+
+func NewStockStreamUsecase(bufferSize int) domain.StockStreamUsecase {
+	return &stockStreamUsecase{
+		// Seeding with the start time keeps IDs increasing across restarts,
+		// so an ID from a previous process is detected as out of range.
+		lastID:      time.Now().UnixMilli() * 1000,
+		buffer:      make([]domain.StockStreamEvent, bufferSize),
+		subscribers: make(map[*stockStreamSubscriber]struct{}),
+	}
+}
+
+func (u *stockStreamUsecase) Publish(shopID int64, eventType string, data any) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.closed {
+		return
+	}
+
+	u.lastID++
+	event := domain.StockStreamEvent{
+		ID:     u.lastID,
+		Type:   eventType,
+		ShopID: shopID,
+		Data:   data,
+	}
+
+	if len(u.buffer) > 0 {
+		u.buffer[u.next] = event
+		u.next = (u.next + 1) % len(u.buffer)
+		if u.size < len(u.buffer) {
+			u.size++
+		}
+	}
+
+	for sub := range u.subscribers {
+		if sub.shopID != shopID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// A subscriber that cannot keep up is disconnected; the client
+			// reconnects with Last-Event-ID and catches up from the buffer.
+			slog.Warn("[stockStreamUsecase] Publish", "dropSlowSubscriber", shopID)
+			delete(u.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (u *stockStreamUsecase) Subscribe(shopID int64, lastEventID int64) domain.StockStreamSubscription {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	sub := &stockStreamSubscriber{
+		shopID: shopID,
+		events: make(chan domain.StockStreamEvent, stockStreamSubscriberBuffer),
+	}
+	if u.closed {
+		close(sub.events)
+		return domain.StockStreamSubscription{Events: sub.events, Cancel: func() {}}
+	}
+	u.subscribers[sub] = struct{}{}
+
+	var backlog []domain.StockStreamEvent
+	if lastEventID > 0 {
+		backlog = u.backlog(shopID, lastEventID)
+	}
+
+	return domain.StockStreamSubscription{
+		Backlog: backlog,
+		Events:  sub.events,
+		Cancel: func() {
+			u.mu.Lock()
+			defer u.mu.Unlock()
+			if _, ok := u.subscribers[sub]; ok {
+				delete(u.subscribers, sub)
+				close(sub.events)
+			}
+		},
+	}
+}
+
+// backlog must be called with u.mu held.
+func (u *stockStreamUsecase) backlog(shopID int64, lastEventID int64) []domain.StockStreamEvent {
+	oldest := u.lastID - int64(u.size) + 1
+	if lastEventID > u.lastID || lastEventID < oldest-1 {
+		return []domain.StockStreamEvent{{
+			ID:     u.lastID,
+			Type:   domain.StockStreamEventTypeReset,
+			ShopID: shopID,
+		}}
+	}
+
+	var events []domain.StockStreamEvent
+	start := (u.next - u.size + len(u.buffer)) % max(len(u.buffer), 1)
+	for i := 0; i < u.size; i++ {
+		event := u.buffer[(start+i)%len(u.buffer)]
+		if event.ID > lastEventID && event.ShopID == shopID {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (u *stockStreamUsecase) Close() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.closed = true
+	for sub := range u.subscribers {
+		delete(u.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// streamPublisher forwards stock and transfer changes to SSE subscribers
+// once they are published to the broker and the caller's transaction has
+// committed.
+type streamPublisher struct {
+	domain.BrokerPublisher
+	stream domain.StockStreamUsecase
+}
+
+func NewStreamPublisher(publisher domain.BrokerPublisher, stream domain.StockStreamUsecase) domain.BrokerPublisher {
+	return &streamPublisher{publisher, stream}
+}
+
+func (p *streamPublisher) PublishStockAvailable(ctx context.Context, data domain.StockMessage) error {
+	if err := p.BrokerPublisher.PublishStockAvailable(ctx, data); err != nil {
+		return err
+	}
+
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		p.stream.Publish(data.ShopID, domain.StockEventTypeAvailabilityChanged, stockAvailabilityEventData{
+			ProductID:   data.ProductID,
+			WarehouseID: data.WarehouseID,
+			Available:   data.Available,
+			Cause:       data.Cause,
+		})
+	})
+	return nil
+}
+
+func (p *streamPublisher) PublishStockTransfer(ctx context.Context, data domain.StockTransferMessage) error {
+	if err := p.BrokerPublisher.PublishStockTransfer(ctx, data); err != nil {
+		return err
+	}
+
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		p.stream.Publish(data.ShopID, domain.StockTransferEventTypeUpdated, data)
+	})
+	return nil
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"testing"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+)
+
+// memoryStockRepo keeps stock rows and their active reservations in memory.
+// WithTransaction restores both when fn fails, like a rollback.
+type memoryStockRepo struct {
+	domain.StockRepository
+
+	stocks   map[int64]domain.Stock
+	reserved map[int64]int64 // active reservations by stock ID
+}
+
+func (r *memoryStockRepo) GetByID(ctx context.Context, id int64) (domain.Stock, error) {
+	stock, ok := r.stocks[id]
+	if !ok {
+		return stock, domain.ErrNotFound
+	}
+	return stock, nil
+}
+
+func (r *memoryStockRepo) LockForUpdate(ctx context.Context, id int64, tx *sql.Tx) (domain.Stock, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryStockRepo) UpdateQuantity(ctx context.Context, id, quantity int64, tx *sql.Tx) error {
+	stock := r.stocks[id]
+	stock.Quantity = quantity
+	r.stocks[id] = stock
+	return nil
+}
+
+func (r *memoryStockRepo) GetAvailableStockByProductID(ctx context.Context, productID int64) (int64, error) {
+	var available int64
+	for id, stock := range r.stocks {
+		if stock.ProductID == productID {
+			available += stock.Quantity - r.reserved[id]
+		}
+	}
+	return available, nil
+}
+
+func (r *memoryStockRepo) WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	stocks := maps.Clone(r.stocks)
+	ctx, commit := ctxutil.WithAfterCommit(ctx)
+	if err := fn(ctx, nil); err != nil {
+		r.stocks = stocks
+		return err
+	}
+	commit(ctx)
+	return nil
+}
+
+type memoryReservations struct {
+	domain.ReservedStockRepository
+	stocks *memoryStockRepo
+}
+
+func (r memoryReservations) GetTotalReservedStockByStockIDAndStatus(ctx context.Context, stockID int64, status domain.ReservedStockStatus) (int64, error) {
+	if status != domain.ReservedStockStatusActive {
+		return 0, nil
+	}
+	return r.stocks.reserved[stockID], nil
+}
+
+type memoryAdjustments struct {
+	domain.StockAdjustmentRepository
+	created []domain.StockAdjustment
+}
+
+func (r *memoryAdjustments) Create(ctx context.Context, adjustment *domain.StockAdjustment, tx *sql.Tx) error {
+	adjustment.ID = int64(len(r.created) + 1)
+	r.created = append(r.created, *adjustment)
+	return nil
+}
+
+type stockMessageRecorder struct {
+	domain.BrokerPublisher
+	messages []domain.StockMessage
+}
+
+func (b *stockMessageRecorder) PublishStockAvailable(ctx context.Context, data domain.StockMessage) error {
+	b.messages = append(b.messages, data)
+	return nil
+}
+
+type noReplenishment struct {
+	domain.ReplenishmentUsecase
+}
+
+func (noReplenishment) Replenish(ctx context.Context, stockID int64) (*domain.StockTransfer, error) {
+	return nil, nil
+}
+
+func TestStockAdjust(t *testing.T) {
+	tests := []struct {
+		name          string
+		quantity      int64
+		reserved      int64
+		newQuantity   func(int64) int64
+		wantErr       error
+		wantAfter     int64
+		wantAvailable int64
+	}{
+		{
+			name:          "increase",
+			quantity:      10,
+			reserved:      3,
+			newQuantity:   func(current int64) int64 { return current + 5 },
+			wantAfter:     15,
+			wantAvailable: 12,
+		},
+		{
+			name:          "decrease down to the reserved quantity",
+			quantity:      10,
+			reserved:      4,
+			newQuantity:   func(current int64) int64 { return current - 6 },
+			wantAfter:     4,
+			wantAvailable: 0,
+		},
+		{
+			name:        "decrease below the reserved quantity",
+			quantity:    10,
+			reserved:    4,
+			newQuantity: func(current int64) int64 { return current - 7 },
+			wantErr:     domain.ErrQuantityBelowReserved,
+		},
+		{
+			name:        "set below the reserved quantity",
+			quantity:    10,
+			reserved:    4,
+			newQuantity: func(int64) int64 { return 2 },
+			wantErr:     domain.ErrQuantityBelowReserved,
+		},
+		{
+			name:        "negative result",
+			quantity:    10,
+			newQuantity: func(current int64) int64 { return current - 11 },
+			wantErr:     domain.ErrInsufficientStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stocks := &memoryStockRepo{
+				stocks:   map[int64]domain.Stock{1: {ID: 1, ProductID: 20, WarehouseID: 30, Quantity: tt.quantity}},
+				reserved: map[int64]int64{1: tt.reserved},
+			}
+			adjustments := &memoryAdjustments{}
+			broker := &stockMessageRecorder{}
+			u := &stockUsecase{
+				stockRepo:           stocks,
+				reservedStockRepo:   memoryReservations{stocks: stocks},
+				stockPublishBroker:  broker,
+				replenishment:       noReplenishment{},
+				stockAdjustmentRepo: adjustments,
+			}
+
+			// The passed row is stale, the locked quantity must be used
+			stale := domain.Stock{ID: 1, ProductID: 20, WarehouseID: 30, Quantity: 999}
+			adjustment, err := u.adjust(context.Background(), "test", stale, 7, 8, domain.AdjustmentReasonCorrection, "note", tt.newQuantity)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if got := stocks.stocks[1].Quantity; got != tt.quantity {
+					t.Fatalf("quantity = %d after a failed adjustment, want %d", got, tt.quantity)
+				}
+				if len(adjustments.created) != 0 || len(broker.messages) != 0 {
+					t.Fatalf("failed adjustment recorded %d ledger rows and %d events", len(adjustments.created), len(broker.messages))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("adjust: %v", err)
+			}
+
+			if got := stocks.stocks[1].Quantity; got != tt.wantAfter {
+				t.Fatalf("quantity = %d, want %d", got, tt.wantAfter)
+			}
+			if len(adjustments.created) != 1 {
+				t.Fatalf("ledger rows = %d, want 1", len(adjustments.created))
+			}
+			ledger := adjustments.created[0]
+			if ledger.QuantityBefore != tt.quantity || ledger.QuantityAfter != tt.wantAfter || ledger.Delta != tt.wantAfter-tt.quantity {
+				t.Fatalf("ledger = %+v, want %d -> %d", ledger, tt.quantity, tt.wantAfter)
+			}
+			if ledger.ShopID != 7 || ledger.UserID == nil || *ledger.UserID != 8 || ledger.StockID != 1 || ledger.ProductID != 20 {
+				t.Fatalf("ledger = %+v, want shop 7, user 8, stock 1, product 20", ledger)
+			}
+			if adjustment.ID != ledger.ID {
+				t.Fatalf("returned adjustment %d, ledger row %d", adjustment.ID, ledger.ID)
+			}
+			if len(broker.messages) != 1 || broker.messages[0].Available != tt.wantAvailable {
+				t.Fatalf("events = %+v, want one with available %d", broker.messages, tt.wantAvailable)
+			}
+		})
+	}
+}
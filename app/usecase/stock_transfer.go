@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"log/slog"
 	"warehouse-service/app/domain"
+	"warehouse-service/pkg/ctxutil"
+	"warehouse-service/pkg/metrics"
 )
 
 type stockTransferUsecase struct {
@@ -24,6 +26,22 @@ func NewStockTransferUsecase(stockTransferRepo domain.StockTransferRepository,
 }
 
 func (u *stockTransferUsecase) CreateTransfer(ctx context.Context, shopID int64, req domain.StockTransferCreateRequest) (*domain.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "stockTransferUsecase.CreateTransfer")
+	defer span.End()
+
+	var stockTransfer *domain.StockTransfer
+	if err := u.stockTransferRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		stockTransfer, err = u.CreateTransferTx(ctx, shopID, req, tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stockTransfer, nil
+}
+
+func (u *stockTransferUsecase) CreateTransferTx(ctx context.Context, shopID int64, req domain.StockTransferCreateRequest, tx *sql.Tx) (*domain.StockTransfer, error) {
 	fromWarehouse, err := u.warehouseRepo.GetByID(ctx, req.FromWarehouse)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockTransferUsecase] CreateTransfer", "getFromWarehouse", err)
@@ -38,7 +56,12 @@ func (u *stockTransferUsecase) CreateTransfer(ctx context.Context, shopID int64,
 
 	if fromWarehouse.ShopID != toWarehouse.ShopID || fromWarehouse.ShopID != shopID {
 		slog.ErrorContext(ctx, "[stockTransferUsecase] CreateTransfer", "invalidShopID", "shopID")
-		return nil, domain.ErrInvalidRequest
+		return nil, domain.ErrWarehouseShopMismatch
+	}
+
+	if !fromWarehouse.Active || !toWarehouse.Active {
+		slog.ErrorContext(ctx, "[stockTransferUsecase] CreateTransfer", "inactiveWarehouse", "warehouse")
+		return nil, domain.ErrWarehouseInactive
 	}
 
 	fromWarehouseStock, err := u.stockRepo.GetByProductIDAndWarehouseID(ctx, req.ProductID, req.FromWarehouse)
@@ -55,7 +78,7 @@ func (u *stockTransferUsecase) CreateTransfer(ctx context.Context, shopID int64,
 
 	if (fromWarehouseStock.Quantity - reservedStockFromWarehouse) < req.Quantity {
 		slog.ErrorContext(ctx, "[stockTransferUsecase] CreateTransfer", "insufficientStock", "fromWarehouseStock")
-		return nil, domain.ErrInvalidRequest
+		return nil, domain.ErrInsufficientStock
 	}
 
 	// Create the stock transfer
@@ -68,17 +91,34 @@ func (u *stockTransferUsecase) CreateTransfer(ctx context.Context, shopID int64,
 		Description:   req.Description,
 	}
 	// Create the stock transfer in the database
-	err = u.stockTransferRepo.Create(ctx, stockTransfer)
+	err = u.stockTransferRepo.Create(ctx, stockTransfer, tx)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockTransferUsecase] CreateTransfer", "createTransfer", err)
 		return nil, err
 	}
 
+	// Announce the transfer only once it exists
+	created := *stockTransfer
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		err := u.stockPublishBroker.PublishStockTransfer(ctx, domain.StockTransferMessage{
+			ShopID:   shopID,
+			Transfer: created,
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "[stockTransferUsecase] CreateTransfer", "publishStockTransfer", err)
+		}
+
+		metrics.TransfersTotal.WithLabelValues("", string(created.Status)).Inc()
+	})
+
 	slog.InfoContext(ctx, "[stockTransferUsecase] CreateTransfer", "transfer", stockTransfer)
 	return stockTransfer, nil
 }
 
 func (u *stockTransferUsecase) GetTransferByID(ctx context.Context, id int64, shopID *int64) (domain.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "stockTransferUsecase.GetTransferByID")
+	defer span.End()
+
 	var st domain.StockTransfer
 	var err error
 
@@ -103,12 +143,28 @@ func (u *stockTransferUsecase) GetTransferByID(ctx context.Context, id int64, sh
 }
 
 func (u *stockTransferUsecase) UpdateTransferStatus(ctx context.Context, id int64, req domain.StockTransferUpdateRequest) error {
-	st, err := u.stockTransferRepo.GetByID(ctx, id)
-	if err != nil {
-		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getTransfer", err)
+	ctx, span := tracer.Start(ctx, "stockTransferUsecase.UpdateTransferStatus")
+	defer span.End()
+
+	// The transfer row is locked so concurrent status changes see each
+	// other's result instead of both moving stock from the same status
+	if err := u.stockTransferRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		st, err := u.stockTransferRepo.LockForUpdate(ctx, id, tx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "lockTransfer", err)
+			return err
+		}
+
+		return u.UpdateTransferStatusTx(ctx, &st, req, tx)
+	}); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "transactionError", err)
 		return err
 	}
 
+	return nil
+}
+
+func (u *stockTransferUsecase) UpdateTransferStatusTx(ctx context.Context, st *domain.StockTransfer, req domain.StockTransferUpdateRequest, tx *sql.Tx) error {
 	fromWarehouseStock, err := u.stockRepo.GetByProductIDAndWarehouseID(ctx, st.ProductID, st.FromWarehouse)
 	if err != nil {
 		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getFromWarehouseStock", err)
@@ -120,118 +176,126 @@ func (u *stockTransferUsecase) UpdateTransferStatus(ctx context.Context, id int6
 		return err
 	}
 
-	availableStock, err := u.stockRepo.GetAvailableStockByProductID(ctx, st.ProductID)
+	fromWarehouse, err := u.warehouseRepo.GetByID(ctx, st.FromWarehouse)
 	if err != nil {
-		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getAvailableStock", err)
+		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getFromWarehouse", err)
 		return err
 	}
 
-	var updateFromWarehouseStock, updateToWarehouseStock bool
+	var changedStock domain.Stock
+	var change int64
+	changedWarehouse := st.FromWarehouse
 
 	switch req.Status {
 	case domain.TransferStatusInProgress:
 		if st.Status != domain.TransferStatusNotStarted {
-			return domain.ErrInvalidRequest
-		}
-
-		reservedStockFromWarehouse, err := u.reservedStockRepo.GetTotalReservedStockByStockIDAndStatus(ctx, fromWarehouseStock.ID, domain.ReservedStockStatusActive)
-		if err != nil {
-			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getReservedStockFromWarehouse", err)
-			return err
+			return domain.ErrTransferInvalidState
 		}
-		if fromWarehouseStock.Quantity-reservedStockFromWarehouse < st.Quantity {
-			return domain.ErrInvalidRequest
-		}
-
-		fromWarehouseStock.Quantity -= st.Quantity
-		availableStock -= st.Quantity
-		updateFromWarehouseStock = true
+		changedStock, change = fromWarehouseStock, -st.Quantity
 
 	case domain.TransferStatusCompleted:
 		if st.Status != domain.TransferStatusInProgress {
-			return domain.ErrInvalidRequest
+			return domain.ErrTransferInvalidState
 		}
-
-		toWarehouseStock.Quantity += st.Quantity
-		availableStock += st.Quantity
-		updateToWarehouseStock = true
+		changedStock, change = toWarehouseStock, st.Quantity
+		changedWarehouse = st.ToWarehouse
 
 	case domain.TransferStatusReverted:
 		if st.Status != domain.TransferStatusInProgress {
-			return domain.ErrInvalidRequest
+			return domain.ErrTransferInvalidState
 		}
-
-		fromWarehouseStock.Quantity += st.Quantity
-		availableStock += st.Quantity
-		updateFromWarehouseStock = true
+		changedStock, change = fromWarehouseStock, st.Quantity
 
 	case domain.TransferStatusFailed:
 		if st.Status != domain.TransferStatusInProgress {
-			return domain.ErrInvalidRequest
+			return domain.ErrTransferInvalidState
 		}
 	default:
-		return domain.ErrInvalidRequest
+		return domain.ErrTransferInvalidState
 	}
 
-	st.Status = req.Status
-	st.Description = req.Description
-
-	if err = u.stockTransferRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		if updateFromWarehouseStock {
-			// Lock the stock row for update
-			_, err = u.stockRepo.LockForUpdate(ctx, fromWarehouseStock.ID, tx)
-			if err != nil {
-				slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "lockFromWarehouseStock", err)
-				return err
-			}
-			// Update the stock quantity
-			err = u.stockRepo.UpdateQuantity(ctx, fromWarehouseStock.ID, fromWarehouseStock.Quantity, tx)
-			if err != nil {
-				slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "updateFromWarehouseStock", err)
-				return err
-			}
+	var availableStock int64
+	if change != 0 {
+		// Lock the stock row for update
+		stock, err := u.stockRepo.LockForUpdate(ctx, changedStock.ID, tx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "lockStock", err)
+			return err
 		}
 
-		if updateToWarehouseStock {
-			// Lock the stock row for update
-			_, err = u.stockRepo.LockForUpdate(ctx, toWarehouseStock.ID, tx)
+		if change < 0 {
+			reservedStockFromWarehouse, err := u.reservedStockRepo.GetTotalReservedStockByStockIDAndStatus(ctx, stock.ID, domain.ReservedStockStatusActive)
 			if err != nil {
-				slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "lockToWarehouseStock", err)
+				slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getReservedStockFromWarehouse", err)
 				return err
 			}
-			// Update the stock quantity in the to warehouse
-			err = u.stockRepo.UpdateQuantity(ctx, toWarehouseStock.ID, toWarehouseStock.Quantity, tx)
-			if err != nil {
-				slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "updateToWarehouseStock", err)
-				return err
+			if stock.Quantity-reservedStockFromWarehouse < st.Quantity {
+				return domain.ErrInsufficientStock
 			}
 		}
 
-		err = u.stockTransferRepo.UpdateStatus(ctx, st, tx)
+		availableStock, err = u.stockRepo.GetAvailableStockByProductID(ctx, st.ProductID)
 		if err != nil {
-			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "updateStatus", err)
+			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getAvailableStock", err)
 			return err
 		}
+		availableStock += change
 
-		if err = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
-			ProductID: fromWarehouseStock.ProductID,
-			Available: availableStock,
-		}); err != nil {
-			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "publishStockAvailable", err)
+		// Update the stock quantity
+		err = u.stockRepo.UpdateQuantity(ctx, stock.ID, stock.Quantity+change, tx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "updateStock", err)
 			return err
 		}
+	} else {
+		availableStock, err = u.stockRepo.GetAvailableStockByProductID(ctx, st.ProductID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "getAvailableStock", err)
+			return err
+		}
+	}
+
+	previousStatus := st.Status
+	st.Status = req.Status
+	st.Description = req.Description
+
+	err = u.stockTransferRepo.UpdateStatus(ctx, *st, tx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "updateStatus", err)
+		return err
+	}
 
-		return nil
+	if err = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
+		ProductID:   st.ProductID,
+		Available:   availableStock,
+		ShopID:      fromWarehouse.ShopID,
+		WarehouseID: changedWarehouse,
+		Cause:       domain.StockEventCauseTransfer,
+		Tx:          tx,
+	}); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "publishStockAvailable", err)
+		return err
+	}
 
+	if err = u.stockPublishBroker.PublishStockTransfer(ctx, domain.StockTransferMessage{
+		ShopID:         fromWarehouse.ShopID,
+		PreviousStatus: previousStatus,
+		Transfer:       *st,
 	}); err != nil {
-		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "transactionError", err)
+		slog.ErrorContext(ctx, "[stockTransferUsecase] UpdateTransferStatus", "publishStockTransfer", err)
 		return err
 	}
 
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		metrics.TransfersTotal.WithLabelValues(string(previousStatus), string(req.Status)).Inc()
+	})
 	return nil
 }
 
 func (u *stockTransferUsecase) GetListStockTransfer(ctx context.Context, shopID int64, param domain.GetListStockTransferRequest) ([]domain.StockTransfer, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "stockTransferUsecase.GetListStockTransfer")
+	defer span.End()
+
 	var stockTransfers []domain.StockTransfer
 	var err error
 
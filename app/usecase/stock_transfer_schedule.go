@@ -0,0 +1,371 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"warehouse-service/app/domain"
+)
+
+const dueScheduleBatchSize = 100
+
+type stockTransferScheduleUsecase struct {
+	scheduleRepo         domain.StockTransferScheduleRepository
+	warehouseRepo        domain.WarehouseRepository
+	stockRepo            domain.StockRepository
+	reservedStockRepo    domain.ReservedStockRepository
+	stockTransferUsecase domain.StockTransferUsecase
+}
+
+func NewStockTransferScheduleUsecase(scheduleRepo domain.StockTransferScheduleRepository,
+	warehouseRepo domain.WarehouseRepository,
+	stockRepo domain.StockRepository,
+	reservedStockRepo domain.ReservedStockRepository,
+	stockTransferUsecase domain.StockTransferUsecase) domain.StockTransferScheduleUsecase {
+	return &stockTransferScheduleUsecase{scheduleRepo, warehouseRepo, stockRepo, reservedStockRepo, stockTransferUsecase}
+}
+
+func (u *stockTransferScheduleUsecase) CreateSchedule(ctx context.Context, shopID int64, req domain.StockTransferScheduleCreateRequest) (*domain.StockTransferSchedule, error) {
+	ctx, span := tracer.Start(ctx, "stockTransferScheduleUsecase.CreateSchedule")
+	defer span.End()
+
+	fromWarehouse, err := u.warehouseRepo.GetByID(ctx, req.FromWarehouse)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CreateSchedule", "getFromWarehouse", err)
+		return nil, err
+	}
+
+	toWarehouse, err := u.warehouseRepo.GetByID(ctx, req.ToWarehouse)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CreateSchedule", "getToWarehouse", err)
+		return nil, err
+	}
+
+	if fromWarehouse.ShopID != toWarehouse.ShopID || fromWarehouse.ShopID != shopID {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CreateSchedule", "invalidShopID", "shopID")
+		return nil, domain.ErrWarehouseShopMismatch
+	}
+
+	// Both stock rows must exist, otherwise every run would fail
+	if _, err = u.stockRepo.GetByProductIDAndWarehouseID(ctx, req.ProductID, req.FromWarehouse); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CreateSchedule", "getFromWarehouseStock", err)
+		return nil, err
+	}
+	if _, err = u.stockRepo.GetByProductIDAndWarehouseID(ctx, req.ProductID, req.ToWarehouse); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CreateSchedule", "getToWarehouseStock", err)
+		return nil, err
+	}
+
+	schedule := &domain.StockTransferSchedule{
+		ShopID:        shopID,
+		ProductID:     req.ProductID,
+		FromWarehouse: req.FromWarehouse,
+		ToWarehouse:   req.ToWarehouse,
+		Quantity:      req.Quantity,
+		TopUpTo:       req.TopUpTo,
+		Recurrence:    req.Recurrence,
+		Description:   req.Description,
+		ScheduledAt:   req.ScheduledAt.UTC(),
+		Active:        true,
+	}
+	if schedule.Recurrence == domain.TransferRecurrenceMonthly {
+		schedule.DayOfMonth = schedule.ScheduledAt.Day()
+	}
+	if schedule.TopUpTo > 0 {
+		schedule.Quantity = 0
+	}
+
+	err = u.scheduleRepo.Create(ctx, schedule)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CreateSchedule", "createSchedule", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "[stockTransferScheduleUsecase] CreateSchedule", "schedule", schedule)
+	return schedule, nil
+}
+
+func (u *stockTransferScheduleUsecase) GetListSchedule(ctx context.Context, shopID int64, param domain.GetListStockTransferScheduleRequest) ([]domain.StockTransferSchedule, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "stockTransferScheduleUsecase.GetListSchedule")
+	defer span.End()
+
+	schedules, err := u.scheduleRepo.GetListSchedule(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] GetListSchedule", "getListSchedule", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	count, err := u.scheduleRepo.GetListScheduleCount(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] GetListSchedule", "getListScheduleCount", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.Metadata{
+		TotalData: count,
+		TotalPage: (count + param.Limit - 1) / param.Limit,
+		Page:      param.Page,
+		Limit:     param.Limit,
+		SortBy:    param.SortBy,
+		SortOrder: param.SortOrder,
+	}
+
+	return schedules, metadata, nil
+}
+
+func (u *stockTransferScheduleUsecase) GetRuns(ctx context.Context, id, shopID int64) ([]domain.StockTransferScheduleRun, error) {
+	ctx, span := tracer.Start(ctx, "stockTransferScheduleUsecase.GetRuns")
+	defer span.End()
+
+	schedule, err := u.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] GetRuns", "getSchedule", err)
+		return nil, err
+	}
+
+	if schedule.ShopID != shopID {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] GetRuns", "invalidShopID", "shopID")
+		return nil, domain.ErrUnauthorized
+	}
+
+	runs, err := u.scheduleRepo.GetRunsByScheduleID(ctx, id, 50)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] GetRuns", "getRuns", err)
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (u *stockTransferScheduleUsecase) CancelSchedule(ctx context.Context, id, shopID int64) error {
+	ctx, span := tracer.Start(ctx, "stockTransferScheduleUsecase.CancelSchedule")
+	defer span.End()
+
+	schedule, err := u.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CancelSchedule", "getSchedule", err)
+		return err
+	}
+
+	if schedule.ShopID != shopID {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CancelSchedule", "invalidShopID", "shopID")
+		return domain.ErrUnauthorized
+	}
+
+	if !schedule.Active {
+		slog.InfoContext(ctx, "[stockTransferScheduleUsecase] CancelSchedule", "noChange", nil)
+		return nil
+	}
+
+	err = u.scheduleRepo.UpdateActive(ctx, id, false)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] CancelSchedule", "updateActive", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *stockTransferScheduleUsecase) RunDueSchedules(ctx context.Context, now time.Time) error {
+	ctx, span := tracer.Start(ctx, "stockTransferScheduleUsecase.RunDueSchedules")
+	defer span.End()
+
+	// Each schedule is claimed, run and advanced in one transaction, so other
+	// instances skip it and a transfer is never created without the schedule
+	// moving on.
+	attempted := []int64{}
+	for range dueScheduleBatchSize {
+		var schedule domain.StockTransferSchedule
+		err := u.scheduleRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+			schedule, err = u.scheduleRepo.ClaimDue(ctx, now, attempted, tx)
+			if err != nil {
+				return err
+			}
+			return u.runSchedule(ctx, schedule, now, tx)
+		})
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			if schedule.ID == 0 {
+				slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] RunDueSchedules", "claimDue", err)
+				return err
+			}
+			// keep going, a broken schedule must not block the others
+			slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] RunDueSchedules", "runSchedule", err, "scheduleID", schedule.ID)
+		}
+		attempted = append(attempted, schedule.ID)
+	}
+
+	return nil
+}
+
+func (u *stockTransferScheduleUsecase) runSchedule(ctx context.Context, schedule domain.StockTransferSchedule, now time.Time, tx *sql.Tx) error {
+	run := domain.StockTransferScheduleRun{
+		ScheduleID: schedule.ID,
+		RunAt:      now,
+	}
+
+	quantity, message, err := u.resolveQuantity(ctx, schedule, tx)
+	switch {
+	case err != nil:
+		run.Status = domain.ScheduleRunStatusFailed
+		run.Message = err.Error()
+	case message != "":
+		run.Status = domain.ScheduleRunStatusSkipped
+		run.Message = message
+	default:
+		run.Quantity = quantity
+		transferID, err := u.startTransfer(ctx, schedule, quantity, tx)
+		switch {
+		case err != nil && transferID != 0:
+			// Roll the run back rather than leave a not_started transfer that
+			// blocks replenishment of the destination
+			return err
+		case err != nil:
+			run.Status = domain.ScheduleRunStatusFailed
+			run.Message = err.Error()
+		default:
+			run.TransferID = &transferID
+			run.Status = domain.ScheduleRunStatusExecuted
+		}
+	}
+
+	schedule.LastRunAt = &now
+	schedule.ScheduledAt, schedule.Active = nextScheduledAt(schedule.ScheduledAt, schedule.Recurrence, schedule.DayOfMonth, now)
+
+	if err := u.scheduleRepo.CreateRun(ctx, &run, tx); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] runSchedule", "createRun", err)
+		return err
+	}
+
+	if err := u.scheduleRepo.UpdateNextRun(ctx, schedule, tx); err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] runSchedule", "updateNextRun", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "[stockTransferScheduleUsecase] runSchedule", "run", run)
+	return nil
+}
+
+// resolveQuantity returns the quantity to move for this run, or a non-empty
+// skip message when the run should not create a transfer. The source stock
+// row is locked in tx; reservations and transfers take the same lock, so the
+// availability checked here still holds when the transfer starts.
+func (u *stockTransferScheduleUsecase) resolveQuantity(ctx context.Context, schedule domain.StockTransferSchedule, tx *sql.Tx) (int64, string, error) {
+	fromStock, err := u.stockRepo.GetByProductIDAndWarehouseID(ctx, schedule.ProductID, schedule.FromWarehouse)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] resolveQuantity", "getFromStock", err)
+		return 0, "", err
+	}
+
+	fromStock, err = u.stockRepo.LockForUpdate(ctx, fromStock.ID, tx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] resolveQuantity", "lockFromStock", err)
+		return 0, "", err
+	}
+
+	fromAvailable, err := u.availableAtStock(ctx, fromStock)
+	if err != nil {
+		return 0, "", err
+	}
+
+	quantity := schedule.Quantity
+	if schedule.TopUpTo > 0 {
+		toAvailable, err := u.availableAtWarehouse(ctx, schedule.ProductID, schedule.ToWarehouse)
+		if err != nil {
+			return 0, "", err
+		}
+		quantity = schedule.TopUpTo - toAvailable
+		if quantity <= 0 {
+			return 0, "destination already at or above top-up level", nil
+		}
+	}
+
+	if fromAvailable < quantity {
+		return 0, fmt.Sprintf("insufficient source availability: need %d, available %d", quantity, fromAvailable), nil
+	}
+
+	return quantity, "", nil
+}
+
+func (u *stockTransferScheduleUsecase) availableAtWarehouse(ctx context.Context, productID, warehouseID int64) (int64, error) {
+	stock, err := u.stockRepo.GetByProductIDAndWarehouseID(ctx, productID, warehouseID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] availableAtWarehouse", "getStock", err)
+		return 0, err
+	}
+
+	return u.availableAtStock(ctx, stock)
+}
+
+func (u *stockTransferScheduleUsecase) availableAtStock(ctx context.Context, stock domain.Stock) (int64, error) {
+	reserved, err := u.reservedStockRepo.GetTotalReservedStockByStockIDAndStatus(ctx, stock.ID, domain.ReservedStockStatusActive)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] availableAtStock", "getReservedStock", err)
+		return 0, err
+	}
+
+	return stock.Quantity - reserved, nil
+}
+
+// startTransfer creates the transfer and moves it to in_progress in the run's
+// transaction. A transfer ID returned with an error means it was created but
+// not started, and the transaction must be rolled back.
+func (u *stockTransferScheduleUsecase) startTransfer(ctx context.Context, schedule domain.StockTransferSchedule, quantity int64, tx *sql.Tx) (int64, error) {
+	transfer, err := u.stockTransferUsecase.CreateTransferTx(ctx, schedule.ShopID, domain.StockTransferCreateRequest{
+		ProductID:     schedule.ProductID,
+		FromWarehouse: schedule.FromWarehouse,
+		ToWarehouse:   schedule.ToWarehouse,
+		Quantity:      quantity,
+		Description:   schedule.Description,
+	}, tx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] startTransfer", "createTransfer", err)
+		return 0, err
+	}
+
+	err = u.stockTransferUsecase.UpdateTransferStatusTx(ctx, transfer, domain.StockTransferUpdateRequest{
+		Status:      domain.TransferStatusInProgress,
+		Description: schedule.Description,
+	}, tx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stockTransferScheduleUsecase] startTransfer", "updateTransferStatus", err)
+		return transfer.ID, err
+	}
+
+	return transfer.ID, nil
+}
+
+// nextScheduledAt advances a schedule past now. One-off schedules are
+// deactivated; missed occurrences of recurring schedules are not replayed.
+func nextScheduledAt(current time.Time, recurrence domain.TransferRecurrence, dayOfMonth int, now time.Time) (time.Time, bool) {
+	next := current
+	for !next.After(now) {
+		switch recurrence {
+		case domain.TransferRecurrenceWeekly:
+			next = next.AddDate(0, 0, 7)
+		case domain.TransferRecurrenceMonthly:
+			next = nextMonth(next, dayOfMonth)
+		default:
+			return current, false
+		}
+	}
+	return next, true
+}
+
+// nextMonth moves t to day of the following month, or to its last day when
+// the month is shorter, so the 31st runs on Feb 28 and again on Mar 31.
+func nextMonth(t time.Time, day int) time.Time {
+	if day == 0 {
+		day = t.Day()
+	}
+	year, month, _ := t.Date()
+	first := time.Date(year, month+1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
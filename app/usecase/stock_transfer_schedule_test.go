@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"warehouse-service/app/domain"
+)
+
+func scheduleDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+func TestNextMonth(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		day  int
+		want time.Time
+	}{
+		{"same day next month", scheduleDate(2026, time.March, 15), 0, scheduleDate(2026, time.April, 15)},
+		{"day 31 clamps to february", scheduleDate(2026, time.January, 31), 31, scheduleDate(2026, time.February, 28)},
+		{"day 31 clamps to leap february", scheduleDate(2028, time.January, 31), 31, scheduleDate(2028, time.February, 29)},
+		{"day 31 returns after a short month", scheduleDate(2026, time.February, 28), 31, scheduleDate(2026, time.March, 31)},
+		{"day 31 clamps to april", scheduleDate(2026, time.March, 31), 31, scheduleDate(2026, time.April, 30)},
+		{"year rolls over", scheduleDate(2026, time.December, 31), 31, scheduleDate(2027, time.January, 31)},
+		{"no day keeps the current day", scheduleDate(2026, time.February, 28), 0, scheduleDate(2026, time.March, 28)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextMonth(tt.from, tt.day); !got.Equal(tt.want) {
+				t.Fatalf("nextMonth(%s, %d) = %s, want %s", tt.from, tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextScheduledAt(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    time.Time
+		recurrence domain.TransferRecurrence
+		day        int
+		now        time.Time
+		want       time.Time
+		wantActive bool
+	}{
+		{
+			name:       "one-off deactivates",
+			current:    scheduleDate(2026, time.May, 4),
+			recurrence: domain.TransferRecurrenceNone,
+			now:        scheduleDate(2026, time.May, 4),
+			want:       scheduleDate(2026, time.May, 4),
+		},
+		{
+			name:       "weekly",
+			current:    scheduleDate(2026, time.May, 4),
+			recurrence: domain.TransferRecurrenceWeekly,
+			now:        scheduleDate(2026, time.May, 4),
+			want:       scheduleDate(2026, time.May, 11),
+			wantActive: true,
+		},
+		{
+			name:       "weekly skips missed occurrences",
+			current:    scheduleDate(2026, time.May, 4),
+			recurrence: domain.TransferRecurrenceWeekly,
+			now:        scheduleDate(2026, time.May, 20),
+			want:       scheduleDate(2026, time.May, 25),
+			wantActive: true,
+		},
+		{
+			name:       "monthly on day 31 clamps",
+			current:    scheduleDate(2026, time.January, 31),
+			recurrence: domain.TransferRecurrenceMonthly,
+			day:        31,
+			now:        scheduleDate(2026, time.January, 31),
+			want:       scheduleDate(2026, time.February, 28),
+			wantActive: true,
+		},
+		{
+			name:       "monthly on day 31 returns to day 31",
+			current:    scheduleDate(2026, time.February, 28),
+			recurrence: domain.TransferRecurrenceMonthly,
+			day:        31,
+			now:        scheduleDate(2026, time.February, 28),
+			want:       scheduleDate(2026, time.March, 31),
+			wantActive: true,
+		},
+		{
+			name:       "monthly skips missed occurrences",
+			current:    scheduleDate(2026, time.January, 31),
+			recurrence: domain.TransferRecurrenceMonthly,
+			day:        31,
+			now:        scheduleDate(2026, time.April, 2),
+			want:       scheduleDate(2026, time.April, 30),
+			wantActive: true,
+		},
+		{
+			name:       "monthly not yet due is unchanged",
+			current:    scheduleDate(2026, time.June, 30),
+			recurrence: domain.TransferRecurrenceMonthly,
+			day:        30,
+			now:        scheduleDate(2026, time.June, 1),
+			want:       scheduleDate(2026, time.June, 30),
+			wantActive: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, active := nextScheduledAt(tt.current, tt.recurrence, tt.day, tt.now)
+			if !got.Equal(tt.want) || active != tt.wantActive {
+				t.Fatalf("nextScheduledAt = %s, %t, want %s, %t", got, active, tt.want, tt.wantActive)
+			}
+		})
+	}
+}
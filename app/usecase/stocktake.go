@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg/metrics"
+)
+
+type stocktakeUsecase struct {
+	stocktakeRepo       domain.StocktakeRepository
+	warehouseRepo       domain.WarehouseRepository
+	stockRepo           domain.StockRepository
+	reservedStockRepo   domain.ReservedStockRepository
+	stockPublishBroker  domain.BrokerPublisher
+	replenishment       domain.ReplenishmentUsecase
+	stockAdjustmentRepo domain.StockAdjustmentRepository
+}
+
+func NewStocktakeUsecase(stocktakeRepo domain.StocktakeRepository, warehouseRepo domain.WarehouseRepository,
+	stockRepo domain.StockRepository, reservedStockRepo domain.ReservedStockRepository,
+	stockPublishBroker domain.BrokerPublisher, replenishment domain.ReplenishmentUsecase,
+	stockAdjustmentRepo domain.StockAdjustmentRepository) domain.StocktakeUsecase {
+	return &stocktakeUsecase{stocktakeRepo, warehouseRepo, stockRepo, reservedStockRepo, stockPublishBroker, replenishment, stockAdjustmentRepo}
+}
+
+func (u *stocktakeUsecase) Open(ctx context.Context, shopID, userID int64, req domain.StocktakeCreateRequest) (*domain.Stocktake, error) {
+	ctx, span := tracer.Start(ctx, "stocktakeUsecase.Open")
+	defer span.End()
+
+	warehouse, err := u.warehouseRepo.GetByID(ctx, req.WarehouseID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] Open", "getWarehouse", err)
+		return nil, err
+	}
+
+	if warehouse.ShopID != shopID {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] Open", "invalidShopID", "shopID unauthorized")
+		return nil, domain.ErrUnauthorized
+	}
+
+	if !warehouse.Active {
+		return nil, domain.ErrWarehouseInactive
+	}
+
+	stocktake := &domain.Stocktake{
+		ShopID:      shopID,
+		WarehouseID: warehouse.ID,
+		Status:      domain.StocktakeStatusOpen,
+		Note:        req.Note,
+		OpenedBy:    userID,
+	}
+	if err = u.stocktakeRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return u.stocktakeRepo.Create(ctx, stocktake, tx)
+	}); err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] Open", "createStocktake", err)
+		return nil, err
+	}
+
+	stocktake.Lines, err = u.stocktakeRepo.GetLines(ctx, stocktake.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] Open", "getLines", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "[stocktakeUsecase] Open", "stocktakeID", stocktake.ID, "lines", len(stocktake.Lines))
+	return stocktake, nil
+}
+
+func (u *stocktakeUsecase) GetByID(ctx context.Context, id, shopID int64) (domain.Stocktake, error) {
+	ctx, span := tracer.Start(ctx, "stocktakeUsecase.GetByID")
+	defer span.End()
+
+	stocktake, err := u.stocktakeRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] GetByID", "getStocktake", err)
+		return stocktake, err
+	}
+
+	if stocktake.ShopID != shopID {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] GetByID", "invalidShopID", "shopID unauthorized")
+		return domain.Stocktake{}, domain.ErrUnauthorized
+	}
+
+	stocktake.Lines, err = u.stocktakeRepo.GetLines(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] GetByID", "getLines", err)
+		return stocktake, err
+	}
+
+	return stocktake, nil
+}
+
+func (u *stocktakeUsecase) GetListStocktake(ctx context.Context, shopID int64, param domain.GetListStocktakeRequest) ([]domain.Stocktake, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "stocktakeUsecase.GetListStocktake")
+	defer span.End()
+
+	stocktakes, err := u.stocktakeRepo.GetListStocktake(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] GetListStocktake", "getListStocktake", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	count, err := u.stocktakeRepo.GetListStocktakeCount(ctx, shopID, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] GetListStocktake", "getListStocktakeCount", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.Metadata{
+		TotalData: count,
+		TotalPage: (count + param.Limit - 1) / param.Limit,
+		Page:      param.Page,
+		Limit:     param.Limit,
+		SortBy:    "id",
+		SortOrder: "desc",
+	}
+
+	return stocktakes, metadata, nil
+}
+
+func (u *stocktakeUsecase) RecordCounts(ctx context.Context, id, shopID, userID int64, req domain.StocktakeCountRequest) (domain.Stocktake, error) {
+	ctx, span := tracer.Start(ctx, "stocktakeUsecase.RecordCounts")
+	defer span.End()
+
+	err := u.changeStocktake(ctx, id, shopID, []domain.StocktakeStatus{domain.StocktakeStatusOpen}, func(ctx context.Context, stocktake *domain.Stocktake, tx *sql.Tx) error {
+		for _, count := range req.Counts {
+			ok, err := u.stocktakeRepo.UpdateCount(ctx, id, count, userID, tx)
+			if err != nil {
+				slog.ErrorContext(ctx, "[stocktakeUsecase] RecordCounts", "updateCount", err)
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("product %d: %w", count.ProductID, domain.ErrStocktakeUnknownItem)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] RecordCounts", "transactionError", err)
+		return domain.Stocktake{}, err
+	}
+
+	return u.GetByID(ctx, id, shopID)
+}
+
+func (u *stocktakeUsecase) Submit(ctx context.Context, id, shopID, userID int64) (domain.Stocktake, error) {
+	ctx, span := tracer.Start(ctx, "stocktakeUsecase.Submit")
+	defer span.End()
+
+	err := u.changeStocktake(ctx, id, shopID, []domain.StocktakeStatus{domain.StocktakeStatusOpen}, func(ctx context.Context, stocktake *domain.Stocktake, tx *sql.Tx) error {
+		lines, err := u.stocktakeRepo.GetLines(ctx, id)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stocktakeUsecase] Submit", "getLines", err)
+			return err
+		}
+		counted := 0
+		for _, line := range lines {
+			if line.CountedQuantity != nil {
+				counted++
+			}
+		}
+		if counted == 0 {
+			return domain.ErrStocktakeNotCounted
+		}
+
+		now := time.Now().UTC()
+		stocktake.Status = domain.StocktakeStatusSubmitted
+		stocktake.SubmittedBy = &userID
+		stocktake.SubmittedAt = &now
+		return u.stocktakeRepo.UpdateStatus(ctx, *stocktake, tx)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] Submit", "transactionError", err)
+		return domain.Stocktake{}, err
+	}
+
+	return u.GetByID(ctx, id, shopID)
+}
+
+func (u *stocktakeUsecase) Approve(ctx context.Context, id, shopID, userID int64) (domain.Stocktake, error) {
+	ctx, span := tracer.Start(ctx, "stocktakeUsecase.Approve")
+	defer span.End()
+
+	var adjusted []domain.StocktakeLine
+	err := u.changeStocktake(ctx, id, shopID, []domain.StocktakeStatus{domain.StocktakeStatusSubmitted}, func(ctx context.Context, stocktake *domain.Stocktake, tx *sql.Tx) error {
+		lines, err := u.stocktakeRepo.GetLines(ctx, id)
+		if err != nil {
+			slog.ErrorContext(ctx, "[stocktakeUsecase] Approve", "getLines", err)
+			return err
+		}
+		// Lock stock rows in id order so concurrent approvals can't deadlock
+		sort.Slice(lines, func(i, j int) bool { return lines[i].StockID < lines[j].StockID })
+
+		for _, line := range lines {
+			// Uncounted products are left as they are
+			if line.Variance == nil || *line.Variance == 0 {
+				continue
+			}
+
+			// The variance is applied to the current quantity, so stock
+			// moved while counting isn't overwritten by the count.
+			stock, err := u.stockRepo.LockForUpdate(ctx, line.StockID, tx)
+			if err != nil {
+				slog.ErrorContext(ctx, "[stocktakeUsecase] Approve", "lockForUpdate", err)
+				return err
+			}
+			quantity := stock.Quantity + *line.Variance
+
+			reservedStock, err := u.reservedStockRepo.GetTotalReservedStockByStockIDAndStatus(ctx, stock.ID, domain.ReservedStockStatusActive)
+			if err != nil {
+				slog.ErrorContext(ctx, "[stocktakeUsecase] Approve", "getReservedStock", err)
+				return err
+			}
+			if quantity < reservedStock {
+				return fmt.Errorf("product %d: %w", line.ProductID, domain.ErrQuantityBelowReserved)
+			}
+
+			if err = u.stockRepo.UpdateQuantity(ctx, stock.ID, quantity, tx); err != nil {
+				slog.ErrorContext(ctx, "[stocktakeUsecase] Approve", "updateStock", err)
+				return err
+			}
+			if err = u.stocktakeRepo.UpdateAppliedQuantity(ctx, line.ID, quantity, tx); err != nil {
+				slog.ErrorContext(ctx, "[stocktakeUsecase] Approve", "updateAppliedQuantity", err)
+				return err
+			}
+			err = u.stockAdjustmentRepo.Create(ctx, &domain.StockAdjustment{
+				ShopID:         shopID,
+				StockID:        stock.ID,
+				ProductID:      stock.ProductID,
+				WarehouseID:    stock.WarehouseID,
+				Delta:          *line.Variance,
+				QuantityBefore: stock.Quantity,
+				QuantityAfter:  quantity,
+				Reason:         domain.AdjustmentReasonCorrection,
+				Note:           fmt.Sprintf("stocktake %d", id),
+				UserID:         &userID,
+			}, tx)
+			if err != nil {
+				slog.ErrorContext(ctx, "[stocktakeUsecase] Approve", "createAdjustment", err)
+				return err
+			}
+			adjusted = append(adjusted, line)
+		}
+
+		now := time.Now().UTC()
+		stocktake.Status = domain.StocktakeStatusApproved
+		stocktake.ClosedBy = &userID
+		stocktake.ClosedAt = &now
+		return u.stocktakeRepo.UpdateStatus(ctx, *stocktake, tx)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] Approve", "transactionError", err)
+		return domain.Stocktake{}, err
+	}
+
+	// The adjustments are committed, a failed publish is only logged
+	stocktake, err := u.GetByID(ctx, id, shopID)
+	if err != nil {
+		return stocktake, err
+	}
+	for _, line := range adjusted {
+		metrics.StockAdjustmentsTotal.WithLabelValues(strconv.FormatInt(shopID, 10), string(domain.AdjustmentReasonCorrection)).Inc()
+
+		availableStock, err := u.stockRepo.GetAvailableStockByProductID(ctx, line.ProductID)
+		if err != nil {
+			slog.WarnContext(ctx, "[stocktakeUsecase] Approve", "getAvailableStock", err)
+			continue
+		}
+		err = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
+			ProductID:   line.ProductID,
+			Available:   availableStock,
+			ShopID:      shopID,
+			WarehouseID: stocktake.WarehouseID,
+			Cause:       domain.StockEventCauseStocktake,
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "[stocktakeUsecase] Approve", "publishStockAvailable", err)
+		}
+
+		if _, err = u.replenishment.Replenish(ctx, line.StockID); err != nil {
+			slog.WarnContext(ctx, "[stocktakeUsecase] Approve", "replenish", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "[stocktakeUsecase] Approve", "stocktakeID", id, "adjusted", len(adjusted))
+	return stocktake, nil
+}
+
+func (u *stocktakeUsecase) Cancel(ctx context.Context, id, shopID, userID int64) (domain.Stocktake, error) {
+	ctx, span := tracer.Start(ctx, "stocktakeUsecase.Cancel")
+	defer span.End()
+
+	allowed := []domain.StocktakeStatus{domain.StocktakeStatusOpen, domain.StocktakeStatusSubmitted}
+	err := u.changeStocktake(ctx, id, shopID, allowed, func(ctx context.Context, stocktake *domain.Stocktake, tx *sql.Tx) error {
+		now := time.Now().UTC()
+		stocktake.Status = domain.StocktakeStatusCancelled
+		stocktake.ClosedBy = &userID
+		stocktake.ClosedAt = &now
+		return u.stocktakeRepo.UpdateStatus(ctx, *stocktake, tx)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[stocktakeUsecase] Cancel", "transactionError", err)
+		return domain.Stocktake{}, err
+	}
+
+	return u.GetByID(ctx, id, shopID)
+}
+
+// changeStocktake runs fn in a transaction holding the stocktake row lock,
+// after checking the shop and that the stocktake is in one of allowed.
+func (u *stocktakeUsecase) changeStocktake(ctx context.Context, id, shopID int64, allowed []domain.StocktakeStatus, fn func(context.Context, *domain.Stocktake, *sql.Tx) error) error {
+	return u.stocktakeRepo.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		stocktake, err := u.stocktakeRepo.LockForUpdate(ctx, id, tx)
+		if err != nil {
+			return err
+		}
+
+		if stocktake.ShopID != shopID {
+			return domain.ErrUnauthorized
+		}
+
+		for _, status := range allowed {
+			if stocktake.Status == status {
+				return fn(ctx, &stocktake, tx)
+			}
+		}
+		return domain.ErrStocktakeInvalidState
+	})
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"warehouse-service/app/domain"
+	"warehouse-service/pkg"
+	"warehouse-service/pkg/ctxutil"
+)
+
+// memoryStocktakeRepo keeps one stocktake in memory. Its transactions also
+// cover the stock rows and the ledger, so a failed approval restores them.
+type memoryStocktakeRepo struct {
+	domain.StocktakeRepository
+
+	stocktake   domain.Stocktake
+	lines       []domain.StocktakeLine
+	stocks      *memoryStockRepo
+	adjustments *memoryAdjustments
+}
+
+func (r *memoryStocktakeRepo) GetByID(ctx context.Context, id int64) (domain.Stocktake, error) {
+	if id != r.stocktake.ID {
+		return domain.Stocktake{}, domain.ErrNotFound
+	}
+	return r.stocktake, nil
+}
+
+func (r *memoryStocktakeRepo) LockForUpdate(ctx context.Context, id int64, tx *sql.Tx) (domain.Stocktake, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryStocktakeRepo) GetLines(ctx context.Context, stocktakeID int64) ([]domain.StocktakeLine, error) {
+	return slices.Clone(r.lines), nil
+}
+
+func (r *memoryStocktakeRepo) UpdateStatus(ctx context.Context, stocktake domain.Stocktake, tx *sql.Tx) error {
+	r.stocktake = stocktake
+	return nil
+}
+
+func (r *memoryStocktakeRepo) UpdateAppliedQuantity(ctx context.Context, lineID, quantity int64, tx *sql.Tx) error {
+	for i := range r.lines {
+		if r.lines[i].ID == lineID {
+			r.lines[i].AppliedQuantity = &quantity
+		}
+	}
+	return nil
+}
+
+func (r *memoryStocktakeRepo) WithTransaction(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	stocktake, lines, stocks := r.stocktake, slices.Clone(r.lines), maps.Clone(r.stocks.stocks)
+	created := len(r.adjustments.created)
+	ctx, commit := ctxutil.WithAfterCommit(ctx)
+	if err := fn(ctx, nil); err != nil {
+		r.stocktake, r.lines, r.stocks.stocks = stocktake, lines, stocks
+		r.adjustments.created = r.adjustments.created[:created]
+		return err
+	}
+	commit(ctx)
+	return nil
+}
+
+func countedLine(id, stockID, system int64, counted *int64) domain.StocktakeLine {
+	line := domain.StocktakeLine{ID: id, StocktakeID: 1, StockID: stockID, ProductID: stockID * 10, SystemQuantity: system, CountedQuantity: counted}
+	if counted != nil {
+		line.Variance = pkg.ToPointer(*counted - system)
+	}
+	return line
+}
+
+func TestStocktakeApprove(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   domain.StocktakeStatus
+		lines    []domain.StocktakeLine
+		current  map[int64]int64 // stock quantity by stock ID at approval
+		reserved map[int64]int64
+		wantErr  error
+		// stock quantity by stock ID after approval
+		want map[int64]int64
+		// ledger deltas by stock ID
+		wantDeltas map[int64]int64
+	}{
+		{
+			name:       "variance is applied to the current quantity",
+			status:     domain.StocktakeStatusSubmitted,
+			lines:      []domain.StocktakeLine{countedLine(1, 1, 10, pkg.ToPointer[int64](8))},
+			current:    map[int64]int64{1: 15},
+			want:       map[int64]int64{1: 13},
+			wantDeltas: map[int64]int64{1: -2},
+		},
+		{
+			name:   "uncounted and unchanged lines are left alone",
+			status: domain.StocktakeStatusSubmitted,
+			lines: []domain.StocktakeLine{
+				countedLine(1, 1, 10, nil),
+				countedLine(2, 2, 10, pkg.ToPointer[int64](10)),
+				countedLine(3, 3, 10, pkg.ToPointer[int64](12)),
+			},
+			current:    map[int64]int64{1: 7, 2: 9, 3: 10},
+			want:       map[int64]int64{1: 7, 2: 9, 3: 12},
+			wantDeltas: map[int64]int64{3: 2},
+		},
+		{
+			name:       "down to the reserved quantity",
+			status:     domain.StocktakeStatusSubmitted,
+			lines:      []domain.StocktakeLine{countedLine(1, 1, 10, pkg.ToPointer[int64](7))},
+			current:    map[int64]int64{1: 7},
+			reserved:   map[int64]int64{1: 4},
+			want:       map[int64]int64{1: 4},
+			wantDeltas: map[int64]int64{1: -3},
+		},
+		{
+			name:   "below the reserved quantity rolls back every line",
+			status: domain.StocktakeStatusSubmitted,
+			lines: []domain.StocktakeLine{
+				countedLine(1, 1, 10, pkg.ToPointer[int64](12)),
+				countedLine(2, 2, 10, pkg.ToPointer[int64](8)),
+			},
+			current:  map[int64]int64{1: 10, 2: 5},
+			reserved: map[int64]int64{2: 4},
+			wantErr:  domain.ErrQuantityBelowReserved,
+			want:     map[int64]int64{1: 10, 2: 5},
+		},
+		{
+			name:    "open stocktake",
+			status:  domain.StocktakeStatusOpen,
+			lines:   []domain.StocktakeLine{countedLine(1, 1, 10, pkg.ToPointer[int64](8))},
+			current: map[int64]int64{1: 10},
+			wantErr: domain.ErrStocktakeInvalidState,
+			want:    map[int64]int64{1: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stocks := &memoryStockRepo{stocks: map[int64]domain.Stock{}, reserved: tt.reserved}
+			for id, quantity := range tt.current {
+				stocks.stocks[id] = domain.Stock{ID: id, ProductID: id * 10, WarehouseID: 30, Quantity: quantity}
+			}
+			adjustments := &memoryAdjustments{}
+			stocktakes := &memoryStocktakeRepo{
+				stocktake:   domain.Stocktake{ID: 1, ShopID: 7, WarehouseID: 30, Status: tt.status},
+				lines:       tt.lines,
+				stocks:      stocks,
+				adjustments: adjustments,
+			}
+			u := &stocktakeUsecase{
+				stocktakeRepo:       stocktakes,
+				stockRepo:           stocks,
+				reservedStockRepo:   memoryReservations{stocks: stocks},
+				stockPublishBroker:  &stockMessageRecorder{},
+				replenishment:       noReplenishment{},
+				stockAdjustmentRepo: adjustments,
+			}
+
+			stocktake, err := u.Approve(context.Background(), 1, 7, 8)
+			for id, want := range tt.want {
+				if got := stocks.stocks[id].Quantity; got != want {
+					t.Errorf("stock %d quantity = %d, want %d", id, got, want)
+				}
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if stocktakes.stocktake.Status != tt.status || len(adjustments.created) != 0 {
+					t.Fatalf("failed approval left status %s and %d ledger rows", stocktakes.stocktake.Status, len(adjustments.created))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Approve: %v", err)
+			}
+
+			if stocktake.Status != domain.StocktakeStatusApproved || stocktake.ClosedBy == nil || *stocktake.ClosedBy != 8 {
+				t.Fatalf("stocktake = %+v, want approved by 8", stocktake)
+			}
+
+			deltas := map[int64]int64{}
+			for _, adjustment := range adjustments.created {
+				deltas[adjustment.StockID] = adjustment.Delta
+				if adjustment.QuantityAfter-adjustment.QuantityBefore != adjustment.Delta || adjustment.QuantityAfter != tt.want[adjustment.StockID] {
+					t.Errorf("ledger = %+v, want after %d", adjustment, tt.want[adjustment.StockID])
+				}
+			}
+			if !maps.Equal(deltas, tt.wantDeltas) {
+				t.Fatalf("ledger deltas = %v, want %v", deltas, tt.wantDeltas)
+			}
+
+			for _, line := range stocktakes.lines {
+				_, adjusted := tt.wantDeltas[line.StockID]
+				if adjusted != (line.AppliedQuantity != nil) {
+					t.Errorf("line %d applied quantity = %v, adjusted %t", line.ID, line.AppliedQuantity, adjusted)
+				}
+				if adjusted && *line.AppliedQuantity != tt.want[line.StockID] {
+					t.Errorf("line %d applied quantity = %d, want %d", line.ID, *line.AppliedQuantity, tt.want[line.StockID])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package usecase
+
+import "go.opentelemetry.io/otel"
+
+// Every exported usecase method opens a span named <usecase>.<Method>.
+var tracer = otel.Tracer("warehouse-service/app/usecase")
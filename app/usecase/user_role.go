@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"warehouse-service/app/domain"
+	"warehouse-service/config"
+)
+
+type userRoleUsecase struct {
+	userRoleRepo domain.UserRoleRepository
+	cfg          *config.Config
+}
+
+func NewUserRoleUsecase(userRoleRepo domain.UserRoleRepository, cfg *config.Config) domain.UserRoleUsecase {
+	return &userRoleUsecase{userRoleRepo, cfg}
+}
+
+func (u *userRoleUsecase) ResolveRole(ctx context.Context, shopID, userID int64) (domain.Role, error) {
+	ctx, span := tracer.Start(ctx, "userRoleUsecase.ResolveRole")
+	defer span.End()
+
+	userRole, err := u.userRoleRepo.GetByShopIDAndUserID(ctx, shopID, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		// Shops that never assigned a role keep the full access they had
+		// before roles existed, until an owner assigns the first one.
+		hasRoles, err := u.userRoleRepo.ExistsByShopID(ctx, shopID)
+		if err != nil {
+			slog.ErrorContext(ctx, "[userRoleUsecase] ResolveRole", "existsByShopID", err)
+			return "", err
+		}
+		if !hasRoles {
+			return domain.RoleOwner, nil
+		}
+		return domain.Role(u.cfg.Rbac.DefaultRole), nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleUsecase] ResolveRole", "getByShopIDAndUserID", err)
+		return "", err
+	}
+	return userRole.Role, nil
+}
+
+func (u *userRoleUsecase) Upsert(ctx context.Context, shopID, callerID int64, req domain.UserRoleUpsertRequest) (*domain.UserRole, error) {
+	ctx, span := tracer.Start(ctx, "userRoleUsecase.Upsert")
+	defer span.End()
+
+	// Owners cannot demote themselves, so a shop never locks itself out.
+	if req.UserID == callerID {
+		slog.ErrorContext(ctx, "[userRoleUsecase] Upsert", "selfChange", callerID)
+		return nil, domain.ErrRoleSelfChange
+	}
+
+	// The caller is an owner through the no-roles fallback, keep them one
+	// once the shop has roles.
+	hasRoles, err := u.userRoleRepo.ExistsByShopID(ctx, shopID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleUsecase] Upsert", "existsByShopID", err)
+		return nil, err
+	}
+	if !hasRoles {
+		if err = u.userRoleRepo.Upsert(ctx, &domain.UserRole{ShopID: shopID, UserID: callerID, Role: domain.RoleOwner}); err != nil {
+			slog.ErrorContext(ctx, "[userRoleUsecase] Upsert", "upsertCaller", err)
+			return nil, err
+		}
+	}
+
+	userRole := &domain.UserRole{
+		ShopID: shopID,
+		UserID: req.UserID,
+		Role:   req.Role,
+	}
+	if err := u.userRoleRepo.Upsert(ctx, userRole); err != nil {
+		slog.ErrorContext(ctx, "[userRoleUsecase] Upsert", "upsert", err)
+		return nil, err
+	}
+	return userRole, nil
+}
+
+func (u *userRoleUsecase) GetByShopID(ctx context.Context, shopID int64) ([]domain.UserRole, error) {
+	ctx, span := tracer.Start(ctx, "userRoleUsecase.GetByShopID")
+	defer span.End()
+
+	userRoles, err := u.userRoleRepo.GetByShopID(ctx, shopID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[userRoleUsecase] GetByShopID", "getByShopID", err)
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+func (u *userRoleUsecase) Delete(ctx context.Context, shopID, callerID, userID int64) error {
+	ctx, span := tracer.Start(ctx, "userRoleUsecase.Delete")
+	defer span.End()
+
+	if userID == callerID {
+		slog.ErrorContext(ctx, "[userRoleUsecase] Delete", "selfChange", callerID)
+		return domain.ErrRoleSelfChange
+	}
+
+	if err := u.userRoleRepo.Delete(ctx, shopID, userID); err != nil {
+		slog.ErrorContext(ctx, "[userRoleUsecase] Delete", "delete", err)
+		return err
+	}
+	return nil
+}
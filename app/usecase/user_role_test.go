@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"warehouse-service/app/domain"
+	"warehouse-service/config"
+)
+
+type memoryUserRoleRepo struct {
+	domain.UserRoleRepository
+	roles map[[2]int64]domain.Role
+}
+
+func (r *memoryUserRoleRepo) Upsert(ctx context.Context, userRole *domain.UserRole) error {
+	r.roles[[2]int64{userRole.ShopID, userRole.UserID}] = userRole.Role
+	return nil
+}
+
+func (r *memoryUserRoleRepo) GetByShopIDAndUserID(ctx context.Context, shopID, userID int64) (domain.UserRole, error) {
+	role, ok := r.roles[[2]int64{shopID, userID}]
+	if !ok {
+		return domain.UserRole{}, domain.ErrNotFound
+	}
+	return domain.UserRole{ShopID: shopID, UserID: userID, Role: role}, nil
+}
+
+func (r *memoryUserRoleRepo) ExistsByShopID(ctx context.Context, shopID int64) (bool, error) {
+	for key := range r.roles {
+		if key[0] == shopID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestUserRoleBootstrap(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryUserRoleRepo{roles: map[[2]int64]domain.Role{}}
+	u := NewUserRoleUsecase(repo, &config.Config{Rbac: config.RbacConfig{DefaultRole: string(domain.RoleViewer)}})
+
+	resolve := func(shopID, userID int64) domain.Role {
+		t.Helper()
+		role, err := u.ResolveRole(ctx, shopID, userID)
+		if err != nil {
+			t.Fatalf("ResolveRole: %v", err)
+		}
+		return role
+	}
+
+	// shops without stored roles keep full access
+	if role := resolve(1, 10); role != domain.RoleOwner {
+		t.Fatalf("role before any assignment = %q, want owner", role)
+	}
+
+	if _, err := u.Upsert(ctx, 1, 10, domain.UserRoleUpsertRequest{UserID: 11, Role: domain.RolePicker}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	if role := resolve(1, 10); role != domain.RoleOwner {
+		t.Fatalf("assigning user = %q, want owner", role)
+	}
+	if role := resolve(1, 11); role != domain.RolePicker {
+		t.Fatalf("assigned user = %q, want picker", role)
+	}
+	if role := resolve(1, 12); role != domain.RoleViewer {
+		t.Fatalf("other user = %q, want the default viewer", role)
+	}
+	if role := resolve(2, 12); role != domain.RoleOwner {
+		t.Fatalf("user of a shop without roles = %q, want owner", role)
+	}
+}
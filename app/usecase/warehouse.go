@@ -3,7 +3,6 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"log/slog"
 	"warehouse-service/app/domain"
 	"warehouse-service/config"
@@ -22,6 +21,9 @@ func NewWarehouseUsecase(warehouseRepo domain.WarehouseRepository, stockRepo dom
 }
 
 func (u *warehouseUsecase) Create(ctx context.Context, shopID int64, req *domain.WarehouseCreateRequest) (*domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "warehouseUsecase.Create")
+	defer span.End()
+
 	warehouse := &domain.Warehouse{
 		ShopID:   shopID,
 		Name:     req.Name,
@@ -39,6 +41,9 @@ func (u *warehouseUsecase) Create(ctx context.Context, shopID int64, req *domain
 }
 
 func (u *warehouseUsecase) GetByShopID(ctx context.Context, shopID int64) ([]domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "warehouseUsecase.GetByShopID")
+	defer span.End()
+
 	warehouses, err := u.warehouseRepo.GetByShopID(ctx, shopID)
 	if err != nil {
 		slog.ErrorContext(ctx, "[warehouseUsecase] GetByShopID", "getWarehouses", err)
@@ -53,6 +58,9 @@ func (u *warehouseUsecase) GetByShopID(ctx context.Context, shopID int64) ([]dom
 }
 
 func (u *warehouseUsecase) GetListWarehouse(ctx context.Context, shopID int64, param domain.GetListWarehouseRequest) ([]domain.Warehouse, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "warehouseUsecase.GetListWarehouse")
+	defer span.End()
+
 	var metadata domain.Metadata
 	warehouses, err := u.warehouseRepo.GetListWarehouse(ctx, shopID, param)
 	if err != nil {
@@ -81,6 +89,9 @@ func (u *warehouseUsecase) GetListWarehouse(ctx context.Context, shopID int64, p
 }
 
 func (u *warehouseUsecase) UpdateStatus(ctx context.Context, id, shopID int64, req domain.WarehouseUpdateStatusRequest) error {
+	ctx, span := tracer.Start(ctx, "warehouseUsecase.UpdateStatus")
+	defer span.End()
+
 	warehouse, err := u.warehouseRepo.GetByID(ctx, id)
 	if err != nil {
 		slog.ErrorContext(ctx, "[warehouseUsecase] UpdateStatus", "getWarehouse", err)
@@ -137,7 +148,7 @@ func (u *warehouseUsecase) UpdateStatus(ctx context.Context, id, shopID int64, r
 
 				if reservedStocks[stock.ID] != 0 {
 					slog.ErrorContext(ctx, "[warehouseUsecase] UpdateStatus", "stockReserved", "still have reserved stock")
-					return fmt.Errorf("%w: stock still have reserved stock", domain.ErrInvalidRequest)
+					return domain.ErrWarehouseHasReserved
 				}
 			}
 		}
@@ -155,8 +166,12 @@ func (u *warehouseUsecase) UpdateStatus(ctx context.Context, id, shopID int64, r
 			}
 
 			_ = u.stockPublishBroker.PublishStockAvailable(ctx, domain.StockMessage{
-				ProductID: stock.ProductID,
-				Available: availableStock,
+				ProductID:   stock.ProductID,
+				Available:   availableStock,
+				ShopID:      shopID,
+				WarehouseID: id,
+				Cause:       domain.StockEventCauseWarehouseStatus,
+				Tx:          tx,
 			})
 		}
 
@@ -0,0 +1,378 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/config"
+	"warehouse-service/pkg"
+	"warehouse-service/pkg/ctxutil"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	webhookDeliveryBatchSize = 50
+	webhookMaxBackoff        = 6 * time.Hour
+)
+
+type webhookUsecase struct {
+	webhookRepo domain.WebhookRepository
+	sender      domain.WebhookSender
+	cfg         *config.Config
+}
+
+func NewWebhookUsecase(webhookRepo domain.WebhookRepository, sender domain.WebhookSender, cfg *config.Config) domain.WebhookUsecase {
+	return &webhookUsecase{webhookRepo, sender, cfg}
+}
+
+func (u *webhookUsecase) Create(ctx context.Context, shopID int64, req domain.WebhookCreateRequest) (*domain.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhookUsecase.Create")
+	defer span.End()
+
+	if !allowedWebhookURL(req.URL) {
+		return nil, domain.ErrWebhookURLNotAllowed
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			slog.ErrorContext(ctx, "[webhookUsecase] Create", "generateSecret", err)
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook := &domain.Webhook{
+		ShopID:     shopID,
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+		Active:     true,
+	}
+
+	if err := u.webhookRepo.Create(ctx, webhook); err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Create", "createWebhook", err)
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// allowedWebhookURL rejects non-https URLs and hosts that are internal
+// addresses. Names are resolved again by the sender when it dials, so this
+// only catches the obvious cases early.
+func allowedWebhookURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return pkg.IsPublicAddr(addr)
+	}
+	return true
+}
+
+func (u *webhookUsecase) GetByShopID(ctx context.Context, shopID int64) ([]domain.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhookUsecase.GetByShopID")
+	defer span.End()
+
+	webhooks, err := u.webhookRepo.GetByShopID(ctx, shopID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] GetByShopID", "getWebhooks", err)
+		return nil, err
+	}
+
+	if len(webhooks) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	// the secret is only handed out once, on create
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+func (u *webhookUsecase) Delete(ctx context.Context, id, shopID int64) error {
+	ctx, span := tracer.Start(ctx, "webhookUsecase.Delete")
+	defer span.End()
+
+	if _, err := u.getOwnedWebhook(ctx, id, shopID); err != nil {
+		return err
+	}
+
+	if err := u.webhookRepo.Delete(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Delete", "deleteWebhook", err)
+		return err
+	}
+	return nil
+}
+
+func (u *webhookUsecase) GetListDelivery(ctx context.Context, id, shopID int64, param domain.GetListWebhookDeliveryRequest) ([]domain.WebhookDelivery, domain.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "webhookUsecase.GetListDelivery")
+	defer span.End()
+
+	if _, err := u.getOwnedWebhook(ctx, id, shopID); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	deliveries, err := u.webhookRepo.GetListDelivery(ctx, id, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] GetListDelivery", "getListDelivery", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	count, err := u.webhookRepo.GetListDeliveryCount(ctx, id, param)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] GetListDelivery", "getListDeliveryCount", err)
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.Metadata{
+		TotalData: count,
+		TotalPage: (count + param.Limit - 1) / param.Limit,
+		Page:      param.Page,
+		Limit:     param.Limit,
+	}
+
+	return deliveries, metadata, nil
+}
+
+func (u *webhookUsecase) Redeliver(ctx context.Context, id, deliveryID, shopID int64) error {
+	ctx, span := tracer.Start(ctx, "webhookUsecase.Redeliver")
+	defer span.End()
+
+	if _, err := u.getOwnedWebhook(ctx, id, shopID); err != nil {
+		return err
+	}
+
+	delivery, err := u.webhookRepo.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Redeliver", "getDelivery", err)
+		return err
+	}
+
+	if delivery.WebhookID != id {
+		slog.ErrorContext(ctx, "[webhookUsecase] Redeliver", "invalidWebhookID", "delivery does not belong to webhook")
+		return domain.ErrNotFound
+	}
+
+	if err = u.webhookRepo.ResetDelivery(ctx, deliveryID); err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Redeliver", "resetDelivery", err)
+		return err
+	}
+	return nil
+}
+
+func (u *webhookUsecase) getOwnedWebhook(ctx context.Context, id, shopID int64) (domain.Webhook, error) {
+	webhook, err := u.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] getOwnedWebhook", "getWebhook", err)
+		return webhook, err
+	}
+
+	if webhook.ShopID != shopID {
+		slog.ErrorContext(ctx, "[webhookUsecase] getOwnedWebhook", "invalidShopID", "shopID")
+		return webhook, domain.ErrUnauthorized
+	}
+	return webhook, nil
+}
+
+func (u *webhookUsecase) Enqueue(ctx context.Context, shopID int64, eventType string, data any) error {
+	ctx, span := tracer.Start(ctx, "webhookUsecase.Enqueue")
+	defer span.End()
+
+	webhooks, err := u.webhookRepo.GetActiveByShopIDAndEventType(ctx, shopID, eventType)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Enqueue", "getWebhooks", err)
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	eventID, err := uuid.NewV4()
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Enqueue", "uuid.NewV4", err)
+		return err
+	}
+
+	now := time.Now().UTC()
+	payload, err := json.Marshal(domain.WebhookPayload{
+		ID:         eventID.String(),
+		Type:       eventType,
+		ShopID:     shopID,
+		OccurredAt: now,
+		Data:       data,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Enqueue", "json.Marshal", err)
+		return err
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       eventID.String(),
+			EventType:     eventType,
+			Payload:       payload,
+			NextAttemptAt: now,
+		})
+	}
+
+	if err = u.webhookRepo.CreateDeliveries(ctx, deliveries); err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] Enqueue", "createDeliveries", err)
+		return err
+	}
+	return nil
+}
+
+func (u *webhookUsecase) DeliverDue(ctx context.Context, now time.Time) error {
+	ctx, span := tracer.Start(ctx, "webhookUsecase.DeliverDue")
+	defer span.End()
+
+	// The lease covers the HTTP timeout so a crashed dispatcher's claims
+	// become due again instead of being lost.
+	leaseUntil := now.Add(2 * time.Duration(u.cfg.Webhook.TimeoutSeconds) * time.Second)
+	deliveries, err := u.webhookRepo.ClaimDueDeliveries(ctx, now, leaseUntil, webhookDeliveryBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] DeliverDue", "claimDueDeliveries", err)
+		return err
+	}
+
+	webhooks := make(map[int64]domain.Webhook)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = u.webhookRepo.GetByID(ctx, delivery.WebhookID)
+			if err != nil {
+				slog.ErrorContext(ctx, "[webhookUsecase] DeliverDue", "getWebhook", err, "deliveryID", delivery.ID)
+				continue
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		u.attempt(ctx, webhook, delivery)
+	}
+
+	return nil
+}
+
+func (u *webhookUsecase) attempt(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) {
+	now := time.Now().UTC()
+	delivery.Attempts++
+
+	statusCode, err := u.sender.Send(ctx, webhook, delivery)
+	delivery.LastStatusCode = int64(statusCode)
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.WebhookDeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case !webhook.Active || delivery.Attempts >= u.cfg.Webhook.MaxAttempts:
+		delivery.Status = domain.WebhookDeliveryStatusFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = domain.WebhookDeliveryStatusPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(webhookBackoff(u.cfg.Webhook.BackoffBaseSeconds, delivery.Attempts))
+	}
+
+	if err := u.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "[webhookUsecase] attempt", "updateDelivery", err, "deliveryID", delivery.ID)
+		return
+	}
+
+	slog.InfoContext(ctx, "[webhookUsecase] attempt", "deliveryID", delivery.ID, "status", delivery.Status,
+		"attempts", delivery.Attempts, "statusCode", statusCode)
+}
+
+// webhookBackoff doubles the delay on every attempt, capped at webhookMaxBackoff.
+func webhookBackoff(baseSeconds, attempts int64) time.Duration {
+	backoff := time.Duration(baseSeconds) * time.Second
+	for i := int64(1); i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+type stockAvailabilityEventData struct {
+	ProductID   int64                  `json:"product_id"`
+	WarehouseID int64                  `json:"warehouse_id,omitempty"`
+	Available   int64                  `json:"available"`
+	Cause       domain.StockEventCause `json:"cause"`
+}
+
+// webhookPublisher queues webhook deliveries for every event that is
+// successfully published to the broker, once the caller's transaction has
+// committed so a rolled back change is never delivered.
+type webhookPublisher struct {
+	domain.BrokerPublisher
+	webhooks domain.WebhookUsecase
+}
+
+func NewWebhookPublisher(publisher domain.BrokerPublisher, webhooks domain.WebhookUsecase) domain.BrokerPublisher {
+	return &webhookPublisher{publisher, webhooks}
+}
+
+func (p *webhookPublisher) PublishStockAvailable(ctx context.Context, data domain.StockMessage) error {
+	if err := p.BrokerPublisher.PublishStockAvailable(ctx, data); err != nil {
+		return err
+	}
+
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		if err := p.webhooks.Enqueue(ctx, data.ShopID, domain.WebhookEventStockAvailabilityChanged, stockAvailabilityEventData{
+			ProductID:   data.ProductID,
+			WarehouseID: data.WarehouseID,
+			Available:   data.Available,
+			Cause:       data.Cause,
+		}); err != nil {
+			slog.WarnContext(ctx, "[webhookPublisher] PublishStockAvailable", "enqueue", err)
+		}
+	})
+	return nil
+}
+
+func (p *webhookPublisher) PublishStockAlert(ctx context.Context, data domain.StockAlertMessage) error {
+	if err := p.BrokerPublisher.PublishStockAlert(ctx, data); err != nil {
+		return err
+	}
+
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		if err := p.webhooks.Enqueue(ctx, data.ShopID, "stock."+string(data.Type), data); err != nil {
+			slog.WarnContext(ctx, "[webhookPublisher] PublishStockAlert", "enqueue", err)
+		}
+	})
+	return nil
+}
+
+func (p *webhookPublisher) PublishStockTransfer(ctx context.Context, data domain.StockTransferMessage) error {
+	if err := p.BrokerPublisher.PublishStockTransfer(ctx, data); err != nil {
+		return err
+	}
+
+	ctxutil.AfterCommit(ctx, func(ctx context.Context) {
+		if err := p.webhooks.Enqueue(ctx, data.ShopID, domain.WebhookEventStockTransferUpdated, data); err != nil {
+			slog.WarnContext(ctx, "[webhookPublisher] PublishStockTransfer", "enqueue", err)
+		}
+	})
+	return nil
+}
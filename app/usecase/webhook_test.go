@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/config"
+)
+
+// memoryWebhookRepo keeps one webhook and its deliveries in memory; only
+// the methods used by DeliverDue are implemented.
+type memoryWebhookRepo struct {
+	domain.WebhookRepository
+
+	mu         sync.Mutex
+	webhook    domain.Webhook
+	deliveries map[int64]domain.WebhookDelivery
+}
+
+func (r *memoryWebhookRepo) GetByID(ctx context.Context, id int64) (domain.Webhook, error) {
+	if id != r.webhook.ID {
+		return domain.Webhook{}, domain.ErrNotFound
+	}
+	return r.webhook, nil
+}
+
+func (r *memoryWebhookRepo) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int64) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []domain.WebhookDelivery
+	for id, delivery := range r.deliveries {
+		if delivery.Status != domain.WebhookDeliveryStatusPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = leaseUntil
+		r.deliveries[id] = delivery
+		due = append(due, delivery)
+	}
+	return due, nil
+}
+
+func (r *memoryWebhookRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (r *memoryWebhookRepo) delivery(id int64) domain.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deliveries[id]
+}
+
+// statusSender answers every delivery with the same status code. Signing is
+// covered by the sender's own tests.
+type statusSender struct {
+	status int
+	calls  atomic.Int64
+	sent   []domain.WebhookDelivery
+}
+
+func (s *statusSender) Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	s.calls.Add(1)
+	s.sent = append(s.sent, delivery)
+	if s.status < 200 || s.status > 299 {
+		return s.status, fmt.Errorf("unexpected status code %d", s.status)
+	}
+	return s.status, nil
+}
+
+func newTestWebhookUsecase(t *testing.T, sender *statusSender) (*webhookUsecase, *memoryWebhookRepo) {
+	t.Helper()
+
+	repo := &memoryWebhookRepo{
+		webhook: domain.Webhook{ID: 1, ShopID: 7, URL: "https://hooks.example.com", Secret: "s3cret", Active: true},
+		deliveries: map[int64]domain.WebhookDelivery{
+			10: {
+				ID:            10,
+				WebhookID:     1,
+				EventID:       "evt-1",
+				EventType:     domain.WebhookEventStockAvailabilityChanged,
+				Payload:       []byte(`{"id":"evt-1"}`),
+				Status:        domain.WebhookDeliveryStatusPending,
+				NextAttemptAt: time.Now().Add(-time.Second),
+			},
+		},
+	}
+	cfg := &config.Config{Webhook: config.WebhookConfig{TimeoutSeconds: 5, MaxAttempts: 3, BackoffBaseSeconds: 30}}
+
+	return &webhookUsecase{repo, sender, cfg}, repo
+}
+
+func TestDeliverDueMarksSucceeded(t *testing.T) {
+	sender := &statusSender{status: http.StatusNoContent}
+	u, repo := newTestWebhookUsecase(t, sender)
+
+	if err := u.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+
+	delivery := repo.delivery(10)
+	if sender.calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1", sender.calls.Load())
+	}
+	if sent := sender.sent[0]; sent.EventID != "evt-1" || sent.EventType != domain.WebhookEventStockAvailabilityChanged {
+		t.Fatalf("sent = %+v", sent)
+	}
+	if delivery.Status != domain.WebhookDeliveryStatusSucceeded || delivery.DeliveredAt == nil {
+		t.Fatalf("delivery = %+v, want succeeded", delivery)
+	}
+	if delivery.LastStatusCode != http.StatusNoContent {
+		t.Fatalf("last status code = %d", delivery.LastStatusCode)
+	}
+}
+
+func TestDeliverDueRetriesWithBackoffAndGivesUp(t *testing.T) {
+	sender := &statusSender{status: http.StatusServiceUnavailable}
+	u, repo := newTestWebhookUsecase(t, sender)
+
+	ctx := context.Background()
+	for attempt := int64(1); attempt <= 2; attempt++ {
+		before := time.Now()
+		if err := u.DeliverDue(ctx, before); err != nil {
+			t.Fatalf("DeliverDue: %v", err)
+		}
+
+		delivery := repo.delivery(10)
+		if delivery.Status != domain.WebhookDeliveryStatusPending || delivery.Attempts != attempt {
+			t.Fatalf("attempt %d: delivery = %+v, want pending", attempt, delivery)
+		}
+		if delivery.LastStatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("attempt %d: last status code = %d", attempt, delivery.LastStatusCode)
+		}
+
+		// 30s, then 60s
+		backoff := time.Duration(30<<(attempt-1)) * time.Second
+		if delay := delivery.NextAttemptAt.Sub(before); delay < backoff || delay > backoff+5*time.Second {
+			t.Fatalf("attempt %d: next attempt in %s, want %s", attempt, delay, backoff)
+		}
+
+		// not due yet, nothing is sent
+		if err := u.DeliverDue(ctx, before); err != nil {
+			t.Fatalf("DeliverDue: %v", err)
+		}
+		if sender.calls.Load() != attempt {
+			t.Fatalf("attempt %d: calls = %d before the backoff elapsed", attempt, sender.calls.Load())
+		}
+
+		repo.mu.Lock()
+		delivery = repo.deliveries[10]
+		delivery.NextAttemptAt = time.Now().Add(-time.Second)
+		repo.deliveries[10] = delivery
+		repo.mu.Unlock()
+	}
+
+	if err := u.DeliverDue(ctx, time.Now()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+
+	delivery := repo.delivery(10)
+	if delivery.Status != domain.WebhookDeliveryStatusFailed || delivery.Attempts != 3 {
+		t.Fatalf("delivery = %+v, want failed after 3 attempts", delivery)
+	}
+
+	if err := u.DeliverDue(ctx, time.Now().Add(24*time.Hour)); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if sender.calls.Load() != 3 {
+		t.Fatalf("calls = %d, want 3", sender.calls.Load())
+	}
+}
+
+func TestAllowedWebhookURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://hooks.example.com/stock", true},
+		{"https://203.0.113.10/stock", true},
+		{"http://hooks.example.com/stock", false},
+		{"ftp://hooks.example.com/stock", false},
+		{"https://localhost/stock", false},
+		{"https://api.localhost/stock", false},
+		{"https://127.0.0.1/stock", false},
+		{"https://10.1.2.3/stock", false},
+		{"https://192.168.1.1/stock", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"https://[::1]/stock", false},
+		{"https://[fe80::1]/stock", false},
+		{"https://[::ffff:127.0.0.1]/stock", false},
+		{"https://0.0.0.0/stock", false},
+		{"https:///stock", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := allowedWebhookURL(tt.url); got != tt.want {
+				t.Fatalf("allowedWebhookURL(%q) = %t, want %t", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"warehouse-service/app/domain"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const apiKeyUsage = `usage:
+  main apikey issue -name <name> -scopes <scope,...> [-expires <duration>]
+  main apikey list
+  main apikey revoke -id <id>
+
+scopes: reserve, init-stock, transfer-admin, snapshot, config`
+
+// runApiKeyCommand issues, lists and revokes API keys for internal and admin
+// callers. The plaintext key is printed once on issue and cannot be shown
+// again.
+func runApiKeyCommand(ctx context.Context, apiKeyUsecase domain.ApiKeyUsecase, reqValidator *validator.Validate, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	switch args[0] {
+	case "issue":
+		fs := flag.NewFlagSet("apikey issue", flag.ContinueOnError)
+		name := fs.String("name", "", "who the key is for, e.g. checkout-service")
+		scopes := fs.String("scopes", "", "comma separated scopes")
+		expires := fs.Duration("expires", 0, "lifetime of the key, e.g. 2160h; 0 never expires")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		req := domain.ApiKeyCreateRequest{Name: *name}
+		for _, scope := range strings.Split(*scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				req.Scopes = append(req.Scopes, domain.ApiKeyScope(scope))
+			}
+		}
+		if *expires > 0 {
+			expiresAt := time.Now().Add(*expires)
+			req.ExpiresAt = &expiresAt
+		}
+		if err := reqValidator.Struct(req); err != nil {
+			return fmt.Errorf("%w\n%s", err, apiKeyUsage)
+		}
+
+		apiKey, key, err := apiKeyUsecase.Issue(ctx, req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("id:     %d\nname:   %s\nscopes: %s\nkey:    %s\n\nstore the key now, it is not shown again\n",
+			apiKey.ID, apiKey.Name, joinScopes(apiKey.Scopes), key)
+		return nil
+
+	case "list":
+		apiKeys, err := apiKeyUsecase.GetList(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES\tLAST USED\tREVOKED")
+		for _, apiKey := range apiKeys {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", apiKey.ID, apiKey.Name, apiKey.Prefix, joinScopes(apiKey.Scopes),
+				formatTime(apiKey.ExpiresAt), formatTime(apiKey.LastUsedAt), formatTime(apiKey.RevokedAt))
+		}
+		return w.Flush()
+
+	case "revoke":
+		fs := flag.NewFlagSet("apikey revoke", flag.ContinueOnError)
+		id := fs.Int64("id", 0, "ID of the key, see `main apikey list`")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New(apiKeyUsage)
+		}
+
+		if err := apiKeyUsecase.Revoke(ctx, *id); err != nil {
+			return err
+		}
+		fmt.Printf("revoked api key %d\n", *id)
+		return nil
+
+	default:
+		return errors.New(apiKeyUsage)
+	}
+}
+
+func joinScopes(scopes []domain.ApiKeyScope) string {
+	s := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		s = append(s, string(scope))
+	}
+	return strings.Join(s, ",")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
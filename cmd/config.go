@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"warehouse-service/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = `usage:
+  main config validate
+  main config print [-format yaml|json]
+
+the config is read from CONFIG_FILE, the .env file (ENV_FILE) and the environment`
+
+// runConfigCommand checks or prints the effective config without connecting
+// to any dependency. Secrets are always redacted.
+func runConfigCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+
+	// Keep stdout for the command output
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	switch args[0] {
+	case "validate":
+		cfg, err := config.Load(ctx)
+		if err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("config is invalid:\n%w", err)
+		}
+		fmt.Println("config is valid")
+		return nil
+
+	case "print":
+		fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+		format := fs.String("format", "yaml", "output format, yaml or json")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		cfg, err := config.Load(ctx)
+		if err != nil {
+			return err
+		}
+
+		var out []byte
+		switch *format {
+		case "yaml":
+			out, err = yaml.Marshal(cfg.Redacted())
+		case "json":
+			out, err = json.MarshalIndent(cfg.Redacted(), "", "  ")
+			out = append(out, '\n')
+		default:
+			return fmt.Errorf("unknown format %q\n%s", *format, configUsage)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+
+	default:
+		return errors.New(configUsage)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"warehouse-service/app/domain"
+	"warehouse-service/app/repository/broker"
+	"warehouse-service/app/repository/db"
+	"warehouse-service/app/repository/webhook"
+	"warehouse-service/app/usecase"
+	"warehouse-service/config"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// deps are the repositories and usecases shared by serve and the
+// operational subcommands, so fixes made from the CLI publish the same
+// events and keep the same invariants as the API.
+type deps struct {
+	validator *validator.Validate
+
+	warehouseRepo domain.WarehouseRepository
+	stockRepo     domain.StockRepository
+
+	apiKeyUsecase                domain.ApiKeyUsecase
+	webhookUsecase               domain.WebhookUsecase
+	stockStreamUsecase           domain.StockStreamUsecase
+	stockAlertUsecase            domain.StockAlertUsecase
+	warehouseUsecase             domain.WarehouseService
+	stockTransferUsecase         domain.StockTransferUsecase
+	replenishmentUsecase         domain.ReplenishmentUsecase
+	stockUsecase                 domain.StockService
+	reservedStockUsecase         domain.ReservedStockUsecase
+	snapshotUsecase              domain.SnapshotUsecase
+	userRoleUsecase              domain.UserRoleUsecase
+	stockTransferScheduleUsecase domain.StockTransferScheduleUsecase
+	reconciliationUsecase        domain.ReconciliationUsecase
+	stocktakeUsecase             domain.StocktakeUsecase
+}
+
+func newDeps(cfg *config.Config, live *config.Live, dbConn *sql.DB, js jetstream.JetStream) *deps {
+	warehouseRepo := db.NewWarehouseRepository(dbConn)
+	stockRepo := db.NewStockRepository(dbConn)
+	stockAlertRepo := db.NewStockAlertRepository(dbConn)
+	stockTransferRepo := db.NewStockTransferRepository(dbConn)
+	reservedStockRepo := db.NewReservedStockRepository(dbConn)
+	stockTransferScheduleRepo := db.NewStockTransferScheduleRepository(dbConn)
+	replenishmentRuleRepo := db.NewReplenishmentRuleRepository(dbConn)
+	webhookRepo := db.NewWebhookRepository(dbConn)
+	userRoleRepo := db.NewUserRoleRepository(dbConn)
+	reconciliationRepo := db.NewReconciliationRepository(dbConn)
+	stocktakeRepo := db.NewStocktakeRepository(dbConn)
+	stockAdjustmentRepo := db.NewStockAdjustmentRepository(dbConn)
+
+	// Events published to NATS are also queued for shop webhooks and pushed
+	// to SSE subscribers, and availability updates go through the alerting
+	// publisher so threshold crossings are detected for every usecase.
+	eventSequenceRepo := db.NewEventSequenceRepository(dbConn)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender(time.Duration(cfg.Webhook.TimeoutSeconds)*time.Second), cfg)
+	stockStreamUsecase := usecase.NewStockStreamUsecase(cfg.Stream.BufferSize)
+	webhookPublisher := usecase.NewWebhookPublisher(broker.NewStockBrokerPublisher(js, eventSequenceRepo, cfg), webhookUsecase)
+	eventPublisher := usecase.NewStreamPublisher(webhookPublisher, stockStreamUsecase)
+	stockAlertUsecase := usecase.NewStockAlertUsecase(stockAlertRepo, stockRepo, eventPublisher, live)
+	stockBroker := usecase.NewAlertingPublisher(eventPublisher, stockAlertUsecase)
+
+	stockTransferUsecase := usecase.NewStockTransferUsecase(stockTransferRepo, warehouseRepo, stockRepo, reservedStockRepo, stockBroker)
+	replenishmentUsecase := usecase.NewReplenishmentUsecase(replenishmentRuleRepo, warehouseRepo, stockRepo, reservedStockRepo, stockTransferRepo, stockTransferUsecase)
+	// Transfers taking stock out of a warehouse may push it below its rule
+	stockTransferUsecase = usecase.NewReplenishingTransferUsecase(stockTransferUsecase, stockRepo, replenishmentUsecase)
+
+	return &deps{
+		validator: newValidator(),
+
+		warehouseRepo: warehouseRepo,
+		stockRepo:     stockRepo,
+
+		apiKeyUsecase:                usecase.NewApiKeyUsecase(db.NewApiKeyRepository(dbConn)),
+		webhookUsecase:               webhookUsecase,
+		stockStreamUsecase:           stockStreamUsecase,
+		stockAlertUsecase:            stockAlertUsecase,
+		warehouseUsecase:             usecase.NewWarehouseUsecase(warehouseRepo, stockRepo, reservedStockRepo, stockBroker, cfg),
+		stockTransferUsecase:         stockTransferUsecase,
+		replenishmentUsecase:         replenishmentUsecase,
+		stockUsecase:                 usecase.NewStockUsecase(stockRepo, warehouseRepo, reservedStockRepo, stockBroker, replenishmentUsecase, stockAdjustmentRepo, cfg),
+		reservedStockUsecase:         usecase.NewReservedStockUsecase(stockRepo, reservedStockRepo, stockBroker, replenishmentUsecase, cfg),
+		snapshotUsecase:              usecase.NewSnapshotUsecase(stockRepo, stockBroker),
+		userRoleUsecase:              usecase.NewUserRoleUsecase(userRoleRepo, cfg),
+		stockTransferScheduleUsecase: usecase.NewStockTransferScheduleUsecase(stockTransferScheduleRepo, warehouseRepo, stockRepo, reservedStockRepo, stockTransferUsecase),
+		reconciliationUsecase:        usecase.NewReconciliationUsecase(reconciliationRepo),
+		stocktakeUsecase:             usecase.NewStocktakeUsecase(stocktakeRepo, warehouseRepo, stockRepo, reservedStockRepo, stockBroker, replenishmentUsecase, stockAdjustmentRepo),
+	}
+}
+
+func newValidator() *validator.Validate {
+	reqValidator := validator.New()
+	// Report json/query names in validation errors instead of Go field names
+	reqValidator.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "query"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	return reqValidator
+}
+
+type natsConn struct {
+	nc         *nats.Conn
+	js         jetstream.JetStream
+	streamName string
+	// closed is closed once a drain has finished
+	closed chan struct{}
+}
+
+// connectNats connects to NATS and makes sure the stock stream exists.
+func connectNats(ctx context.Context, cfg *config.Config) (*natsConn, error) {
+	closed := make(chan struct{})
+	nc, err := nats.Connect(cfg.Nats.Url, // default is nats://localhost:4222
+		nats.Name(cfg.Nats.Name),
+		nats.Timeout(time.Duration(cfg.Nats.ConnectTimeoutSeconds)*time.Second),
+		nats.ReconnectWait(time.Duration(cfg.Nats.ReconnectWaitSeconds)*time.Second),
+		nats.MaxReconnects(cfg.Nats.MaxReconnects),
+		nats.ClosedHandler(func(*nats.Conn) { close(closed) }),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("create JetStream context: %w", err)
+	}
+	streamName := strings.ToUpper(cfg.Nats.StreamName)
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     streamName,
+		Subjects: []string{fmt.Sprintf("%s.*", strings.ToLower(cfg.Nats.StreamName))},
+		Storage:  jetstream.FileStorage,
+		// Publishes carry Nats-Msg-Id, retries inside this window are dropped
+		Duplicates: 2 * time.Minute,
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		nc.Close()
+		return nil, fmt.Errorf("create %s stream: %w", streamName, err)
+	}
+
+	return &natsConn{nc: nc, js: js, streamName: streamName, closed: closed}, nil
+}
@@ -6,123 +6,134 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
-	handler "warehouse-service/app/handler/api"
-	"warehouse-service/app/middleware"
-	"warehouse-service/app/repository/broker"
 	"warehouse-service/app/repository/db"
 	"warehouse-service/app/usecase"
 	"warehouse-service/config"
 	"warehouse-service/pkg/logger"
-
-	"github.com/go-playground/validator/v10"
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/healthcheck"
-	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/nats-io/nats.go"
-	"github.com/nats-io/nats.go/jetstream"
-	slogfiber "github.com/samber/slog-fiber"
+	"warehouse-service/pkg/metrics"
+	"warehouse-service/pkg/tracing"
 )
 
+const usage = `usage: main [command] [args]
+
+commands:
+  serve                 run the http and grpc servers (default)
+  migrate               apply or roll back the database migrations
+  apikey                issue, list and revoke api keys
+  config                validate or print the effective config
+  reconcile             check stock invariants, print violations as json, optionally repair
+  reservations expire   cancel active reservations older than a cutoff
+  stock adjust          set the quantity of a stock row
+  transfer show         print a stock transfer as json
+  events replay         republish current availability events
+
+run a command without args for its usage`
+
 func main() {
 	// init logger
 	logger.InitLogger()
 
 	ctx := context.Background()
+
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	if err := run(ctx, command, args); err != nil {
+		if command == "serve" {
+			slog.Error("failed to serve", "error", err)
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run loads the config and connects only what command needs. Every command
+// other than serve writes its output to stdout and logs warnings to stderr.
+func run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "help":
+		fmt.Println(usage)
+		return nil
+	case "config":
+		// `main config ...` checks the config without connecting to anything
+		return runConfigCommand(ctx, args)
+	case "serve":
+	case "migrate", "apikey", "reconcile", "reservations", "stock", "transfer", "events":
+		// Keep stdout for the command output
+		logger.Level.Set(slog.LevelWarn)
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logger.Level})))
+	default:
+		return errors.New(usage)
+	}
+
 	// init config
 	cfg, err := config.InitConfig(ctx)
 	if err != nil {
-		slog.Error("failed to init config", "error", err)
-		return
+		return fmt.Errorf("init config: %w", err)
 	}
-
-	// init database
-	dbConn, err := db.NewPostgres(cfg.Db)
-	if err != nil {
-		slog.Error("DB connection failed", "error", err)
+	// Reloadable settings are read through live, see config.ReloadableKeys
+	live := config.NewLive(cfg)
+	if command == "serve" {
+		if err := logger.SetLevel(cfg.Log.Level); err != nil {
+			return fmt.Errorf("set log level: %w", err)
+		}
+		live.OnReload(func(cfg *config.Config) {
+			if err := logger.SetLevel(cfg.Log.Level); err != nil {
+				slog.Error("failed to set log level", "error", err)
+			}
+		})
 	}
-	defer dbConn.Close()
 
-	// Connect to NATS server
-	nc, err := nats.Connect(cfg.Nats.Url) // default is nats://localhost:4222
+	// init tracing, before the DB so its statements are traced
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		slog.Error("Error connecting to NATS", "error", err)
-		return
+		return fmt.Errorf("init tracing: %w", err)
 	}
-	defer nc.Drain()
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}()
 
-	js, err := jetstream.New(nc)
+	// init database
+	dbConn, err := db.NewPostgres(cfg.Db)
 	if err != nil {
-		slog.Error("Error creating JetStream context", "error", err)
-		return
+		return fmt.Errorf("DB connection failed: %w", err)
 	}
-	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
-		Name:     strings.ToUpper(cfg.Nats.StreamName),
-		Subjects: []string{fmt.Sprintf("%s.*", strings.ToLower(cfg.Nats.StreamName))},
-		Storage:  jetstream.FileStorage,
-	})
-	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
-		slog.Error("create STOCK stream failed", "error", err)
-		return
+	// serve closes the pool as its last shutdown step
+	if command != "serve" {
+		defer dbConn.Close()
 	}
 
-	reqValidator := validator.New()
-	warehouseRepo := db.NewWarehouseRepository(dbConn)
-	stockRepo := db.NewStockRepository(dbConn)
-	stockBroker := broker.NewStockBrokerPublisher(js)
-	stockTransferRepo := db.NewStockTransferRepository(dbConn)
-	reservedStockRepo := db.NewReservedStockRepository(dbConn)
-
-	warehouseUsecase := usecase.NewWarehouseUsecase(warehouseRepo, stockRepo, reservedStockRepo, stockBroker, cfg)
-	stockUsecase := usecase.NewStockUsecase(stockRepo, warehouseRepo, reservedStockRepo, stockBroker, cfg)
-	stockTransferUsecase := usecase.NewStockTransferUsecase(stockTransferRepo, warehouseRepo, stockRepo, reservedStockRepo, stockBroker)
-	reservedStockUsecase := usecase.NewReservedStockUsecase(stockRepo, reservedStockRepo, stockBroker, cfg)
-
-	warehouseHandler := handler.NewWarehouseHandler(warehouseUsecase, reqValidator)
-	stockHandler := handler.NewStockHandler(stockUsecase, reqValidator)
-	stockTransferHandler := handler.NewStockTransferHandler(stockTransferUsecase, reqValidator)
-	reservedStockHandler := handler.NewReservedStockHandler(reservedStockUsecase, reqValidator)
-
-	// Initialize HTTP web framework
-	app := fiber.New()
-	app.Use(healthcheck.New(healthcheck.Config{
-		LivenessProbe: func(c *fiber.Ctx) bool {
-			return true
-		},
-		LivenessEndpoint: "/live",
-		ReadinessProbe: func(c *fiber.Ctx) bool {
-			return true
-		},
-		ReadinessEndpoint: "/ready",
-	}))
-	webLogger := slog.New(&logger.RequestIDHandler{Handler: slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})})
-	app.Use(slogfiber.New(webLogger))
-	app.Use(recover.New())
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-	}))
-	app.Use(middleware.RequestIDMiddleware())
-
-	handler.SetupRouter(app, warehouseHandler, stockHandler, stockTransferHandler, reservedStockHandler, cfg)
-
-	go func() {
-		if err := app.Listen(":" + cfg.Port); err != nil {
-			slog.Error("Failed to listen", "port", cfg.Port)
-			return
-		}
-	}()
+	switch command {
+	case "migrate":
+		return runMigrateCommand(dbConn, args)
+	case "apikey":
+		// `main apikey ...` manages API keys and exits without starting the server
+		return runApiKeyCommand(ctx, usecase.NewApiKeyUsecase(db.NewApiKeyRepository(dbConn)), newValidator(), args)
+	}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
-	slog.Info("Gracefully shutdown")
-	err = app.Shutdown()
+	n, err := connectNats(ctx, cfg)
 	if err != nil {
-		slog.Warn("Unfortunately the shutdown wasn't smooth", "err", err)
+		return err
 	}
+	d := newDeps(cfg, live, dbConn, n.js)
+
+	if command == "serve" {
+		metrics.RegisterDB(dbConn, cfg.Db.DbName)
+		return runServe(ctx, cfg, live, dbConn, n, d)
+	}
+
+	// Publishes are acknowledged by JetStream, nothing is left to drain
+	defer n.nc.Close()
+	return runOpsCommand(ctx, d, command, args)
 }
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"warehouse-service/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+const migrateUsage = `usage:
+  main migrate up
+  main migrate down [-steps <n>]
+  main migrate version
+  main migrate force -version <n>
+
+migrations are embedded from migrations/, the applied version is kept in schema_migrations.
+the first one also creates the base tables (warehouses, stocks, reserved_stocks, stock_transfers)
+when missing, so an empty database can be migrated up`
+
+// runMigrateCommand applies the embedded migrations. The bookkeeping table
+// is the golang-migrate one, so databases migrated with the migrate CLI
+// carry on from their current version.
+func runMigrateCommand(dbConn *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+	driver, err := pgx.WithInstance(dbConn, &pgx.Config{})
+	if err != nil {
+		return fmt.Errorf("migrate driver: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *steps <= 0 {
+			return fmt.Errorf("-steps must be positive\n%s", migrateUsage)
+		}
+		err = m.Steps(-*steps)
+
+	case "force":
+		fs := flag.NewFlagSet("migrate force", flag.ContinueOnError)
+		version := fs.Int("version", -1, "version to mark as applied after fixing a failed migration by hand")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *version < 0 {
+			return fmt.Errorf("-version is required\n%s", migrateUsage)
+		}
+		err = m.Force(*version)
+
+	case "version":
+		// printed below
+
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("version: none")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty:   %t\n", version, dirty)
+	return nil
+}
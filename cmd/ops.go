@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"warehouse-service/app/domain"
+)
+
+const opsUsage = `usage:
+  main reconcile [-repair]
+  main reservations expire -older-than <duration> [-limit <n>] [-dry-run]
+  main stock adjust -id <stock id> -quantity <n>
+  main stock adjust -id <stock id> -delta <+n|-n> -reason <reason> [-note <text>]
+  main transfer show -id <transfer id>
+  main events replay [-shop <id>] [-products <id,...>] [-rate <per second>]
+
+changes go through the same usecases as the api, events are published as usual`
+
+// runOpsCommand runs the operational subcommands on-call uses to inspect and
+// fix data. Results are printed as JSON.
+func runOpsCommand(ctx context.Context, d *deps, command string, args []string) error {
+	if command != "reconcile" {
+		if len(args) == 0 {
+			return errors.New(opsUsage)
+		}
+		command, args = command+" "+args[0], args[1:]
+	}
+
+	switch command {
+	case "reconcile":
+		fs := flag.NewFlagSet(command, flag.ContinueOnError)
+		repair := fs.Bool("repair", false, "create missing empty stock rows, other violations are only reported")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		report, err := d.reconciliationUsecase.Check(ctx, *repair)
+		if printErr := printJSON(report); printErr != nil {
+			return printErr
+		}
+		if err != nil {
+			return err
+		}
+		// Exit non-zero while something is left to look at, so it can gate scripts
+		if unrepaired := int64(len(report.Violations)) - report.Repaired; unrepaired > 0 {
+			return fmt.Errorf("%d violations left", unrepaired)
+		}
+		return nil
+
+	case "reservations expire":
+		fs := flag.NewFlagSet(command, flag.ContinueOnError)
+		olderThan := fs.Duration("older-than", 0, "cancel active reservations created before now minus this, e.g. 24h")
+		limit := fs.Int64("limit", 1000, "maximum reservations to cancel")
+		dryRun := fs.Bool("dry-run", false, "only list the reservations")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *olderThan <= 0 || *limit <= 0 {
+			return fmt.Errorf("-older-than and -limit must be positive\n%s", opsUsage)
+		}
+
+		reservedStocks, err := d.reservedStockUsecase.ExpireReservedStocks(ctx, time.Now().Add(-*olderThan), *limit, *dryRun)
+		if printErr := printJSON(map[string]any{"dry_run": *dryRun, "reservations": reservedStocks}); printErr != nil {
+			return printErr
+		}
+		return err
+
+	case "stock adjust":
+		fs := flag.NewFlagSet(command, flag.ContinueOnError)
+		id := fs.Int64("id", 0, "stock id")
+		quantity := fs.Int64("quantity", -1, "new quantity, not below the active reservations")
+		delta := fs.Int64("delta", 0, "change of the current quantity, instead of -quantity")
+		reason := fs.String("reason", "", "reason of a -delta: received, damaged, lost, found, correction or returned")
+		note := fs.String("note", "", "note of a -delta")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *id <= 0 || (*quantity < 0) == (*delta == 0) {
+			return fmt.Errorf("-id and one of -quantity or -delta are required\n%s", opsUsage)
+		}
+
+		stock, err := d.stockRepo.GetByID(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("get stock %d: %w", *id, err)
+		}
+		warehouse, err := d.warehouseRepo.GetByID(ctx, stock.WarehouseID)
+		if err != nil {
+			return fmt.Errorf("get warehouse %d: %w", stock.WarehouseID, err)
+		}
+		if *delta != 0 {
+			req := domain.StockAdjustRequest{Delta: *delta, Reason: domain.AdjustmentReason(*reason), Note: *note}
+			if err := d.validator.Struct(req); err != nil {
+				return fmt.Errorf("%w\n%s", err, opsUsage)
+			}
+			adjustment, err := d.stockUsecase.AdjustQuantity(ctx, stock.ID, warehouse.ShopID, 0, req)
+			if err != nil {
+				return err
+			}
+			return printJSON(adjustment)
+		}
+		err = d.stockUsecase.UpdateQuantity(ctx, stock.ID, warehouse.ShopID, 0, domain.UpdateQuantityRequest{Quantity: *quantity})
+		if err != nil {
+			return err
+		}
+
+		stock, err = d.stockRepo.GetByID(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("get stock %d: %w", *id, err)
+		}
+		return printJSON(stock)
+
+	case "transfer show":
+		fs := flag.NewFlagSet(command, flag.ContinueOnError)
+		id := fs.Int64("id", 0, "transfer id")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *id <= 0 {
+			return fmt.Errorf("-id is required\n%s", opsUsage)
+		}
+
+		transfer, err := d.stockTransferUsecase.GetTransferByID(ctx, *id, nil)
+		if err != nil {
+			return err
+		}
+		return printJSON(transfer)
+
+	case "events replay":
+		fs := flag.NewFlagSet(command, flag.ContinueOnError)
+		shopID := fs.Int64("shop", 0, "only products of this shop, 0 for every shop")
+		products := fs.String("products", "", "comma separated product ids, instead of a whole shop")
+		rate := fs.Int64("rate", 0, "events per second, defaults to the snapshot rate")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		req := domain.AvailabilityReplayRequest{ShopID: *shopID, RatePerSecond: *rate}
+		for _, product := range strings.Split(*products, ",") {
+			if product = strings.TrimSpace(product); product == "" {
+				continue
+			}
+			productID, err := strconv.ParseInt(product, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid product id %q\n%s", product, opsUsage)
+			}
+			req.ProductIDs = append(req.ProductIDs, productID)
+		}
+
+		published, err := d.snapshotUsecase.ReplayAvailability(ctx, req)
+		if printErr := printJSON(map[string]int64{"published": published}); printErr != nil {
+			return printErr
+		}
+		return err
+
+	default:
+		return errors.New(opsUsage)
+	}
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
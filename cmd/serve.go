@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	handler "warehouse-service/app/handler/api"
+	grpchandler "warehouse-service/app/handler/grpc"
+	"warehouse-service/app/middleware"
+	"warehouse-service/app/scheduler"
+	"warehouse-service/config"
+	"warehouse-service/pkg"
+	"warehouse-service/pkg/health"
+	"warehouse-service/pkg/lifecycle"
+	"warehouse-service/pkg/logger"
+	"warehouse-service/pkg/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/healthcheck"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/nats-io/nats.go"
+	slogfiber "github.com/samber/slog-fiber"
+)
+
+// runServe starts the HTTP and gRPC servers and the background workers, and
+// blocks until SIGINT or SIGTERM.
+func runServe(ctx context.Context, cfg *config.Config, live *config.Live, dbConn *sql.DB, n *natsConn, d *deps) error {
+	// Tokens are verified with the HMAC secret and/or the JWKS, so issuers
+	// can move to asymmetric keys without a flag day.
+	jwtVerifierCfg := pkg.JwtVerifierConfig{
+		SecretKey:         cfg.Jwt.SecretKey,
+		Issuer:            cfg.Jwt.Issuer,
+		Audience:          cfg.Jwt.Audience,
+		Leeway:            time.Duration(cfg.Jwt.LeewaySeconds) * time.Second,
+		RequireExpiration: cfg.Jwt.RequireExpiration,
+	}
+	if cfg.Jwt.JwksUrl != "" {
+		jwks, err := pkg.NewJWKS(ctx, cfg.Jwt.JwksUrl, time.Duration(cfg.Jwt.JwksRefreshSeconds)*time.Second)
+		if err != nil {
+			return fmt.Errorf("load JWKS: %w", err)
+		}
+		jwtVerifierCfg.JWKS = jwks
+	}
+	jwtVerifier := pkg.NewJwtVerifier(jwtVerifierCfg)
+
+	warehouseHandler := handler.NewWarehouseHandler(d.warehouseUsecase, d.validator)
+	stockHandler := handler.NewStockHandler(d.stockUsecase, d.validator)
+	stockTransferHandler := handler.NewStockTransferHandler(d.stockTransferUsecase, d.validator)
+	reservedStockHandler := handler.NewReservedStockHandler(d.reservedStockUsecase, d.validator)
+	stockTransferScheduleHandler := handler.NewStockTransferScheduleHandler(d.stockTransferScheduleUsecase, d.validator)
+	replenishmentHandler := handler.NewReplenishmentHandler(d.replenishmentUsecase, d.validator)
+	stockAlertHandler := handler.NewStockAlertHandler(d.stockAlertUsecase, d.validator)
+	snapshotHandler := handler.NewSnapshotHandler(d.snapshotUsecase, d.validator)
+	webhookHandler := handler.NewWebhookHandler(d.webhookUsecase, d.validator)
+	userRoleHandler := handler.NewUserRoleHandler(d.userRoleUsecase, d.validator)
+	configHandler := handler.NewConfigHandler(live)
+	stocktakeHandler := handler.NewStocktakeHandler(d.stocktakeUsecase, d.validator)
+	stockStreamHandler := handler.NewStockStreamHandler(d.stockStreamUsecase, time.Duration(cfg.Stream.HeartbeatSeconds)*time.Second)
+
+	// Start background workers, they are stopped in this order on shutdown
+	lc := lifecycle.New()
+	transferScheduler := scheduler.NewStockTransferScheduler(d.stockTransferScheduleUsecase, time.Duration(cfg.Scheduler.TransferIntervalSeconds)*time.Second)
+	lc.Go(ctx, "transfer scheduler", transferScheduler.Start)
+	webhookDispatcher := scheduler.NewWebhookDispatcher(d.webhookUsecase, time.Duration(cfg.Webhook.DispatchIntervalSeconds)*time.Second)
+	lc.Go(ctx, "webhook dispatcher", webhookDispatcher.Start)
+	if cfg.Reconciliation.IntervalSeconds > 0 {
+		reconciliationScheduler := scheduler.NewReconciliationScheduler(d.reconciliationUsecase, time.Duration(cfg.Reconciliation.IntervalSeconds)*time.Second, cfg.Reconciliation.AutoRepair)
+		lc.Go(ctx, "reconciliation scheduler", reconciliationScheduler.Start)
+	}
+
+	// Reload the runtime settings on SIGHUP or when CONFIG_FILE changes
+	live.Watch(ctx)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	lc.Go(ctx, "config reloader", func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				// Errors are logged by Reload, the running config stays in place
+				_ = live.Reload(ctx)
+			}
+		}
+	})
+
+	// Rate limit buckets are kept in process, shared by HTTP and gRPC
+	cleanupCtx, stopCleanup := context.WithCancel(ctx)
+	defer stopCleanup()
+	rateLimitStore := ratelimit.NewMemoryStore(cleanupCtx, time.Minute)
+	concurrency := ratelimit.NewConcurrency()
+
+	// Readiness follows the dependencies after startup; a dropped NATS
+	// connection or a deleted stream takes the pod out of rotation.
+	healthChecker := health.NewChecker(
+		time.Duration(cfg.Health.CheckTimeoutSeconds)*time.Second,
+		time.Duration(cfg.Health.CacheTTLSeconds)*time.Second,
+		health.Check{Name: "postgres", Check: dbConn.PingContext},
+		health.Check{Name: "nats", Check: func(ctx context.Context) error {
+			if status := n.nc.Status(); status != nats.CONNECTED {
+				return fmt.Errorf("connection %s", status)
+			}
+			return nil
+		}},
+		health.Check{Name: "jetstream", Check: func(ctx context.Context) error {
+			_, err := n.js.Stream(ctx, n.streamName)
+			return err
+		}},
+	)
+
+	// Initialize HTTP web framework
+	app := fiber.New(fiber.Config{
+		ReadTimeout:  time.Duration(cfg.Http.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout: time.Duration(cfg.Http.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:  time.Duration(cfg.Http.IdleTimeoutSeconds) * time.Second,
+		BodyLimit:    cfg.Http.BodyLimitBytes,
+	})
+	app.Use(healthcheck.New(healthcheck.Config{
+		// Liveness only says the process serves requests, a dependency outage
+		// must not get it restarted.
+		LivenessProbe: func(c *fiber.Ctx) bool {
+			return true
+		},
+		LivenessEndpoint: "/live",
+		ReadinessProbe: func(c *fiber.Ctx) bool {
+			return healthChecker.Ready(c.UserContext())
+		},
+		ReadinessEndpoint: "/ready",
+	}))
+	app.Get("/health", handler.HealthReport(healthChecker))
+	webLogger := slog.New(&logger.RequestIDHandler{Handler: slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logger.Level,
+	})})
+	app.Use(slogfiber.New(webLogger))
+	app.Use(recover.New())
+	app.Use(cors.New(cors.Config{
+		AllowOrigins: "*",
+	}))
+	app.Use(middleware.Tracing())
+	app.Use(middleware.RequestIDMiddleware())
+	if cfg.Metrics.Enabled {
+		app.Use(middleware.Metrics())
+	}
+
+	handler.SetupRouter(app, warehouseHandler, stockHandler, stockTransferHandler, reservedStockHandler, stockTransferScheduleHandler, replenishmentHandler, stockAlertHandler, snapshotHandler, webhookHandler, stockStreamHandler, userRoleHandler, configHandler, stocktakeHandler, d.userRoleUsecase, jwtVerifier, d.apiKeyUsecase, rateLimitStore, concurrency, live)
+	for _, route := range handler.UndocumentedRoutes(app.GetRoutes(true)) {
+		slog.Warn("Route missing from openapi.json", "route", route)
+	}
+
+	go func() {
+		if err := app.Listen(":" + cfg.Port); err != nil {
+			slog.Error("Failed to listen", "port", cfg.Port)
+			return
+		}
+	}()
+
+	// gRPC server for internal callers, alongside the HTTP API
+	grpcServer := grpchandler.NewServer(grpchandler.NewWarehouseServer(d.stockUsecase, d.reservedStockUsecase, d.stockTransferUsecase, d.validator), d.apiKeyUsecase, rateLimitStore, concurrency, live)
+	go func() {
+		lis, err := net.Listen("tcp", ":"+cfg.GrpcPort)
+		if err != nil {
+			slog.Error("Failed to listen", "port", cfg.GrpcPort, "error", err)
+			return
+		}
+		if err := grpcServer.Serve(lis); err != nil {
+			slog.Error("Failed to serve gRPC", "port", cfg.GrpcPort, "error", err)
+		}
+	}()
+
+	// Shutdown order: stop taking requests and let in-flight ones finish,
+	// then stop workers, drain pending publishes and close the DB pool last.
+	lc.OnStop("http", func(ctx context.Context) error {
+		// Close open SSE streams first, otherwise Shutdown waits on them.
+		d.stockStreamUsecase.Close()
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			return fmt.Errorf("%d connections still open: %w", app.Server().GetOpenConnectionsCount(), err)
+		}
+		return nil
+	})
+	lc.OnStop("grpc", func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			grpcServer.Stop()
+			return fmt.Errorf("in-flight rpcs cancelled: %w", ctx.Err())
+		}
+	})
+	lc.OnStop("workers", lc.StopWorkers)
+	lc.OnStop("nats", func(ctx context.Context) error {
+		if err := n.nc.Drain(); err != nil {
+			return err
+		}
+		select {
+		case <-n.closed:
+			return nil
+		case <-ctx.Done():
+			n.nc.Close()
+			return fmt.Errorf("pending messages dropped: %w", ctx.Err())
+		}
+	})
+	lc.OnStop("postgres", func(ctx context.Context) error {
+		// Close waits for running queries to finish, stop waiting at the
+		// deadline and let the process exit take the connections
+		closed := make(chan error, 1)
+		go func() {
+			closed <- dbConn.Close()
+		}()
+		select {
+		case err := <-closed:
+			return err
+		case <-ctx.Done():
+			return fmt.Errorf("queries still running: %w", ctx.Err())
+		}
+	})
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+	slog.Info("Gracefully shutdown", "timeout", cfg.Shutdown.TimeoutSeconds)
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Shutdown.TimeoutSeconds)*time.Second)
+	defer cancel()
+	if abandoned := lc.Shutdown(shutdownCtx); len(abandoned) > 0 {
+		slog.Warn("Unfortunately the shutdown wasn't smooth", "abandoned", abandoned)
+	}
+	return nil
+}
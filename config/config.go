@@ -2,8 +2,11 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -11,129 +14,345 @@ import (
 )
 
 type Config struct {
-	Port                     string     `mapstructure:"PORT" validate:"required"`
-	InternalAuthHeader       string     `mapstructure:"INTERNAL_AUTH_HEADER" validate:"required"`
-	WarehouseAdminAuthHeader string     `mapstructure:"WAREHOUSE_ADMIN_AUTH_HEADER" validate:"required"`
-	Db                       DbConfig   `mapstructure:",squash"`
-	Jwt                      JwtConfig  `mapstructure:",squash"`
-	Nats                     NatsConfig `mapstructure:",squash"`
+	Port     string `mapstructure:"port" validate:"required"`
+	GrpcPort string `mapstructure:"grpc_port" validate:"required"`
+	// Static secrets accepted next to API keys while callers migrate. Leave
+	// empty to only accept API keys.
+	InternalAuthHeader       string               `mapstructure:"internal_auth_header" redact:"true"`
+	WarehouseAdminAuthHeader string               `mapstructure:"warehouse_admin_auth_header" redact:"true"`
+	Log                      LogConfig            `mapstructure:"log"`
+	Http                     HttpConfig           `mapstructure:"http"`
+	Db                       DbConfig             `mapstructure:"db"`
+	Jwt                      JwtConfig            `mapstructure:"jwt"`
+	Nats                     NatsConfig           `mapstructure:"nats"`
+	Scheduler                SchedulerConfig      `mapstructure:"transfer_scheduler"`
+	Event                    EventConfig          `mapstructure:"event"`
+	Webhook                  WebhookConfig        `mapstructure:"webhook"`
+	Stream                   StreamConfig         `mapstructure:"stock_stream"`
+	OpenAPI                  OpenAPIConfig        `mapstructure:"openapi"`
+	Rbac                     RbacConfig           `mapstructure:"rbac"`
+	Alert                    AlertConfig          `mapstructure:"alert"`
+	RateLimit                RateLimitConfig      `mapstructure:"rate_limit"`
+	Metrics                  MetricsConfig        `mapstructure:"metrics"`
+	Tracing                  TracingConfig        `mapstructure:"tracing"`
+	Health                   HealthConfig         `mapstructure:"health"`
+	Shutdown                 ShutdownConfig       `mapstructure:"shutdown"`
+	Reconciliation           ReconciliationConfig `mapstructure:"reconciliation"`
 }
 
 type DbConfig struct {
-	Host     string `mapstructure:"DB_HOST" validate:"required"`
-	Port     string `mapstructure:"DB_PORT" validate:"required"`
-	Username string `mapstructure:"DB_USERNAME" validate:"required"`
-	Password string `mapstructure:"DB_PASSWORD" validate:"required"`
-	DbName   string `mapstructure:"DB_DBNAME" validate:"required"`
-	SSLMode  string `mapstructure:"DB_SSLMODE"`
+	Host     string `mapstructure:"host" validate:"required"`
+	Port     string `mapstructure:"port" validate:"required"`
+	Username string `mapstructure:"username" validate:"required" redact:"true"`
+	Password string `mapstructure:"password" validate:"required" redact:"true"`
+	DbName   string `mapstructure:"dbname" validate:"required"`
+	SSLMode  string `mapstructure:"sslmode"`
+	// Connection pool
+	MaxOpenConns           int   `mapstructure:"max_open_conns" validate:"gt=0"`
+	MaxIdleConns           int   `mapstructure:"max_idle_conns" validate:"gte=0,ltefield=MaxOpenConns"`
+	ConnMaxLifetimeSeconds int64 `mapstructure:"conn_max_lifetime" validate:"gte=0"` // 0 keeps connections forever
+}
+
+type LogConfig struct {
+	Level string `mapstructure:"level" validate:"oneof=debug info warn error" reload:"true"`
+}
+
+type HttpConfig struct {
+	ReadTimeoutSeconds int64 `mapstructure:"read_timeout" validate:"gte=0"`
+	// WriteTimeoutSeconds also caps SSE streams, leave it 0 while
+	// /stocks/stream is served by this instance.
+	WriteTimeoutSeconds int64 `mapstructure:"write_timeout" validate:"gte=0"`
+	IdleTimeoutSeconds  int64 `mapstructure:"idle_timeout" validate:"gte=0"`
+	BodyLimitBytes      int   `mapstructure:"body_limit" validate:"gt=0"`
 }
 
 type JwtConfig struct {
-	SecretKey string `mapstructure:"JWT_SECRETKEY" validate:"required"`
-	Expire    int64  `mapstructure:"JWT_EXPIRE" validate:"required"`
+	// SecretKey verifies HS256 tokens. It can be left empty once every
+	// issuer signs with a key from the JWKS.
+	SecretKey string `mapstructure:"secretkey" validate:"required_without=JwksUrl" redact:"true"`
+	Expire    int64  `mapstructure:"expire" validate:"required"`
+	// JwksUrl is an http(s) URL or a file path of the JSON Web Key Set used
+	// for RS256, ES256 and EdDSA tokens.
+	JwksUrl            string `mapstructure:"jwks_url"`
+	JwksRefreshSeconds int64  `mapstructure:"jwks_refresh" validate:"required,gt=0"`
+	Issuer             string `mapstructure:"issuer"`
+	Audience           string `mapstructure:"audience"`
+	LeewaySeconds      int64  `mapstructure:"leeway" validate:"gte=0"`
+	RequireExpiration  bool   `mapstructure:"require_exp"`
 }
 
 type NatsConfig struct {
-	Url        string `mapstructure:"NATS_URL" validate:"required"`
-	StreamName string `mapstructure:"NATS_STREAM_NAME" validate:"required"`
+	Url        string `mapstructure:"url" validate:"required"`
+	StreamName string `mapstructure:"stream_name" validate:"required"`
+	// Name identifies this client in the NATS server's connection list.
+	Name                  string `mapstructure:"name"`
+	ConnectTimeoutSeconds int64  `mapstructure:"connect_timeout" validate:"required,gt=0"`
+	ReconnectWaitSeconds  int64  `mapstructure:"reconnect_wait" validate:"required,gt=0"`
+	MaxReconnects         int    `mapstructure:"max_reconnects" validate:"gte=-1"` // -1 retries forever
+}
+
+type SchedulerConfig struct {
+	TransferIntervalSeconds int64 `mapstructure:"interval" validate:"required,gt=0"`
+}
+
+type EventConfig struct {
+	// LegacyPayload keeps publishing the flat stock.available payload
+	// alongside the versioned envelope during the compatibility window.
+	LegacyPayload bool `mapstructure:"legacy_payload"`
+	// CloudEventsMode is "binary" (ce-* headers) or "structured" (JSON body).
+	CloudEventsMode string `mapstructure:"cloudevents_mode" validate:"oneof=binary structured"`
+	Source          string `mapstructure:"source" validate:"required"`
+}
+
+type WebhookConfig struct {
+	DispatchIntervalSeconds int64 `mapstructure:"dispatch_interval" validate:"required,gt=0"`
+	TimeoutSeconds          int64 `mapstructure:"timeout" validate:"required,gt=0"`
+	MaxAttempts             int64 `mapstructure:"max_attempts" validate:"required,gt=0"`
+	BackoffBaseSeconds      int64 `mapstructure:"backoff_base" validate:"required,gt=0"`
+}
+
+type StreamConfig struct {
+	HeartbeatSeconds int64 `mapstructure:"heartbeat" validate:"required,gt=0"`
+	// BufferSize is how many recent events are kept for Last-Event-ID resume.
+	BufferSize int `mapstructure:"buffer" validate:"gte=0"`
+}
+
+type OpenAPIConfig struct {
+	// SwaggerUI serves an interactive viewer of /openapi.json at /docs.
+	SwaggerUI bool `mapstructure:"swagger_ui"`
+}
+
+type RbacConfig struct {
+	// DefaultRole applies to users without a `role` claim or a row in the
+	// shop role table.
+	DefaultRole string `mapstructure:"default_role" validate:"oneof=owner inventory_manager picker viewer"`
+}
+
+type AlertConfig struct {
+	// DefaultLowThreshold applies to products whose shop has no threshold.
+	DefaultLowThreshold int64 `mapstructure:"default_low_threshold" validate:"gte=0" reload:"true"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// PublicKey picks the bucket for JWT callers, "shop" or "user". Internal
+	// and admin callers are limited per API key.
+	PublicKey         string  `mapstructure:"public_key" validate:"oneof=shop user"`
+	PublicRate        float64 `mapstructure:"public_rps" validate:"gt=0" reload:"true"`
+	PublicBurst       int     `mapstructure:"public_burst" validate:"gte=1" reload:"true"`
+	PublicConcurrency int     `mapstructure:"public_concurrency" validate:"gte=0" reload:"true"` // 0 is unlimited
+	// Internal limits apply to the internal and admin routes and to gRPC.
+	InternalRate        float64 `mapstructure:"internal_rps" validate:"gt=0" reload:"true"`
+	InternalBurst       int     `mapstructure:"internal_burst" validate:"gte=1" reload:"true"`
+	InternalConcurrency int     `mapstructure:"internal_concurrency" validate:"gte=0" reload:"true"` // 0 is unlimited
+}
+
+type MetricsConfig struct {
+	// Enabled serves Prometheus metrics on /metrics, without auth. Keep the
+	// path off the public ingress.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+type TracingConfig struct {
+	// Exporter is "none" (trace IDs in logs only), "stdout" or "otlp".
+	Exporter     string  `mapstructure:"exporter" validate:"oneof=none stdout otlp"`
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint" validate:"required_if=Exporter otlp"`
+	OTLPInsecure bool    `mapstructure:"otlp_insecure"`
+	SampleRatio  float64 `mapstructure:"sample_ratio" validate:"gte=0,lte=1"`
+}
+
+type HealthConfig struct {
+	// CheckTimeoutSeconds bounds each dependency check of /ready and /health.
+	CheckTimeoutSeconds int64 `mapstructure:"check_timeout" validate:"required,gt=0"`
+	// CacheTTLSeconds is how long a check result is reused across probes.
+	CacheTTLSeconds int64 `mapstructure:"cache_ttl" validate:"gte=0"`
 }
 
+type ShutdownConfig struct {
+	// TimeoutSeconds bounds the whole shutdown: in-flight requests, workers,
+	// the NATS drain and closing the DB pool.
+	TimeoutSeconds int64 `mapstructure:"timeout" validate:"required,gt=0"`
+}
+
+type ReconciliationConfig struct {
+	// IntervalSeconds between invariant checks in the server, 0 disables them.
+	IntervalSeconds int64 `mapstructure:"interval" validate:"gte=0"`
+	// AutoRepair lets the periodic check create missing empty stock rows.
+	AutoRepair bool `mapstructure:"auto_repair"`
+}
+
+// defaults for optional settings, by config key
+var defaults = map[string]any{
+	"grpc_port":                       "9090",
+	"log.level":                       "info",
+	"http.read_timeout":               15,
+	"http.write_timeout":              0,
+	"http.idle_timeout":               60,
+	"http.body_limit":                 4 * 1024 * 1024,
+	"db.max_open_conns":               10,
+	"db.max_idle_conns":               5,
+	"db.conn_max_lifetime":            1800,
+	"nats.name":                       "warehouse-service",
+	"nats.connect_timeout":            2,
+	"nats.reconnect_wait":             2,
+	"nats.max_reconnects":             60,
+	"transfer_scheduler.interval":     60,
+	"event.legacy_payload":            true,
+	"event.cloudevents_mode":          "binary",
+	"event.source":                    "/warehouse-service",
+	"webhook.dispatch_interval":       5,
+	"webhook.timeout":                 10,
+	"webhook.max_attempts":            8,
+	"webhook.backoff_base":            30,
+	"stock_stream.heartbeat":          15,
+	"stock_stream.buffer":             1000,
+	"openapi.swagger_ui":              false,
+	"rbac.default_role":               "viewer",
+	"alert.default_low_threshold":     0,
+	"jwt.jwks_refresh":                300,
+	"jwt.leeway":                      30,
+	"jwt.require_exp":                 false,
+	"rate_limit.enabled":              true,
+	"rate_limit.public_key":           "shop",
+	"rate_limit.public_rps":           10,
+	"rate_limit.public_burst":         20,
+	"rate_limit.public_concurrency":   4,
+	"rate_limit.internal_rps":         50,
+	"rate_limit.internal_burst":       100,
+	"rate_limit.internal_concurrency": 6,
+	"metrics.enabled":                 true,
+	"tracing.exporter":                "none",
+	"tracing.otlp_endpoint":           "localhost:4317",
+	"tracing.otlp_insecure":           true,
+	"tracing.sample_ratio":            1.0,
+	"health.check_timeout":            2,
+	"health.cache_ttl":                5,
+	"shutdown.timeout":                30,
+	"reconciliation.interval":         3600,
+	"reconciliation.auto_repair":      false,
+}
+
+// InitConfig loads the config, logs it with secrets redacted and validates
+// it.
 func InitConfig(ctx context.Context) (*Config, error) {
-	var cfg Config
+	cfg, err := Load(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[InitConfig] Load", "error", err)
+		return nil, err
+	}
 
-	// Reset viper to avoid any previous configuration
-	viper.Reset()
+	slog.InfoContext(ctx, "[InitConfig] Configuration after binding", "config", cfg.Redacted())
 
-	// Make viper case insensitive for environment variables
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	if err := cfg.Validate(); err != nil {
+		for _, fieldErr := range unjoin(err) {
+			slog.ErrorContext(ctx, "[InitConfig] Validation error", "error", fieldErr)
+		}
+		return nil, err
+	}
 
-	// Set the configuration type
-	viper.SetConfigType("env")
+	slog.InfoContext(ctx, "[InitConfig] Config loaded successfully")
+	return cfg, nil
+}
 
-	// Try to load from .env file if it exists
-	envFile := os.Getenv("ENV_FILE")
-	if envFile == "" {
-		envFile = ".env"
+// Load reads the defaults, the config file named by CONFIG_FILE (YAML, TOML or
+// JSON, nested by section), the .env file and the environment, each
+// overriding the previous one. Env names are the config keys upper-cased with
+// dots as underscores, so db.max_open_conns is DB_MAX_OPEN_CONNS. The result
+// is not validated.
+func Load(ctx context.Context) (*Config, error) {
+	v := viper.New()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	// Keys only found in the environment are ignored by Unmarshal unless bound
+	for _, key := range keys(reflect.TypeOf(Config{}), "", nil) {
+		if err := v.BindEnv(key); err != nil {
+			return nil, fmt.Errorf("bind %s: %w", key, err)
+		}
 	}
 
-	_, err := os.Stat(envFile)
-	if !os.IsNotExist(err) {
-		viper.SetConfigFile(envFile)
+	loadDotEnv(ctx)
 
-		if err := viper.ReadInConfig(); err != nil {
-			slog.WarnContext(ctx, "[InitConfig] ReadInConfig warning, continuing with env vars only", "error", err)
-			// Continue with just environment variables instead of returning error
-		} else {
-			slog.InfoContext(ctx, "[InitConfig] Successfully loaded config file", "file", envFile)
+	if configFile := os.Getenv("CONFIG_FILE"); configFile != "" {
+		v.SetConfigFile(configFile)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read config file %s: %w", configFile, err)
 		}
-	} else {
-		slog.InfoContext(ctx, "[InitConfig] No config file found, using environment variables")
+		slog.InfoContext(ctx, "[InitConfig] Successfully loaded config file", "file", configFile)
 	}
 
-	// Load environment variables
-	viper.AutomaticEnv()
-
-	// Debug: Print environment variables we're looking for
-	envVars := []string{
-		"PORT",
-		"DB_HOST",
-		"DB_PORT",
-		"DB_USERNAME",
-		"DB_PASSWORD",
-		"DB_DBNAME",
-		"DB_SSLMODE",
-		"JWT_SECRETKEY",
-		"JWT_EXPIRE",
-		"INTERNAL_AUTH_HEADER",
-		"NATS_URL",
-		"NATS_STREAM_NAME",
-		"WAREHOUSE_ADMIN_AUTH_HEADER",
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
 	}
+	return &cfg, nil
+}
 
-	slog.InfoContext(ctx, "[InitConfig] Environment variables debug:")
+// dotEnvSet holds the env vars that came from the .env file, so a reload
+// picks up edits to them while real env vars keep precedence.
+var dotEnvSet = map[string]bool{}
 
-	// Bind environment variables explicitly to ensure they're mapped correctly
-	for _, key := range envVars {
-		viper.BindEnv(key)
+// loadDotEnv copies the .env file (or ENV_FILE) into the environment without
+// overriding variables that are already set.
+func loadDotEnv(ctx context.Context) {
+	envFile := os.Getenv("ENV_FILE")
+	if envFile == "" {
+		envFile = ".env"
 	}
 
-	// Unmarshal configuration
-	if err := viper.Unmarshal(&cfg); err != nil {
-		slog.ErrorContext(ctx, "[InitConfig] Unmarshal", "failed bind config", err)
-		return nil, err
+	if _, err := os.Stat(envFile); os.IsNotExist(err) {
+		slog.InfoContext(ctx, "[InitConfig] No env file found, using environment variables", "file", envFile)
+		return
+	}
+
+	dotEnv := viper.New()
+	dotEnv.SetConfigFile(envFile)
+	dotEnv.SetConfigType("env")
+	if err := dotEnv.ReadInConfig(); err != nil {
+		slog.WarnContext(ctx, "[InitConfig] ReadInConfig warning, continuing with env vars only", "error", err)
+		return
 	}
 
-	// Log the entire configuration after binding
-	slog.InfoContext(ctx, "[InitConfig] Configuration after binding",
-		"PORT", cfg.Port,
-		"DB_HOST", cfg.Db.Host,
-		"DB_PORT", cfg.Db.Port,
-		"DB_USERNAME", cfg.Db.Username,
-		"DB_DBNAME", cfg.Db.DbName,
-		"DB_SSLMODE", cfg.Db.SSLMode,
-		"JWT_EXPIRE", cfg.Jwt.Expire,
-		"NATS_URL", cfg.Nats.Url,
-		"NATS_STREAM_NAME", cfg.Nats.StreamName,
-	)
-
-	// Validate configuration
+	for _, key := range dotEnv.AllKeys() {
+		name := strings.ToUpper(key)
+		if _, ok := os.LookupEnv(name); !ok || dotEnvSet[name] {
+			os.Setenv(name, dotEnv.GetString(key))
+			dotEnvSet[name] = true
+		}
+	}
+	slog.InfoContext(ctx, "[InitConfig] Successfully loaded env file", "file", envFile)
+}
+
+// Validate reports every invalid field by config key and env name.
+func (c *Config) Validate() error {
 	validate := validator.New()
-	if err := validate.Struct(cfg); err != nil {
-		validationErrs, ok := err.(validator.ValidationErrors)
-		if ok {
-			for _, validationErr := range validationErrs {
-				slog.ErrorContext(ctx, "[InitConfig] Validation error",
-					"field", validationErr.Field(),
-					"namespace", validationErr.Namespace(),
-					"tag", validationErr.Tag(),
-					"value", validationErr.Value())
-			}
-		} else {
-			slog.ErrorContext(ctx, "[InitConfig] Validation", "error", err)
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("mapstructure")
+	})
+
+	err := validate.Struct(c)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	errs := make([]error, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		key := strings.TrimPrefix(fieldErr.Namespace(), "Config.")
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule += "=" + fieldErr.Param()
 		}
-		return nil, err
+		errs = append(errs, fmt.Errorf("%s (%s): failed %s", key, envName(key), rule))
 	}
+	return errors.Join(errs...)
+}
 
-	slog.InfoContext(ctx, "[InitConfig] Config loaded successfully")
-	return &cfg, nil
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
 }
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// loadSample loads config.sample.yaml, which only lacks the JWT secret.
+func loadSample(t *testing.T) *Config {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "../config.sample.yaml")
+	t.Setenv("ENV_FILE", "../.no-env-file")
+	t.Setenv("JWT_SECRETKEY", "secret")
+
+	cfg, err := Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("sample config: %v", err)
+	}
+	return cfg
+}
+
+func TestValidateRateLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		key    string
+	}{
+		{"zero public rate", func(cfg *Config) { cfg.RateLimit.PublicRate = 0 }, "rate_limit.public_rps"},
+		{"negative internal rate", func(cfg *Config) { cfg.RateLimit.InternalRate = -1 }, "rate_limit.internal_rps"},
+		{"zero public burst", func(cfg *Config) { cfg.RateLimit.PublicBurst = 0 }, "rate_limit.public_burst"},
+		{"zero internal burst", func(cfg *Config) { cfg.RateLimit.InternalBurst = 0 }, "rate_limit.internal_burst"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadSample(t)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.key) {
+				t.Fatalf("Validate() = %v, want an error for %s", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestReloadRejectsInvalidRateLimit(t *testing.T) {
+	cfg := loadSample(t)
+	live := NewLive(cfg)
+
+	t.Setenv("RATE_LIMIT_PUBLIC_RPS", "0")
+	if err := live.Reload(context.Background()); err == nil {
+		t.Fatal("Reload accepted a zero rate")
+	}
+	if live.Get().RateLimit.PublicRate != cfg.RateLimit.PublicRate {
+		t.Fatalf("running rate = %v, want %v kept", live.Get().RateLimit.PublicRate, cfg.RateLimit.PublicRate)
+	}
+
+	t.Setenv("RATE_LIMIT_PUBLIC_RPS", "5")
+	t.Setenv("RATE_LIMIT_PUBLIC_BURST", "0")
+	if err := live.Reload(context.Background()); err == nil {
+		t.Fatal("Reload accepted a zero burst")
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Live holds the running config. Reload swaps in the fields tagged
+// `reload:"true"` as one new *Config, so readers calling Get never see half
+// an update; changes to any other field are logged and wait for a restart.
+type Live struct {
+	current    atomic.Pointer[Config]
+	reloadedAt atomic.Pointer[time.Time]
+
+	mu        sync.Mutex
+	listeners []func(cfg *Config)
+}
+
+func NewLive(cfg *Config) *Live {
+	l := &Live{}
+	l.current.Store(cfg)
+	return l
+}
+
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// ReloadedAt is when a reload last changed the config, zero if never.
+func (l *Live) ReloadedAt() time.Time {
+	if t := l.reloadedAt.Load(); t != nil {
+		return *t
+	}
+	return time.Time{}
+}
+
+// OnReload registers fn to run with the new config after every reload that
+// changed it. Components that copy settings at startup, e.g. the log level,
+// apply them here.
+func (l *Live) OnReload(fn func(cfg *Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+// Reload reads the config again. An invalid config is rejected and the
+// running one is kept.
+func (l *Live) Reload(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next, err := Load(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[config] Reload", "load", err)
+		return err
+	}
+
+	merged := *l.current.Load()
+	changed, restart := mergeReloadable(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(next).Elem(), "")
+	for _, key := range restart {
+		slog.WarnContext(ctx, "[config] Reload", "restartRequired", key)
+	}
+	if len(changed) == 0 {
+		slog.InfoContext(ctx, "[config] Reload", "changed", "none")
+		return nil
+	}
+
+	if err := merged.Validate(); err != nil {
+		slog.ErrorContext(ctx, "[config] Reload", "validate", err)
+		return err
+	}
+
+	now := time.Now().UTC()
+	l.current.Store(&merged)
+	l.reloadedAt.Store(&now)
+	for _, fn := range l.listeners {
+		fn(&merged)
+	}
+	slog.InfoContext(ctx, "[config] Reload", "changed", changed)
+	return nil
+}
+
+// Watch reloads whenever CONFIG_FILE changes on disk. The watcher runs for the
+// life of the process.
+func (l *Live) Watch(ctx context.Context) {
+	configFile := os.Getenv("CONFIG_FILE")
+	if configFile == "" {
+		return
+	}
+
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	v.OnConfigChange(func(event fsnotify.Event) {
+		slog.InfoContext(ctx, "[config] Watch", "event", event.String())
+		// Errors are logged by Reload, the running config stays in place
+		_ = l.Reload(ctx)
+	})
+	v.WatchConfig()
+}
+
+// ReloadableKeys lists the config keys Reload applies without a restart.
+func ReloadableKeys() []string {
+	return keys(reflect.TypeOf(Config{}), "", func(field reflect.StructField) bool {
+		return field.Tag.Get("reload") == "true"
+	})
+}
+
+// mergeReloadable copies the reloadable fields of src into dst and returns
+// the keys it changed and the keys that differ but need a restart.
+func mergeReloadable(dst, src reflect.Value, prefix string) (changed, restart []string) {
+	for i := range dst.NumField() {
+		field := dst.Type().Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+
+		if field.Type.Kind() == reflect.Struct {
+			c, r := mergeReloadable(dst.Field(i), src.Field(i), key+".")
+			changed = append(changed, c...)
+			restart = append(restart, r...)
+			continue
+		}
+
+		if reflect.DeepEqual(dst.Field(i).Interface(), src.Field(i).Interface()) {
+			continue
+		}
+		if field.Tag.Get("reload") != "true" {
+			restart = append(restart, key)
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+		changed = append(changed, key)
+	}
+	return changed, restart
+}
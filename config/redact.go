@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+const redactedValue = "******"
+
+// keys lists the config keys of t's fields matching match, e.g. "db.host".
+// A nil match lists all of them.
+func keys(t reflect.Type, prefix string, match func(field reflect.StructField) bool) []string {
+	var out []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+		if field.Type.Kind() == reflect.Struct {
+			out = append(out, keys(field.Type, key+".", match)...)
+			continue
+		}
+		if match == nil || match(field) {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Redacted returns the config nested the way the config file is, with fields
+// tagged `redact:"true"` masked when set. Use it whenever the config is
+// logged or printed.
+func (c *Config) Redacted() map[string]any {
+	return redact(reflect.ValueOf(*c))
+}
+
+func redact(v reflect.Value) map[string]any {
+	out := make(map[string]any, v.NumField())
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			out[key] = redact(v.Field(i))
+		case field.Tag.Get("redact") == "true" && !v.Field(i).IsZero():
+			out[key] = redactedValue
+		default:
+			out[key] = v.Field(i).Interface()
+		}
+	}
+	return out
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS stock_transfer_schedule_runs;
+DROP TABLE IF EXISTS stock_transfer_schedules;
+
+-- The base tables are kept, on older databases they predate migrations/
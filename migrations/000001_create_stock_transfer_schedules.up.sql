@@ -0,0 +1,84 @@
+-- Base schema. It predates migrations/, so existing databases already have
+-- these tables and only fresh ones create them here.
+CREATE TABLE IF NOT EXISTS warehouses (
+    id         BIGSERIAL PRIMARY KEY,
+    shop_id    BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    location   TEXT         NOT NULL DEFAULT '',
+    active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouses_shop_id ON warehouses (shop_id);
+
+CREATE TABLE IF NOT EXISTS stocks (
+    id           BIGSERIAL PRIMARY KEY,
+    product_id   BIGINT      NOT NULL,
+    warehouse_id BIGINT      NOT NULL REFERENCES warehouses (id),
+    quantity     BIGINT      NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stocks_warehouse_id ON stocks (warehouse_id);
+
+CREATE TABLE IF NOT EXISTS reserved_stocks (
+    id         BIGSERIAL PRIMARY KEY,
+    stock_id   BIGINT      NOT NULL REFERENCES stocks (id),
+    quantity   BIGINT      NOT NULL,
+    order_id   BIGINT      NOT NULL,
+    status     VARCHAR(16) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reserved_stocks_stock_id ON reserved_stocks (stock_id, status);
+CREATE INDEX IF NOT EXISTS idx_reserved_stocks_order_id ON reserved_stocks (order_id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id             BIGSERIAL PRIMARY KEY,
+    product_id     BIGINT      NOT NULL,
+    from_warehouse BIGINT      NOT NULL REFERENCES warehouses (id),
+    to_warehouse   BIGINT      NOT NULL REFERENCES warehouses (id),
+    quantity       BIGINT      NOT NULL,
+    status         VARCHAR(16) NOT NULL DEFAULT 'not_started',
+    description    TEXT        NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_from_warehouse ON stock_transfers (from_warehouse);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_schedules (
+    id             BIGSERIAL PRIMARY KEY,
+    shop_id        BIGINT      NOT NULL,
+    product_id     BIGINT      NOT NULL,
+    from_warehouse BIGINT      NOT NULL REFERENCES warehouses (id),
+    to_warehouse   BIGINT      NOT NULL REFERENCES warehouses (id),
+    quantity       BIGINT      NOT NULL DEFAULT 0,
+    top_up_to      BIGINT      NOT NULL DEFAULT 0,
+    recurrence     VARCHAR(16) NOT NULL DEFAULT '',
+    description    TEXT        NOT NULL DEFAULT '',
+    scheduled_at   TIMESTAMPTZ NOT NULL,
+    active         BOOLEAN     NOT NULL DEFAULT TRUE,
+    last_run_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_schedules_due ON stock_transfer_schedules (active, scheduled_at);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_schedules_shop_id ON stock_transfer_schedules (shop_id);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_schedule_runs (
+    id          BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT      NOT NULL REFERENCES stock_transfer_schedules (id),
+    transfer_id BIGINT REFERENCES stock_transfers (id),
+    quantity    BIGINT      NOT NULL DEFAULT 0,
+    status      VARCHAR(16) NOT NULL,
+    message     TEXT        NOT NULL DEFAULT '',
+    run_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_schedule_runs_schedule_id ON stock_transfer_schedule_runs (schedule_id, run_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stock_transfers_open;
+DROP TABLE IF EXISTS replenishment_rules;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS replenishment_rules (
+    id                  BIGSERIAL PRIMARY KEY,
+    shop_id             BIGINT      NOT NULL,
+    product_id          BIGINT      NOT NULL,
+    warehouse_id        BIGINT      NOT NULL REFERENCES warehouses (id),
+    source_warehouse_id BIGINT      NOT NULL REFERENCES warehouses (id),
+    min_level           BIGINT      NOT NULL DEFAULT 0,
+    max_level           BIGINT      NOT NULL,
+    active              BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_replenishment_rules_shop_id ON replenishment_rules (shop_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_open ON stock_transfers (product_id, to_warehouse)
+    WHERE status IN ('not_started', 'in_progress');
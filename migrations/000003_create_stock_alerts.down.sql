@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_alert_states;
+DROP TABLE IF EXISTS stock_alert_thresholds;
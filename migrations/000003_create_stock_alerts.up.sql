@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS stock_alert_thresholds (
+    id            BIGSERIAL PRIMARY KEY,
+    shop_id       BIGINT      NOT NULL,
+    product_id    BIGINT      NOT NULL DEFAULT 0,
+    low_threshold BIGINT      NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (shop_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_alert_states (
+    product_id BIGINT PRIMARY KEY,
+    shop_id    BIGINT      NOT NULL,
+    state      VARCHAR(16) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS product_event_sequences (
+    product_id    BIGINT PRIMARY KEY,
+    last_sequence BIGINT NOT NULL DEFAULT 0
+);
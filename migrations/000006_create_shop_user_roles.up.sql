@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS shop_user_roles (
+    shop_id    BIGINT      NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    role       VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (shop_id, user_id)
+);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id           BIGSERIAL PRIMARY KEY,
+    shop_id      BIGINT      NOT NULL,
+    warehouse_id BIGINT      NOT NULL REFERENCES warehouses (id),
+    status       VARCHAR(16) NOT NULL DEFAULT 'open',
+    note         TEXT        NOT NULL DEFAULT '',
+    opened_by    BIGINT      NOT NULL,
+    submitted_by BIGINT,
+    submitted_at TIMESTAMPTZ,
+    closed_by    BIGINT,
+    closed_at    TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stocktakes_shop_id ON stocktakes (shop_id, id DESC);
+-- one stocktake in progress per warehouse
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stocktakes_warehouse_active ON stocktakes (warehouse_id)
+    WHERE status IN ('open', 'submitted');
+
+CREATE TABLE IF NOT EXISTS stocktake_lines (
+    id               BIGSERIAL PRIMARY KEY,
+    stocktake_id     BIGINT      NOT NULL REFERENCES stocktakes (id) ON DELETE CASCADE,
+    stock_id         BIGINT      NOT NULL REFERENCES stocks (id),
+    product_id       BIGINT      NOT NULL,
+    system_quantity  BIGINT      NOT NULL,
+    counted_quantity BIGINT,
+    counted_by       BIGINT,
+    counted_at       TIMESTAMPTZ,
+    applied_quantity BIGINT,
+    UNIQUE (stocktake_id, product_id)
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id              BIGSERIAL PRIMARY KEY,
+    shop_id         BIGINT      NOT NULL,
+    stock_id        BIGINT      NOT NULL REFERENCES stocks (id),
+    product_id      BIGINT      NOT NULL,
+    warehouse_id    BIGINT      NOT NULL,
+    delta           BIGINT      NOT NULL,
+    quantity_before BIGINT      NOT NULL,
+    quantity_after  BIGINT      NOT NULL,
+    reason          VARCHAR(16) NOT NULL,
+    note            TEXT        NOT NULL DEFAULT '',
+    -- NULL for adjustments made from the command line
+    user_id         BIGINT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_shop_id ON stock_adjustments (shop_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_stock_id ON stock_adjustments (stock_id, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE stock_transfer_schedules DROP COLUMN IF EXISTS day_of_month;
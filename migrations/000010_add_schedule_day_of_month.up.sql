@@ -0,0 +1,5 @@
+-- day of month a monthly schedule runs on, kept when a shorter month moves a run earlier
+ALTER TABLE stock_transfer_schedules ADD COLUMN IF NOT EXISTS day_of_month SMALLINT NOT NULL DEFAULT 0;
+
+UPDATE stock_transfer_schedules SET day_of_month = EXTRACT(DAY FROM scheduled_at AT TIME ZONE 'UTC')
+WHERE recurrence = 'monthly' AND day_of_month = 0;
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations so the binary can apply them
+// with `main migrate`.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,45 @@
+package ctxutil
+
+import (
+	"context"
+	"sync"
+)
+
+type afterCommitKey struct{}
+
+type afterCommitQueue struct {
+	mu  sync.Mutex
+	fns []func(context.Context)
+}
+
+// WithAfterCommit returns the context to run a transaction with and a
+// function to call once it has committed. It runs the callbacks registered
+// with AfterCommit on that context; a rolled back transaction just never
+// calls it.
+func WithAfterCommit(ctx context.Context) (context.Context, func(context.Context)) {
+	queue := &afterCommitQueue{}
+	return context.WithValue(ctx, afterCommitKey{}, queue), func(ctx context.Context) {
+		queue.mu.Lock()
+		fns := queue.fns
+		queue.fns = nil
+		queue.mu.Unlock()
+
+		for _, fn := range fns {
+			fn(ctx)
+		}
+	}
+}
+
+// AfterCommit defers fn until the transaction of ctx commits, or runs it
+// right away when ctx is not inside a transaction.
+func AfterCommit(ctx context.Context, fn func(context.Context)) {
+	queue, ok := ctx.Value(afterCommitKey{}).(*afterCommitQueue)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	queue.mu.Lock()
+	queue.fns = append(queue.fns, fn)
+	queue.mu.Unlock()
+}
@@ -11,6 +11,10 @@ const (
 	RequestIDKey ctxKey = "request_id"
 	UserIDKey    ctxKey = "user_id"
 	ShopIDKey    ctxKey = "shop_id"
+	RoleKey      ctxKey = "role"
+	// API key callers on the internal and admin routes
+	ApiKeyIDKey     ctxKey = "api_key_id"
+	ApiKeyScopesKey ctxKey = "api_key_scopes"
 )
 
 func WithRequestID(ctx context.Context, reqID string) context.Context {
@@ -43,3 +47,21 @@ func GetShopIDCtx(ctx context.Context) (int64, error) {
 	}
 	return 0, errors.New("shop ID not found")
 }
+
+func GetRoleCtx(ctx context.Context) (string, error) {
+	if v := ctx.Value(RoleKey); v != nil {
+		if role, ok := v.(string); ok {
+			return role, nil
+		}
+	}
+	return "", errors.New("role not found")
+}
+
+func GetApiKeyIDCtx(ctx context.Context) (int64, error) {
+	if v := ctx.Value(ApiKeyIDKey); v != nil {
+		if id, ok := v.(int64); ok {
+			return id, nil
+		}
+	}
+	return 0, errors.New("api key ID not found")
+}
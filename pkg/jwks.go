@@ -0,0 +1,253 @@
+package pkg
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksMinRefreshInterval limits refresh attempts, failed ones included, so a
+// flood of tokens with made-up kids or an unreachable JWKS endpoint cannot
+// hammer it.
+const jwksMinRefreshInterval = 30 * time.Second
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// JWKS is a cached JSON Web Key Set loaded from a file path or an http(s)
+// URL. Keys are refreshed after refreshInterval, and early when a token names
+// a kid that is not cached yet (key rotation).
+type JWKS struct {
+	source          string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	// refreshMu lets one caller refresh at a time, the others wait and use
+	// its result instead of fetching again.
+	refreshMu sync.Mutex
+
+	mu          sync.RWMutex
+	keys        map[string]jwksKey
+	refreshedAt time.Time // last successful refresh
+	attemptedAt time.Time // last refresh, successful or not
+}
+
+func NewJWKS(ctx context.Context, source string, refreshInterval time.Duration) (*JWKS, error) {
+	jwks := &JWKS{
+		source:          source,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := jwks.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+// Key returns the public key for kid. An empty kid is accepted only when the
+// set holds exactly one key.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, string, error) {
+	j.mu.RLock()
+	key, ok := j.lookup(kid)
+	due := j.refreshDue(ok)
+	j.mu.RUnlock()
+
+	if due {
+		if err := j.refreshIfDue(ctx, kid); err != nil {
+			// Keep serving cached keys if the JWKS source is briefly unavailable.
+			slog.WarnContext(ctx, "[JWKS] Key", "refresh", err)
+		}
+		j.mu.RLock()
+		key, ok = j.lookup(kid)
+		j.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, "", fmt.Errorf("unknown key id %q", kid)
+	}
+	return key.key, key.alg, nil
+}
+
+func (j *JWKS) lookup(kid string) (jwksKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// refreshDue reports whether the cache is stale, or kid was not found, and
+// the last attempt is old enough to try again. Callers hold mu.
+func (j *JWKS) refreshDue(found bool) bool {
+	stale := time.Since(j.refreshedAt) > j.refreshInterval
+	return (stale || !found) && time.Since(j.attemptedAt) > jwksMinRefreshInterval
+}
+
+// refreshIfDue refreshes unless another caller did while this one waited.
+func (j *JWKS) refreshIfDue(ctx context.Context, kid string) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	j.mu.RLock()
+	_, ok := j.lookup(kid)
+	due := j.refreshDue(ok)
+	j.mu.RUnlock()
+
+	if !due {
+		return nil
+	}
+	return j.refresh(ctx)
+}
+
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = time.Now()
+	j.mu.Unlock()
+
+	body, err := j.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]jwksKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "[JWKS] refresh", "kid", k.Kid, "skip", err)
+			continue
+		}
+		keys[k.Kid] = jwksKey{alg: k.Alg, key: key}
+	}
+	if len(keys) == 0 {
+		return errors.New("jwks has no usable signing keys")
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.refreshedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWKSRefreshDuringOutage(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fetches atomic.Int64
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if down.Load() {
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"keys":[{"kid":"k1","kty":"OKP","crv":"Ed25519","alg":"EdDSA","x":%q}]}`,
+			base64.RawURLEncoding.EncodeToString(pub))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	jwks, err := NewJWKS(ctx, server.URL, time.Hour)
+	if err != nil {
+		t.Fatalf("NewJWKS: %v", err)
+	}
+
+	// Pretend the last refresh was long ago and the source went down
+	down.Store(true)
+	fetches.Store(0)
+	jwks.mu.Lock()
+	jwks.refreshedAt = time.Now().Add(-2 * time.Hour)
+	jwks.attemptedAt = jwks.refreshedAt
+	jwks.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := jwks.Key(ctx, "k1"); err != nil {
+				t.Errorf("cached key: %v", err)
+			}
+			if _, _, err := jwks.Key(ctx, "unknown"); err == nil {
+				t.Error("unknown kid resolved")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("fetches during outage = %d, want 1", got)
+	}
+
+	if _, _, err := jwks.Key(ctx, "unknown"); err == nil {
+		t.Fatal("unknown kid resolved")
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("fetches after a failed attempt = %d, want 1 within the minimum interval", got)
+	}
+}
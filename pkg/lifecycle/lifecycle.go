@@ -0,0 +1,140 @@
+// Package lifecycle coordinates shutdown: stop steps run in order under one
+// deadline, and whatever is still running when it passes is reported as
+// abandoned.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// forceCloseGrace is how long a step may take to return after the deadline
+// before it is reported as abandoned.
+const forceCloseGrace = time.Second
+
+type step struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+type worker struct {
+	name   string
+	cancel context.CancelFunc
+	abort  context.CancelFunc
+	done   chan struct{}
+}
+
+type workContextKey struct{}
+
+type Manager struct {
+	mu      sync.Mutex
+	steps   []step
+	workers []worker
+}
+
+func New() *Manager {
+	return &Manager{}
+}
+
+// OnStop registers a shutdown step. Steps run one after another in the order
+// they were registered.
+func (m *Manager) OnStop(name string, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, step{name: name, stop: stop})
+}
+
+// Go runs a background worker until StopWorkers cancels its context. Work
+// already started should run on WorkContext(ctx) instead, which StopWorkers
+// only cancels once the shutdown deadline has passed.
+func (m *Manager) Go(ctx context.Context, name string, run func(ctx context.Context)) {
+	workCtx, abort := context.WithCancel(ctx)
+	ctx, cancel := context.WithCancel(context.WithValue(workCtx, workContextKey{}, workCtx))
+	w := worker{name: name, cancel: cancel, abort: abort, done: make(chan struct{})}
+
+	m.mu.Lock()
+	m.workers = append(m.workers, w)
+	m.mu.Unlock()
+
+	go func() {
+		defer close(w.done)
+		run(ctx)
+	}()
+}
+
+// WorkContext returns the context a worker started with Go finishes its
+// current work on: it outlives the stop signal but not the shutdown deadline.
+// Outside a worker it returns ctx.
+func WorkContext(ctx context.Context) context.Context {
+	if workCtx, ok := ctx.Value(workContextKey{}).(context.Context); ok {
+		return workCtx
+	}
+	return ctx
+}
+
+// StopWorkers cancels the workers in start order, waiting for each to return
+// before stopping the next. Once ctx is done the remaining workers' work
+// contexts are cancelled too. It is meant to be registered as a stop step.
+func (m *Manager) StopWorkers(ctx context.Context) error {
+	m.mu.Lock()
+	workers := m.workers
+	m.mu.Unlock()
+
+	var abandoned []string
+	for _, w := range workers {
+		w.cancel()
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			w.abort()
+			abandoned = append(abandoned, w.name)
+		}
+	}
+	if len(abandoned) > 0 {
+		return fmt.Errorf("workers still running: %s", strings.Join(abandoned, ", "))
+	}
+	return nil
+}
+
+// Shutdown runs the stop steps within ctx's deadline and returns the steps
+// that failed or had not finished in time. Once the deadline has passed the
+// remaining steps are still started, so they can force-close, but not
+// waited for beyond forceCloseGrace.
+func (m *Manager) Shutdown(ctx context.Context) []string {
+	m.mu.Lock()
+	steps := m.steps
+	m.mu.Unlock()
+
+	var abandoned []string
+	for _, s := range steps {
+		start := time.Now()
+		done := make(chan error, 1)
+		go func() {
+			done <- s.stop(ctx)
+		}()
+
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			// Steps force-close once ctx is done, give them a moment to do so
+			select {
+			case err = <-done:
+			case <-time.After(forceCloseGrace):
+				err = fmt.Errorf("abandoned: %w", ctx.Err())
+			}
+		}
+
+		if err != nil {
+			slog.WarnContext(ctx, "[lifecycle] Shutdown", "step", s.name, "error", err)
+			abandoned = append(abandoned, fmt.Sprintf("%s: %v", s.name, err))
+			continue
+		}
+		slog.InfoContext(ctx, "[lifecycle] Shutdown", "step", s.name, "took", time.Since(start).String())
+	}
+	return abandoned
+}
@@ -0,0 +1,56 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStopWorkersCancelsWorkAtDeadline(t *testing.T) {
+	m := New()
+	workErr := make(chan error, 1)
+	m.Go(context.Background(), "worker", func(ctx context.Context) {
+		<-ctx.Done()
+		// a pass still running after the stop signal
+		work := WorkContext(ctx)
+		select {
+		case <-work.Done():
+			workErr <- work.Err()
+		case <-time.After(5 * time.Second):
+			workErr <- nil
+		}
+	})
+	m.OnStop("workers", m.StopWorkers)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if abandoned := m.Shutdown(ctx); len(abandoned) != 1 {
+		t.Fatalf("abandoned = %v, want the worker", abandoned)
+	}
+
+	select {
+	case err := <-workErr:
+		if err == nil {
+			t.Fatal("work context outlived the shutdown deadline")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("worker did not finish after its work context was cancelled")
+	}
+}
+
+func TestWorkContextOutlivesStopSignal(t *testing.T) {
+	m := New()
+	finished := make(chan struct{})
+	m.Go(context.Background(), "worker", func(ctx context.Context) {
+		<-ctx.Done()
+		if err := WorkContext(ctx).Err(); err != nil {
+			t.Errorf("work context cancelled with the stop signal: %v", err)
+		}
+		close(finished)
+	})
+
+	if err := m.StopWorkers(context.Background()); err != nil {
+		t.Fatalf("StopWorkers: %v", err)
+	}
+	<-finished
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"warehouse-service/pkg/ctxutil"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RequestIDHandler struct {
@@ -15,5 +17,8 @@ func (h *RequestIDHandler) Handle(ctx context.Context, r slog.Record) error {
 	if requestID != "" {
 		r.AddAttrs(slog.String("request_id", requestID))
 	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		r.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
 	return h.Handler.Handle(ctx, r)
 }
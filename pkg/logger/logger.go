@@ -5,9 +5,23 @@ import (
 	"os"
 )
 
+// Level is shared by the app and request loggers so SetLevel applies to both
+// at runtime.
+var Level = new(slog.LevelVar)
+
 func InitLogger() {
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: Level,
 	})
 	slog.SetDefault(slog.New(&RequestIDHandler{Handler: handler}))
 }
+
+// SetLevel takes debug, info, warn or error.
+func SetLevel(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	Level.Set(l)
+	return nil
+}
@@ -0,0 +1,88 @@
+// Package metrics holds the Prometheus collectors served on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "warehouse"
+
+// Registry is used instead of the global default registry, so only the
+// collectors below (plus Go and process stats) are exposed.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	NatsPublishTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nats_publish_total",
+		Help:      "Messages published to NATS by subject and result (success, failure).",
+	}, []string{"subject", "result"})
+
+	ReservationsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_total",
+		Help:      "Stock reservations by result (created, failed) and failure reason.",
+	}, []string{"result", "reason"})
+
+	TransfersTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Stock transfer status transitions; from is empty for newly created transfers.",
+	}, []string{"from", "to"})
+
+	StockAdjustmentsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stock_adjustments_total",
+		Help:      "Manual stock quantity adjustments per shop and reason.",
+	}, []string{"shop_id", "reason"})
+
+	ReconciliationViolations = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_violations",
+		Help:      "Stock invariant violations found by the last reconciliation run, by invariant.",
+	}, []string{"invariant"})
+
+	ReconciliationRepairsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_repairs_total",
+		Help:      "Violations fixed by reconciliation auto-repair, by invariant.",
+	}, []string{"invariant"})
+
+	ReconciliationLastRunTimestamp = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_last_run_timestamp_seconds",
+		Help:      "Unix time of the last completed reconciliation run.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDB exposes the connection pool stats of db (open, in use, idle,
+// wait count and duration, ...).
+func RegisterDB(db *sql.DB, dbName string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,157 @@
+// Package ratelimit implements token-bucket rate limits and in-flight
+// request caps per client key.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate per
+// second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Validate rejects limits a bucket can't refill or never holds a token in.
+func (l Limit) Validate() error {
+	if l.Rate <= 0 || l.Burst < 1 {
+		return fmt.Errorf("%w: rate %v, burst %d", ErrInvalidLimit, l.Rate, l.Burst)
+	}
+	return nil
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is when the bucket is full again.
+	ResetAfter time.Duration
+	// RetryAfter is when the next request is allowed, zero when allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. MemoryStore suits a single instance; a shared
+// store (e.g. Redis) is needed once the service runs with several replicas.
+// Take fails with ErrInvalidLimit when the limit does not validate.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore returns an in-process store. Refilled buckets are dropped
+// every cleanupInterval until ctx is done.
+func NewMemoryStore(ctx context.Context, cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+	go s.cleanup(ctx, cleanupInterval)
+	return s
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+
+	// Refill for the time since the last request
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return result, nil
+}
+
+func (s *MemoryStore) cleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			for key, b := range s.buckets {
+				// A full bucket is the same as no bucket
+				if b.tokens+s.now().Sub(b.updated).Seconds()*b.limit.Rate >= float64(b.limit.Burst) {
+					delete(s.buckets, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Concurrency caps how many requests one key may have in flight.
+type Concurrency struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+func NewConcurrency() *Concurrency {
+	return &Concurrency{inFlight: make(map[string]int)}
+}
+
+// Acquire reserves a slot for key. Callers must Release when Acquire returns
+// true.
+func (c *Concurrency) Acquire(key string, max int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inFlight[key] >= max {
+		return false
+	}
+	c.inFlight[key]++
+	return true
+}
+
+func (c *Concurrency) Release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inFlight[key] <= 1 {
+		delete(c.inFlight, key)
+		return
+	}
+	c.inFlight[key]--
+}
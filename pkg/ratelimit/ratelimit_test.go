@@ -0,0 +1,25 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTakeRejectsInvalidLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := NewMemoryStore(ctx, time.Minute)
+
+	for _, limit := range []Limit{{Rate: 0, Burst: 5}, {Rate: -1, Burst: 5}, {Rate: 1, Burst: 0}} {
+		if _, err := store.Take(ctx, "key", limit); !errors.Is(err, ErrInvalidLimit) {
+			t.Fatalf("Take(%+v) error = %v, want ErrInvalidLimit", limit, err)
+		}
+	}
+
+	result, err := store.Take(ctx, "key", Limit{Rate: 1, Burst: 1})
+	if err != nil || !result.Allowed {
+		t.Fatalf("Take with a valid limit = %+v, %v", result, err)
+	}
+}
@@ -1,24 +1,81 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type TokenClaims struct {
-	UID int64  `json:"uid"`
-	SID *int64 `json:"sid"`
+	UID  int64  `json:"uid"`
+	SID  *int64 `json:"sid"`
+	Role string `json:"role"` // optional, falls back to the shop role table
 }
 
-func ParseJwtToken(tokenString string, secretKey string) (TokenClaims, error) {
-	// Parse the token
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		// Check the signing method
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+type JwtVerifierConfig struct {
+	// SecretKey enables HS256/HS384/HS512 tokens. Leave empty to accept
+	// asymmetric tokens only.
+	SecretKey string
+	// JWKS enables RS*, PS*, ES* and EdDSA tokens, selected by `kid`.
+	JWKS     *JWKS
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+	// RequireExpiration rejects tokens without an `exp` claim.
+	RequireExpiration bool
+}
+
+type JwtVerifier struct {
+	cfg    JwtVerifierConfig
+	parser *jwt.Parser
+}
+
+var (
+	hmacMethods       = []string{"HS256", "HS384", "HS512"}
+	asymmetricMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+)
+
+func NewJwtVerifier(cfg JwtVerifierConfig) *JwtVerifier {
+	var methods []string
+	if cfg.SecretKey != "" {
+		methods = append(methods, hmacMethods...)
+	}
+	if cfg.JWKS != nil {
+		methods = append(methods, asymmetricMethods...)
+	}
+
+	// exp and nbf are always checked when present
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithLeeway(cfg.Leeway)}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	if cfg.RequireExpiration {
+		options = append(options, jwt.WithExpirationRequired())
+	}
+
+	return &JwtVerifier{cfg: cfg, parser: jwt.NewParser(options...)}
+}
+
+func (v *JwtVerifier) Parse(ctx context.Context, tokenString string) (TokenClaims, error) {
+	token, err := v.parser.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			return []byte(v.cfg.SecretKey), nil
 		}
-		return []byte(secretKey), nil
+
+		kid, _ := t.Header["kid"].(string)
+		key, alg, err := v.cfg.JWKS.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if alg != "" && alg != t.Method.Alg() {
+			return nil, fmt.Errorf("key %q is for %s, token uses %s", kid, alg, t.Method.Alg())
+		}
+		return key, nil
 	})
 	if err != nil {
 		return TokenClaims{}, err
@@ -34,6 +91,9 @@ func ParseJwtToken(tokenString string, secretKey string) (TokenClaims, error) {
 			tokenClaims.SID = new(int64)
 			*tokenClaims.SID = int64(shopID)
 		}
+		if role, ok := claims["role"].(string); ok {
+			tokenClaims.Role = role
+		}
 		return tokenClaims, nil
 	}
 
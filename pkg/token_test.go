@@ -0,0 +1,203 @@
+package pkg
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type testKeys struct {
+	rsa      *rsa.PrivateKey
+	ec       *ecdsa.PrivateKey
+	ed       ed25519.PrivateKey
+	verifier *JwtVerifier
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// newTestKeys writes a JWKS file with one key per type. "rsa-any" has no
+// alg, so only the key type limits what it verifies.
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaJWK := func(kid, alg string) map[string]string {
+		return map[string]string{"kid": kid, "kty": "RSA", "alg": alg,
+			"n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())}
+	}
+	set := map[string]any{"keys": []map[string]string{
+		rsaJWK("rsa", "RS256"),
+		rsaJWK("rsa-any", ""),
+		{"kid": "ec", "kty": "EC", "alg": "ES256", "crv": "P-256",
+			"x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kid": "ed", "kty": "OKP", "alg": "EdDSA", "crv": "Ed25519", "x": b64(edPub)},
+	}}
+	body, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := NewJWKS(context.Background(), path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewJWKS: %v", err)
+	}
+	return testKeys{rsaKey, ecKey, edKey, NewJwtVerifier(JwtVerifierConfig{JWKS: jwks})}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign %s: %v", method.Alg(), err)
+	}
+	return signed
+}
+
+func TestJwtVerifierKeys(t *testing.T) {
+	keys := newTestKeys(t)
+	claims := jwt.MapClaims{"uid": 1, "sid": 2, "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"RS256", sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims), false},
+		{"ES256", sign(t, jwt.SigningMethodES256, "ec", keys.ec, claims), false},
+		{"EdDSA", sign(t, jwt.SigningMethodEdDSA, "ed", keys.ed, claims), false},
+		{"RS256 with a key without alg", sign(t, jwt.SigningMethodRS256, "rsa-any", keys.rsa, claims), false},
+		{"PS256 with a key without alg", sign(t, jwt.SigningMethodPS256, "rsa-any", keys.rsa, claims), false},
+		{"unknown kid", sign(t, jwt.SigningMethodRS256, "rotated", keys.rsa, claims), true},
+		{"missing kid with several keys", sign(t, jwt.SigningMethodRS256, "", keys.rsa, claims), true},
+		{"alg differs from the key alg", sign(t, jwt.SigningMethodPS256, "rsa", keys.rsa, claims), true},
+		{"EC token naming an RSA key", sign(t, jwt.SigningMethodES256, "rsa", keys.ec, claims), true},
+		{"EC token naming an RSA key without alg", sign(t, jwt.SigningMethodES256, "rsa-any", keys.ec, claims), true},
+		{"EdDSA token naming an EC key", sign(t, jwt.SigningMethodEdDSA, "ec", keys.ed, claims), true},
+		{"signed by another key", sign(t, jwt.SigningMethodEdDSA, "ed", ed25519.NewKeyFromSeed(make([]byte, 32)), claims), true},
+		{"HS256 without a secret", sign(t, jwt.SigningMethodHS256, "rsa", []byte("n"), claims), true},
+		{"alg none", sign(t, jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType, claims), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keys.verifier.Parse(context.Background(), tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("token accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got.UID != 1 || got.SID == nil || *got.SID != 2 {
+				t.Fatalf("claims = %+v", got)
+			}
+		})
+	}
+}
+
+func TestJwtVerifierClaims(t *testing.T) {
+	verifier := NewJwtVerifier(JwtVerifierConfig{
+		SecretKey:         "secret",
+		Issuer:            "https://auth.example.com",
+		Audience:          "warehouse",
+		Leeway:            30 * time.Second,
+		RequireExpiration: true,
+	})
+	now := time.Now()
+
+	valid := func(overrides jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"uid":  1,
+			"sid":  2,
+			"role": "manager",
+			"iss":  "https://auth.example.com",
+			"aud":  "warehouse",
+			"exp":  now.Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr bool
+	}{
+		{"valid", valid(nil), false},
+		{"audience list", valid(jwt.MapClaims{"aud": []string{"other", "warehouse"}}), false},
+		{"other issuer", valid(jwt.MapClaims{"iss": "https://evil.example.com"}), true},
+		{"no issuer", valid(jwt.MapClaims{"iss": nil}), true},
+		{"other audience", valid(jwt.MapClaims{"aud": "billing"}), true},
+		{"no audience", valid(jwt.MapClaims{"aud": nil}), true},
+		{"no expiration", valid(jwt.MapClaims{"exp": nil}), true},
+		{"expired within leeway", valid(jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()}), false},
+		{"expired beyond leeway", valid(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}), true},
+		{"not before within leeway", valid(jwt.MapClaims{"nbf": now.Add(10 * time.Second).Unix()}), false},
+		{"not before beyond leeway", valid(jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Parse(context.Background(), sign(t, jwt.SigningMethodHS256, "", []byte("secret"), tt.claims))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("token accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got.UID != 1 || got.SID == nil || *got.SID != 2 || got.Role != "manager" {
+				t.Fatalf("claims = %+v", got)
+			}
+		})
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		if _, err := verifier.Parse(context.Background(), sign(t, jwt.SigningMethodHS256, "", []byte("other"), valid(nil))); err == nil {
+			t.Fatal("token accepted")
+		}
+	})
+}
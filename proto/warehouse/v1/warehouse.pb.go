@@ -0,0 +1,1258 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: warehouse/v1/warehouse.proto
+
+package warehousev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ReservationStatus int32
+
+const (
+	ReservationStatus_RESERVATION_STATUS_UNSPECIFIED ReservationStatus = 0
+	ReservationStatus_RESERVATION_STATUS_ACTIVE      ReservationStatus = 1
+	ReservationStatus_RESERVATION_STATUS_COMPLETED   ReservationStatus = 2
+	ReservationStatus_RESERVATION_STATUS_CANCELLED   ReservationStatus = 3
+)
+
+// Enum value maps for ReservationStatus.
+var (
+	ReservationStatus_name = map[int32]string{
+		0: "RESERVATION_STATUS_UNSPECIFIED",
+		1: "RESERVATION_STATUS_ACTIVE",
+		2: "RESERVATION_STATUS_COMPLETED",
+		3: "RESERVATION_STATUS_CANCELLED",
+	}
+	ReservationStatus_value = map[string]int32{
+		"RESERVATION_STATUS_UNSPECIFIED": 0,
+		"RESERVATION_STATUS_ACTIVE":      1,
+		"RESERVATION_STATUS_COMPLETED":   2,
+		"RESERVATION_STATUS_CANCELLED":   3,
+	}
+)
+
+func (x ReservationStatus) Enum() *ReservationStatus {
+	p := new(ReservationStatus)
+	*p = x
+	return p
+}
+
+func (x ReservationStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReservationStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_warehouse_v1_warehouse_proto_enumTypes[0].Descriptor()
+}
+
+func (ReservationStatus) Type() protoreflect.EnumType {
+	return &file_warehouse_v1_warehouse_proto_enumTypes[0]
+}
+
+func (x ReservationStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReservationStatus.Descriptor instead.
+func (ReservationStatus) EnumDescriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{0}
+}
+
+type TransferStatus int32
+
+const (
+	TransferStatus_TRANSFER_STATUS_UNSPECIFIED TransferStatus = 0
+	TransferStatus_TRANSFER_STATUS_NOT_STARTED TransferStatus = 1
+	TransferStatus_TRANSFER_STATUS_IN_PROGRESS TransferStatus = 2
+	TransferStatus_TRANSFER_STATUS_REVERTED    TransferStatus = 3
+	TransferStatus_TRANSFER_STATUS_COMPLETED   TransferStatus = 4
+	TransferStatus_TRANSFER_STATUS_FAILED      TransferStatus = 5
+)
+
+// Enum value maps for TransferStatus.
+var (
+	TransferStatus_name = map[int32]string{
+		0: "TRANSFER_STATUS_UNSPECIFIED",
+		1: "TRANSFER_STATUS_NOT_STARTED",
+		2: "TRANSFER_STATUS_IN_PROGRESS",
+		3: "TRANSFER_STATUS_REVERTED",
+		4: "TRANSFER_STATUS_COMPLETED",
+		5: "TRANSFER_STATUS_FAILED",
+	}
+	TransferStatus_value = map[string]int32{
+		"TRANSFER_STATUS_UNSPECIFIED": 0,
+		"TRANSFER_STATUS_NOT_STARTED": 1,
+		"TRANSFER_STATUS_IN_PROGRESS": 2,
+		"TRANSFER_STATUS_REVERTED":    3,
+		"TRANSFER_STATUS_COMPLETED":   4,
+		"TRANSFER_STATUS_FAILED":      5,
+	}
+)
+
+func (x TransferStatus) Enum() *TransferStatus {
+	p := new(TransferStatus)
+	*p = x
+	return p
+}
+
+func (x TransferStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransferStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_warehouse_v1_warehouse_proto_enumTypes[1].Descriptor()
+}
+
+func (TransferStatus) Type() protoreflect.EnumType {
+	return &file_warehouse_v1_warehouse_proto_enumTypes[1]
+}
+
+func (x TransferStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransferStatus.Descriptor instead.
+func (TransferStatus) EnumDescriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{1}
+}
+
+type Stock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	WarehouseId   int64                  `protobuf:"varint,3,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	Quantity      int64                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stock) Reset() {
+	*x = Stock{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stock) ProtoMessage() {}
+
+func (x *Stock) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stock.ProtoReflect.Descriptor instead.
+func (*Stock) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Stock) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Stock) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Stock) GetWarehouseId() int64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+func (x *Stock) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Stock) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Stock) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type StockTransfer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	FromWarehouse int64                  `protobuf:"varint,3,opt,name=from_warehouse,json=fromWarehouse,proto3" json:"from_warehouse,omitempty"`
+	ToWarehouse   int64                  `protobuf:"varint,4,opt,name=to_warehouse,json=toWarehouse,proto3" json:"to_warehouse,omitempty"`
+	Quantity      int64                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Status        TransferStatus         `protobuf:"varint,6,opt,name=status,proto3,enum=warehouse.v1.TransferStatus" json:"status,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockTransfer) Reset() {
+	*x = StockTransfer{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockTransfer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockTransfer) ProtoMessage() {}
+
+func (x *StockTransfer) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockTransfer.ProtoReflect.Descriptor instead.
+func (*StockTransfer) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StockTransfer) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *StockTransfer) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *StockTransfer) GetFromWarehouse() int64 {
+	if x != nil {
+		return x.FromWarehouse
+	}
+	return 0
+}
+
+func (x *StockTransfer) GetToWarehouse() int64 {
+	if x != nil {
+		return x.ToWarehouse
+	}
+	return 0
+}
+
+func (x *StockTransfer) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *StockTransfer) GetStatus() TransferStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TransferStatus_TRANSFER_STATUS_UNSPECIFIED
+}
+
+func (x *StockTransfer) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *StockTransfer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *StockTransfer) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type InitStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShopId        int64                  `protobuf:"varint,1,opt,name=shop_id,json=shopId,proto3" json:"shop_id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitStockRequest) Reset() {
+	*x = InitStockRequest{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitStockRequest) ProtoMessage() {}
+
+func (x *InitStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitStockRequest.ProtoReflect.Descriptor instead.
+func (*InitStockRequest) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InitStockRequest) GetShopId() int64 {
+	if x != nil {
+		return x.ShopId
+	}
+	return 0
+}
+
+func (x *InitStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+type InitStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stocks        []*Stock               `protobuf:"bytes,1,rep,name=stocks,proto3" json:"stocks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitStockResponse) Reset() {
+	*x = InitStockResponse{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitStockResponse) ProtoMessage() {}
+
+func (x *InitStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitStockResponse.ProtoReflect.Descriptor instead.
+func (*InitStockResponse) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InitStockResponse) GetStocks() []*Stock {
+	if x != nil {
+		return x.Stocks
+	}
+	return nil
+}
+
+type GetAvailabilityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductIds    []int64                `protobuf:"varint,1,rep,packed,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAvailabilityRequest) Reset() {
+	*x = GetAvailabilityRequest{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailabilityRequest) ProtoMessage() {}
+
+func (x *GetAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*GetAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetAvailabilityRequest) GetProductIds() []int64 {
+	if x != nil {
+		return x.ProductIds
+	}
+	return nil
+}
+
+type ProductAvailability struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ProductId      int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	AvailableStock int64                  `protobuf:"varint,2,opt,name=available_stock,json=availableStock,proto3" json:"available_stock,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ProductAvailability) Reset() {
+	*x = ProductAvailability{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductAvailability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductAvailability) ProtoMessage() {}
+
+func (x *ProductAvailability) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductAvailability.ProtoReflect.Descriptor instead.
+func (*ProductAvailability) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProductAvailability) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductAvailability) GetAvailableStock() int64 {
+	if x != nil {
+		return x.AvailableStock
+	}
+	return 0
+}
+
+type GetAvailabilityResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Items are returned in request order; unknown products report zero.
+	Items         []*ProductAvailability `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAvailabilityResponse) Reset() {
+	*x = GetAvailabilityResponse{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAvailabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailabilityResponse) ProtoMessage() {}
+
+func (x *GetAvailabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailabilityResponse.ProtoReflect.Descriptor instead.
+func (*GetAvailabilityResponse) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetAvailabilityResponse) GetItems() []*ProductAvailability {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ReserveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	OrderId       int64                  `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveRequest) Reset() {
+	*x = ReserveRequest{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveRequest) ProtoMessage() {}
+
+func (x *ReserveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveRequest.ProtoReflect.Descriptor instead.
+func (*ReserveRequest) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReserveRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ReserveRequest) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ReserveRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type ReserveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveResponse) Reset() {
+	*x = ReserveResponse{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveResponse) ProtoMessage() {}
+
+func (x *ReserveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveResponse.ProtoReflect.Descriptor instead.
+func (*ReserveResponse) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{8}
+}
+
+type UpdateReservationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        ReservationStatus      `protobuf:"varint,2,opt,name=status,proto3,enum=warehouse.v1.ReservationStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateReservationStatusRequest) Reset() {
+	*x = UpdateReservationStatusRequest{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateReservationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReservationStatusRequest) ProtoMessage() {}
+
+func (x *UpdateReservationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReservationStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateReservationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateReservationStatusRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *UpdateReservationStatusRequest) GetStatus() ReservationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ReservationStatus_RESERVATION_STATUS_UNSPECIFIED
+}
+
+type UpdateReservationStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateReservationStatusResponse) Reset() {
+	*x = UpdateReservationStatusResponse{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateReservationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReservationStatusResponse) ProtoMessage() {}
+
+func (x *UpdateReservationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReservationStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateReservationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{10}
+}
+
+type CreateTransferRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShopId        int64                  `protobuf:"varint,1,opt,name=shop_id,json=shopId,proto3" json:"shop_id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	FromWarehouse int64                  `protobuf:"varint,3,opt,name=from_warehouse,json=fromWarehouse,proto3" json:"from_warehouse,omitempty"`
+	ToWarehouse   int64                  `protobuf:"varint,4,opt,name=to_warehouse,json=toWarehouse,proto3" json:"to_warehouse,omitempty"`
+	Quantity      int64                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransferRequest) Reset() {
+	*x = CreateTransferRequest{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransferRequest) ProtoMessage() {}
+
+func (x *CreateTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransferRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransferRequest) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateTransferRequest) GetShopId() int64 {
+	if x != nil {
+		return x.ShopId
+	}
+	return 0
+}
+
+func (x *CreateTransferRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *CreateTransferRequest) GetFromWarehouse() int64 {
+	if x != nil {
+		return x.FromWarehouse
+	}
+	return 0
+}
+
+func (x *CreateTransferRequest) GetToWarehouse() int64 {
+	if x != nil {
+		return x.ToWarehouse
+	}
+	return 0
+}
+
+func (x *CreateTransferRequest) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *CreateTransferRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type CreateTransferResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transfer      *StockTransfer         `protobuf:"bytes,1,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransferResponse) Reset() {
+	*x = CreateTransferResponse{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransferResponse) ProtoMessage() {}
+
+func (x *CreateTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransferResponse.ProtoReflect.Descriptor instead.
+func (*CreateTransferResponse) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateTransferResponse) GetTransfer() *StockTransfer {
+	if x != nil {
+		return x.Transfer
+	}
+	return nil
+}
+
+type GetTransferRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// When set, the transfer must belong to this shop.
+	ShopId        *int64 `protobuf:"varint,2,opt,name=shop_id,json=shopId,proto3,oneof" json:"shop_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransferRequest) Reset() {
+	*x = GetTransferRequest{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransferRequest) ProtoMessage() {}
+
+func (x *GetTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransferRequest.ProtoReflect.Descriptor instead.
+func (*GetTransferRequest) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetTransferRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GetTransferRequest) GetShopId() int64 {
+	if x != nil && x.ShopId != nil {
+		return *x.ShopId
+	}
+	return 0
+}
+
+type GetTransferResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transfer      *StockTransfer         `protobuf:"bytes,1,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransferResponse) Reset() {
+	*x = GetTransferResponse{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransferResponse) ProtoMessage() {}
+
+func (x *GetTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransferResponse.ProtoReflect.Descriptor instead.
+func (*GetTransferResponse) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTransferResponse) GetTransfer() *StockTransfer {
+	if x != nil {
+		return x.Transfer
+	}
+	return nil
+}
+
+type UpdateTransferStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        TransferStatus         `protobuf:"varint,2,opt,name=status,proto3,enum=warehouse.v1.TransferStatus" json:"status,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTransferStatusRequest) Reset() {
+	*x = UpdateTransferStatusRequest{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTransferStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTransferStatusRequest) ProtoMessage() {}
+
+func (x *UpdateTransferStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTransferStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTransferStatusRequest) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateTransferStatusRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTransferStatusRequest) GetStatus() TransferStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TransferStatus_TRANSFER_STATUS_UNSPECIFIED
+}
+
+func (x *UpdateTransferStatusRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type UpdateTransferStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTransferStatusResponse) Reset() {
+	*x = UpdateTransferStatusResponse{}
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTransferStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTransferStatusResponse) ProtoMessage() {}
+
+func (x *UpdateTransferStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_warehouse_v1_warehouse_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTransferStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTransferStatusResponse) Descriptor() ([]byte, []int) {
+	return file_warehouse_v1_warehouse_proto_rawDescGZIP(), []int{16}
+}
+
+var File_warehouse_v1_warehouse_proto protoreflect.FileDescriptor
+
+const file_warehouse_v1_warehouse_proto_rawDesc = "" +
+	"\n" +
+	"\x1cwarehouse/v1/warehouse.proto\x12\fwarehouse.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xeb\x01\n" +
+	"\x05Stock\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12!\n" +
+	"\fwarehouse_id\x18\x03 \x01(\x03R\vwarehouseId\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x03R\bquantity\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xf2\x02\n" +
+	"\rStockTransfer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12%\n" +
+	"\x0efrom_warehouse\x18\x03 \x01(\x03R\rfromWarehouse\x12!\n" +
+	"\fto_warehouse\x18\x04 \x01(\x03R\vtoWarehouse\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x03R\bquantity\x124\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x1c.warehouse.v1.TransferStatusR\x06status\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"J\n" +
+	"\x10InitStockRequest\x12\x17\n" +
+	"\ashop_id\x18\x01 \x01(\x03R\x06shopId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\"@\n" +
+	"\x11InitStockResponse\x12+\n" +
+	"\x06stocks\x18\x01 \x03(\v2\x13.warehouse.v1.StockR\x06stocks\"9\n" +
+	"\x16GetAvailabilityRequest\x12\x1f\n" +
+	"\vproduct_ids\x18\x01 \x03(\x03R\n" +
+	"productIds\"]\n" +
+	"\x13ProductAvailability\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12'\n" +
+	"\x0favailable_stock\x18\x02 \x01(\x03R\x0eavailableStock\"R\n" +
+	"\x17GetAvailabilityResponse\x127\n" +
+	"\x05items\x18\x01 \x03(\v2!.warehouse.v1.ProductAvailabilityR\x05items\"f\n" +
+	"\x0eReserveRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x03R\bquantity\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\x03R\aorderId\"\x11\n" +
+	"\x0fReserveResponse\"t\n" +
+	"\x1eUpdateReservationStatusRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x127\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1f.warehouse.v1.ReservationStatusR\x06status\"!\n" +
+	"\x1fUpdateReservationStatusResponse\"\xd7\x01\n" +
+	"\x15CreateTransferRequest\x12\x17\n" +
+	"\ashop_id\x18\x01 \x01(\x03R\x06shopId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12%\n" +
+	"\x0efrom_warehouse\x18\x03 \x01(\x03R\rfromWarehouse\x12!\n" +
+	"\fto_warehouse\x18\x04 \x01(\x03R\vtoWarehouse\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x03R\bquantity\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\"Q\n" +
+	"\x16CreateTransferResponse\x127\n" +
+	"\btransfer\x18\x01 \x01(\v2\x1b.warehouse.v1.StockTransferR\btransfer\"N\n" +
+	"\x12GetTransferRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1c\n" +
+	"\ashop_id\x18\x02 \x01(\x03H\x00R\x06shopId\x88\x01\x01B\n" +
+	"\n" +
+	"\b_shop_id\"N\n" +
+	"\x13GetTransferResponse\x127\n" +
+	"\btransfer\x18\x01 \x01(\v2\x1b.warehouse.v1.StockTransferR\btransfer\"\x85\x01\n" +
+	"\x1bUpdateTransferStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x124\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1c.warehouse.v1.TransferStatusR\x06status\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\"\x1e\n" +
+	"\x1cUpdateTransferStatusResponse*\x9a\x01\n" +
+	"\x11ReservationStatus\x12\"\n" +
+	"\x1eRESERVATION_STATUS_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19RESERVATION_STATUS_ACTIVE\x10\x01\x12 \n" +
+	"\x1cRESERVATION_STATUS_COMPLETED\x10\x02\x12 \n" +
+	"\x1cRESERVATION_STATUS_CANCELLED\x10\x03*\xcc\x01\n" +
+	"\x0eTransferStatus\x12\x1f\n" +
+	"\x1bTRANSFER_STATUS_UNSPECIFIED\x10\x00\x12\x1f\n" +
+	"\x1bTRANSFER_STATUS_NOT_STARTED\x10\x01\x12\x1f\n" +
+	"\x1bTRANSFER_STATUS_IN_PROGRESS\x10\x02\x12\x1c\n" +
+	"\x18TRANSFER_STATUS_REVERTED\x10\x03\x12\x1d\n" +
+	"\x19TRANSFER_STATUS_COMPLETED\x10\x04\x12\x1a\n" +
+	"\x16TRANSFER_STATUS_FAILED\x10\x052\xa0\x05\n" +
+	"\x10WarehouseService\x12L\n" +
+	"\tInitStock\x12\x1e.warehouse.v1.InitStockRequest\x1a\x1f.warehouse.v1.InitStockResponse\x12^\n" +
+	"\x0fGetAvailability\x12$.warehouse.v1.GetAvailabilityRequest\x1a%.warehouse.v1.GetAvailabilityResponse\x12F\n" +
+	"\aReserve\x12\x1c.warehouse.v1.ReserveRequest\x1a\x1d.warehouse.v1.ReserveResponse\x12v\n" +
+	"\x17UpdateReservationStatus\x12,.warehouse.v1.UpdateReservationStatusRequest\x1a-.warehouse.v1.UpdateReservationStatusResponse\x12[\n" +
+	"\x0eCreateTransfer\x12#.warehouse.v1.CreateTransferRequest\x1a$.warehouse.v1.CreateTransferResponse\x12R\n" +
+	"\vGetTransfer\x12 .warehouse.v1.GetTransferRequest\x1a!.warehouse.v1.GetTransferResponse\x12m\n" +
+	"\x14UpdateTransferStatus\x12).warehouse.v1.UpdateTransferStatusRequest\x1a*.warehouse.v1.UpdateTransferStatusResponseB2Z0warehouse-service/proto/warehouse/v1;warehousev1b\x06proto3"
+
+var (
+	file_warehouse_v1_warehouse_proto_rawDescOnce sync.Once
+	file_warehouse_v1_warehouse_proto_rawDescData []byte
+)
+
+func file_warehouse_v1_warehouse_proto_rawDescGZIP() []byte {
+	file_warehouse_v1_warehouse_proto_rawDescOnce.Do(func() {
+		file_warehouse_v1_warehouse_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_warehouse_v1_warehouse_proto_rawDesc), len(file_warehouse_v1_warehouse_proto_rawDesc)))
+	})
+	return file_warehouse_v1_warehouse_proto_rawDescData
+}
+
+var file_warehouse_v1_warehouse_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_warehouse_v1_warehouse_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_warehouse_v1_warehouse_proto_goTypes = []any{
+	(ReservationStatus)(0),                  // 0: warehouse.v1.ReservationStatus
+	(TransferStatus)(0),                     // 1: warehouse.v1.TransferStatus
+	(*Stock)(nil),                           // 2: warehouse.v1.Stock
+	(*StockTransfer)(nil),                   // 3: warehouse.v1.StockTransfer
+	(*InitStockRequest)(nil),                // 4: warehouse.v1.InitStockRequest
+	(*InitStockResponse)(nil),               // 5: warehouse.v1.InitStockResponse
+	(*GetAvailabilityRequest)(nil),          // 6: warehouse.v1.GetAvailabilityRequest
+	(*ProductAvailability)(nil),             // 7: warehouse.v1.ProductAvailability
+	(*GetAvailabilityResponse)(nil),         // 8: warehouse.v1.GetAvailabilityResponse
+	(*ReserveRequest)(nil),                  // 9: warehouse.v1.ReserveRequest
+	(*ReserveResponse)(nil),                 // 10: warehouse.v1.ReserveResponse
+	(*UpdateReservationStatusRequest)(nil),  // 11: warehouse.v1.UpdateReservationStatusRequest
+	(*UpdateReservationStatusResponse)(nil), // 12: warehouse.v1.UpdateReservationStatusResponse
+	(*CreateTransferRequest)(nil),           // 13: warehouse.v1.CreateTransferRequest
+	(*CreateTransferResponse)(nil),          // 14: warehouse.v1.CreateTransferResponse
+	(*GetTransferRequest)(nil),              // 15: warehouse.v1.GetTransferRequest
+	(*GetTransferResponse)(nil),             // 16: warehouse.v1.GetTransferResponse
+	(*UpdateTransferStatusRequest)(nil),     // 17: warehouse.v1.UpdateTransferStatusRequest
+	(*UpdateTransferStatusResponse)(nil),    // 18: warehouse.v1.UpdateTransferStatusResponse
+	(*timestamppb.Timestamp)(nil),           // 19: google.protobuf.Timestamp
+}
+var file_warehouse_v1_warehouse_proto_depIdxs = []int32{
+	19, // 0: warehouse.v1.Stock.created_at:type_name -> google.protobuf.Timestamp
+	19, // 1: warehouse.v1.Stock.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 2: warehouse.v1.StockTransfer.status:type_name -> warehouse.v1.TransferStatus
+	19, // 3: warehouse.v1.StockTransfer.created_at:type_name -> google.protobuf.Timestamp
+	19, // 4: warehouse.v1.StockTransfer.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 5: warehouse.v1.InitStockResponse.stocks:type_name -> warehouse.v1.Stock
+	7,  // 6: warehouse.v1.GetAvailabilityResponse.items:type_name -> warehouse.v1.ProductAvailability
+	0,  // 7: warehouse.v1.UpdateReservationStatusRequest.status:type_name -> warehouse.v1.ReservationStatus
+	3,  // 8: warehouse.v1.CreateTransferResponse.transfer:type_name -> warehouse.v1.StockTransfer
+	3,  // 9: warehouse.v1.GetTransferResponse.transfer:type_name -> warehouse.v1.StockTransfer
+	1,  // 10: warehouse.v1.UpdateTransferStatusRequest.status:type_name -> warehouse.v1.TransferStatus
+	4,  // 11: warehouse.v1.WarehouseService.InitStock:input_type -> warehouse.v1.InitStockRequest
+	6,  // 12: warehouse.v1.WarehouseService.GetAvailability:input_type -> warehouse.v1.GetAvailabilityRequest
+	9,  // 13: warehouse.v1.WarehouseService.Reserve:input_type -> warehouse.v1.ReserveRequest
+	11, // 14: warehouse.v1.WarehouseService.UpdateReservationStatus:input_type -> warehouse.v1.UpdateReservationStatusRequest
+	13, // 15: warehouse.v1.WarehouseService.CreateTransfer:input_type -> warehouse.v1.CreateTransferRequest
+	15, // 16: warehouse.v1.WarehouseService.GetTransfer:input_type -> warehouse.v1.GetTransferRequest
+	17, // 17: warehouse.v1.WarehouseService.UpdateTransferStatus:input_type -> warehouse.v1.UpdateTransferStatusRequest
+	5,  // 18: warehouse.v1.WarehouseService.InitStock:output_type -> warehouse.v1.InitStockResponse
+	8,  // 19: warehouse.v1.WarehouseService.GetAvailability:output_type -> warehouse.v1.GetAvailabilityResponse
+	10, // 20: warehouse.v1.WarehouseService.Reserve:output_type -> warehouse.v1.ReserveResponse
+	12, // 21: warehouse.v1.WarehouseService.UpdateReservationStatus:output_type -> warehouse.v1.UpdateReservationStatusResponse
+	14, // 22: warehouse.v1.WarehouseService.CreateTransfer:output_type -> warehouse.v1.CreateTransferResponse
+	16, // 23: warehouse.v1.WarehouseService.GetTransfer:output_type -> warehouse.v1.GetTransferResponse
+	18, // 24: warehouse.v1.WarehouseService.UpdateTransferStatus:output_type -> warehouse.v1.UpdateTransferStatusResponse
+	18, // [18:25] is the sub-list for method output_type
+	11, // [11:18] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_warehouse_v1_warehouse_proto_init() }
+func file_warehouse_v1_warehouse_proto_init() {
+	if File_warehouse_v1_warehouse_proto != nil {
+		return
+	}
+	file_warehouse_v1_warehouse_proto_msgTypes[13].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_warehouse_v1_warehouse_proto_rawDesc), len(file_warehouse_v1_warehouse_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_warehouse_v1_warehouse_proto_goTypes,
+		DependencyIndexes: file_warehouse_v1_warehouse_proto_depIdxs,
+		EnumInfos:         file_warehouse_v1_warehouse_proto_enumTypes,
+		MessageInfos:      file_warehouse_v1_warehouse_proto_msgTypes,
+	}.Build()
+	File_warehouse_v1_warehouse_proto = out.File
+	file_warehouse_v1_warehouse_proto_goTypes = nil
+	file_warehouse_v1_warehouse_proto_depIdxs = nil
+}